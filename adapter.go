@@ -0,0 +1,106 @@
+package pbimmutable
+
+import (
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// CheckRecord runs the same checks as the hook returned by MakeImmutable(args...)
+// outside of the PocketBase record hooks, e.g. in a custom route handler right
+// before the handler saves the record.
+//
+// c is the request context of the handler, so that request aware options
+// (e.g. WithQueryParamOverride or WithRequireMFA) can be used. It can be nil for
+// non-HTTP usage.
+//
+// CheckRecord doesn't save the record and ignores the callback argument, if any.
+// Additional writes requested by the checks (e.g. the MakeJustifiedImmutable audit records)
+// are not part of the caller save transaction, so prefer the hooks when atomicity matters.
+func CheckRecord(app core.App, c echo.Context, record *models.Record, args ...interface{}) error {
+	return checkRecord(parseArgs("CheckRecord", args), app, nil, c, record)
+}
+
+// CheckImmutable reports whether the update described by e would violate the immutability
+// of the provided fields (or of all user-defined fields when none are given), e.g. to
+// pre-flight a batch of updates in a migration tool before saving anything.
+//
+// It only runs the comparison: e.Next() is never called, nothing is written and no
+// callback runs. It returns the same violation error as the MakeImmutable hook, or nil.
+func CheckImmutable(e *core.RecordEvent, fields ...string) error {
+	args := make([]interface{}, len(fields))
+	for i, field := range fields {
+		args[i] = field
+	}
+
+	cfg := parseArgs("CheckImmutable", args)
+
+	_, err := cfg.check(eventContext(e), e, nil)
+	return err
+}
+
+// ValidateImmutable runs the checks of MakeImmutableWith(config) for the event and returns
+// the violation error or nil, leaving e.Next() to the caller, e.g. to run other logic
+// between the checks and the save:
+//
+//	app.OnRecordUpdate("orders").Add(func(e *core.RecordEvent) error {
+//		if err := pbimmutable.ValidateImmutable(e, config); err != nil {
+//			return err
+//		}
+//		// ... other logic ...
+//		return e.Next()
+//	})
+//
+// Like the hook, it applies the record changes and the additional writes requested by
+// the checks (e.g. the MakeJustifiedImmutable audit records); the writes are made in
+// their own transaction. config.Callback is ignored.
+func ValidateImmutable(e *core.RecordEvent, config ImmutableConfig) error {
+	cfg := config.build("ValidateImmutable")
+	return cfg.validate(e, nil)
+}
+
+// CheckRecordInTx is like CheckRecord, but for records saved as part of a larger
+// transaction, e.g. a batch of updates executed in app.Dao().RunInTransaction.
+//
+// The original record and the records looked up by the checks (e.g. the parent record of
+// MakeImmutableUntilParent or the approval of MakeApprovedImmutable) are read with txDao,
+// so they reflect the changes made by earlier operations of the same transaction, and the
+// additional writes requested by the checks are made with txDao as well. Return the error to the transaction to abort the whole batch.
+func CheckRecordInTx(app core.App, txDao *daos.Dao, c echo.Context, record *models.Record, args ...interface{}) error {
+	return checkRecord(parseArgs("CheckRecordInTx", args), app, txDao, c, record)
+}
+
+// checkRecord runs the checks of cfg for a record outside of the hooks chain.
+//
+// txDao is optional: when set, it is used to fetch the original record and
+// to execute the additional writes of the checks.
+func checkRecord(cfg hookConfig, app core.App, txDao *daos.Dao, c echo.Context, record *models.Record) error {
+	e := &core.RecordEvent{
+		App:         app,
+		Record:      record,
+		HttpContext: c,
+	}
+
+	return cfg.validate(e, txDao)
+}
+
+// validate runs the checks of cfg for the event and executes the additional writes
+// requested by them, without calling e.Next() or the callback.
+//
+// txDao is optional: when set, it is used to fetch the original record and
+// to execute the additional writes of the checks.
+func (cfg *hookConfig) validate(e *core.RecordEvent, txDao *daos.Dao) error {
+	checked, err := cfg.check(eventContext(e), e, txDao)
+	if err != nil || checked.inTx == nil {
+		return err
+	}
+
+	if txDao != nil {
+		return checked.inTx(txDao)
+	}
+
+	return e.App.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		return checked.inTx(txDao)
+	})
+}
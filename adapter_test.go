@@ -0,0 +1,297 @@
+package pbimmutable
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestCheckRecord(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "adapter_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	newContext := func(query map[string]any) echo.Context {
+		req := httptest.NewRequest(http.MethodPost, "/api/custom/items/"+initialRecord.Id, nil)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		c.Set(apis.ContextRequestInfoKey, &models.RequestInfo{Query: query})
+		return c
+	}
+
+	t.Run("without request context", func(t *testing.T) {
+		record := initialRecord.CleanCopy()
+		record.Set("name", "changed")
+
+		err := CheckRecord(app, nil, record, "name")
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+
+	t.Run("unchanged frozen field passes", func(t *testing.T) {
+		record := initialRecord.CleanCopy()
+		record.Set("status", "changed")
+
+		if err := CheckRecord(app, nil, record, "name"); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		query       map[string]any
+		expectError bool
+	}{
+		{"missing query param", map[string]any{}, true},
+		{"different query param value", map[string]any{"mode": "regular"}, true},
+		{"matching query param", map[string]any{"mode": "correction"}, false},
+		{"matching repeated query param", map[string]any{"mode": []string{"correction", "regular"}}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			record := initialRecord.CleanCopy()
+			record.Set("value", 200)
+
+			err := CheckRecord(app, newContext(tc.query), record, "name", "value", WithQueryParamOverride("mode", "correction", "value"))
+
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error, got nil")
+			} else if !tc.expectError && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("override doesn't unlock unlisted fields", func(t *testing.T) {
+		record := initialRecord.CleanCopy()
+		record.Set("name", "changed")
+
+		err := CheckRecord(app, newContext(map[string]any{"mode": "correction"}), record, "name", "value", WithQueryParamOverride("mode", "correction", "value"))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+}
+
+func TestCheckImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "dry_run_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		fields              []string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"listed field changed", []string{"name"}, map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"unlisted field changed", []string{"name"}, map[string]interface{}{"status": "changed"}, ""},
+		{"all fields", nil, map[string]interface{}{"value": 200}, "Attempt to modify immutable field 'value'"},
+		{"no changes", nil, map[string]interface{}{}, ""},
+		{"unknown field", []string{"nmae"}, map[string]interface{}{}, `unknown fields "nmae"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckImmutable(newUpdateEvent(app, initialRecord, tc.updates), tc.fields...)
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	stored, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+	if err != nil {
+		t.Fatalf("Failed to fetch record: %v", err)
+	}
+	if stored.GetString("status") != "" || stored.GetInt("value") != 100 {
+		t.Errorf("Expected the dry run not to save anything, got status=%q value=%d", stored.GetString("status"), stored.GetInt("value"))
+	}
+}
+
+func TestValidateImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "validate_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	callbackCalled := false
+	callback := func(e *core.RecordEvent) error {
+		callbackCalled = true
+		return nil
+	}
+
+	tests := []struct {
+		name                string
+		config              ImmutableConfig
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"listed field changed", ImmutableConfig{Fields: []string{"name"}, Callback: callback}, map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"unlisted field changed", ImmutableConfig{Fields: []string{"name"}, Callback: callback}, map[string]interface{}{"status": "changed"}, ""},
+		{"freeze all", ImmutableConfig{FreezeAll: true}, map[string]interface{}{"value": 200}, "Attempt to modify immutable field 'value'"},
+		{"unknown field", ImmutableConfig{Fields: []string{"nmae"}}, map[string]interface{}{}, `unknown fields "nmae"`},
+		{"missing fields", ImmutableConfig{}, map[string]interface{}{}, "pbimmutable.ValidateImmutable: no fields provided"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateImmutable(newUpdateEvent(app, initialRecord, tc.updates), tc.config)
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	if callbackCalled {
+		t.Error("Expected ValidateImmutable not to run the callback")
+	}
+
+	stored, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+	if err != nil {
+		t.Fatalf("Failed to fetch record: %v", err)
+	}
+	if stored.GetString("status") != "" {
+		t.Errorf("Expected ValidateImmutable not to save the record, got status=%q", stored.GetString("status"))
+	}
+}
+
+func TestCheckRecordInTx_Batch(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	var records []*models.Record
+	for _, name := range []string{"batch_1", "batch_2"} {
+		record := models.NewRecord(coll)
+		record.Set("name", name)
+		record.Set("status", "open")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	// runBatch executes the operations in a single transaction, like the PocketBase batch API.
+	runBatch := func(ops []func(txDao *daos.Dao) error) error {
+		return app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+			for _, op := range ops {
+				if err := op(txDao); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	update := func(record *models.Record, updates map[string]interface{}, args ...interface{}) func(txDao *daos.Dao) error {
+		return func(txDao *daos.Dao) error {
+			// load the pending record within the batch, so that it carries the earlier batch changes
+			pending, err := txDao.FindRecordById(coll.Id, record.Id)
+			if err != nil {
+				return err
+			}
+			for k, v := range updates {
+				pending.Set(k, v)
+			}
+
+			if err := CheckRecordInTx(app, txDao, nil, pending, args...); err != nil {
+				return err
+			}
+			return txDao.SaveRecord(pending)
+		}
+	}
+
+	t.Run("one violation aborts the whole batch", func(t *testing.T) {
+		err := runBatch([]func(txDao *daos.Dao) error{
+			update(records[0], map[string]interface{}{"status": "closed"}, "name"),
+			update(records[1], map[string]interface{}{"name": "renamed"}, "name"),
+		})
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Fatalf("Expected immutable field error, got: %v", err)
+		}
+
+		stored, err := app.Dao().FindRecordById(coll.Id, records[0].Id)
+		if err != nil {
+			t.Fatalf("Failed to fetch record: %v", err)
+		}
+		if stored.GetString("status") != "open" {
+			t.Errorf("Expected the first operation to be rolled back, got status %q", stored.GetString("status"))
+		}
+	})
+
+	t.Run("originals reflect earlier batch operations", func(t *testing.T) {
+		// the second operation carries the status written by the first one,
+		// which must not be reported as a change of the frozen status
+		err := runBatch([]func(txDao *daos.Dao) error{
+			update(records[0], map[string]interface{}{"status": "closed"}, "name"),
+			update(records[0], map[string]interface{}{"description": "closed in batch"}, "status"),
+		})
+		if err != nil {
+			t.Fatalf("Expected the batch to pass, got: %v", err)
+		}
+	})
+
+	t.Run("lookups of the checks reflect earlier batch operations", func(t *testing.T) {
+		cfg := parseArgs("CheckRecordInTx", []interface{}{"name"})
+		cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+			related, err := e.App.Dao().FindRecordById(coll.Id, records[1].Id)
+			if err != nil {
+				return nil, err
+			}
+			if related.GetString("status") != "archived" {
+				return nil, errors.New("the lookup missed the earlier batch operation")
+			}
+			return nil, nil
+		}
+
+		err := runBatch([]func(txDao *daos.Dao) error{
+			update(records[1], map[string]interface{}{"status": "archived"}, "name"),
+			func(txDao *daos.Dao) error {
+				pending, err := txDao.FindRecordById(coll.Id, records[0].Id)
+				if err != nil {
+					return err
+				}
+				return checkRecord(cfg, app, txDao, nil, pending)
+			},
+		})
+		if err != nil {
+			t.Fatalf("Expected the batch to pass, got: %v", err)
+		}
+	})
+}
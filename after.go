@@ -0,0 +1,71 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// MakeImmutableAfter returns a hook that freezes the provided fields (or all user-defined
+// fields when none are given) once d has elapsed since the record was created, e.g. to
+// allow corrections during a grace period:
+//
+//	MakeImmutableAfter(15*time.Minute, "amount", "iban")
+//
+// Within the window, updates pass straight through to e.Next().
+//
+// The window is computed from the "created" timestamp of the original record, or from a
+// business date field set with WithAgeSourceField:
+//
+//	MakeImmutableAfter(24*time.Hour, "amount", WithAgeSourceField("effectiveDate"))
+//
+// A record whose value is missing or can't be parsed fails closed: its fields are
+// treated as frozen, because its age can't prove that it is still within the window.
+//
+// It accepts the same arguments as MakeImmutable.
+func MakeImmutableAfter(d time.Duration, args ...interface{}) func(e *core.RecordEvent) error {
+	args = append(args[:len(args):len(args)], measuresAge()) // never write into the caller's slice
+	cfg := parseArgs("MakeImmutableAfter", args)
+	if cfg.setupErr == nil && d < 0 {
+		cfg.setupErr = fmt.Errorf("pbimmutable.MakeImmutableAfter: the duration must not be negative, got %s", d)
+	}
+
+	source := cfg.ageSource()
+	cfg.freezeOn(func(original *models.Record) bool {
+		return windowElapsed(original, source, d, time.Now())
+	}, func(record *models.Record) FreezeInfo {
+		return FreezeInfo{Reason: FreezeReasonTimeWindow, Since: windowEnd(record, source, d)}
+	})
+
+	return newHook(cfg)
+}
+
+// windowElapsed reports whether d has elapsed at now since the date stored in the
+// sourceField of the record, e.g. its "created" timestamp. It returns true for records
+// whose sourceField value is missing or can't be parsed as a date.
+func windowElapsed(record *models.Record, sourceField string, d time.Duration, now time.Time) bool {
+	since := record.GetDateTime(sourceField)
+	if since.IsZero() {
+		return true
+	}
+
+	return !now.Before(since.Time().Add(d))
+}
+
+// windowEnd returns when d elapses since the date stored in the sourceField of the record,
+// or nil when the value is missing or can't be parsed as a date.
+func windowEnd(record *models.Record, sourceField string, d time.Duration) *types.DateTime {
+	since := record.GetDateTime(sourceField)
+	if since.IsZero() {
+		return nil
+	}
+
+	end, err := types.ParseDateTime(since.Time().Add(d))
+	if err != nil {
+		return nil
+	}
+	return &end
+}
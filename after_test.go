@@ -0,0 +1,90 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestMakeImmutableAfter(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "after_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		window              time.Duration
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"within the window", 15 * time.Minute, map[string]interface{}{"value": 200}, ""},
+		{"window elapsed", 0, map[string]interface{}{"value": 200}, "Attempt to modify immutable field 'value'"},
+		{"window elapsed unrelated field", 0, map[string]interface{}{"status": "done"}, ""},
+		{"negative window", -time.Minute, map[string]interface{}{"value": 200}, "MakeImmutableAfter setup error: pbimmutable.MakeImmutableAfter: the duration must not be negative, got -1m0s"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableAfter(tc.window, "value")
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("age source field", func(t *testing.T) {
+		// the record was created within the window, so only the missing age source value freezes it
+		err := MakeImmutableAfter(time.Hour, "value", WithAgeSourceField("description"))(newUpdateEvent(app, initialRecord, map[string]interface{}{"value": 200}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'value'") {
+			t.Errorf("Expected a missing age source value to fail closed, got: %v", err)
+		}
+	})
+}
+
+func TestWindowElapsed(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newRecord := func(created time.Time) *models.Record {
+		record := models.NewRecord(&models.Collection{Name: "test_items", Type: models.CollectionTypeBase})
+		if !created.IsZero() {
+			dt, _ := types.ParseDateTime(created)
+			record.Created = dt
+		}
+		return record
+	}
+
+	tests := []struct {
+		name     string
+		created  time.Time
+		expected bool
+	}{
+		{"created within the window", now.Add(-10 * time.Minute), false},
+		{"created at the window end", now.Add(-15 * time.Minute), true},
+		{"created before the window", now.Add(-time.Hour), true},
+		{"missing created fails closed", time.Time{}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := windowElapsed(newRecord(tc.created), models.SystemFieldCreated, 15*time.Minute, now); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
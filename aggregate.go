@@ -0,0 +1,115 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// RuleAggregate is the kind of the rule created by MakeAggregateImmutable.
+const RuleAggregate RuleKind = "aggregate"
+
+// aggregateTolerance absorbs float rounding differences when summing child values.
+const aggregateTolerance = 1e-9
+
+// AggregateRule describes a total field that must equal the sum of a field of related child records.
+type AggregateRule struct {
+	// TotalField is the number field of the parent record holding the total, e.g. "total".
+	TotalField string
+
+	// RelationField is the (multiple) relation field of the parent record pointing to the children,
+	// e.g. "items".
+	RelationField string
+
+	// ChildField is the number field of the child records that is summed, e.g. "amount".
+	ChildField string
+}
+
+// MakeAggregateImmutable returns a hook that freezes rule.TotalField and verifies on every update
+// that it still equals the sum of rule.ChildField over the records currently referenced by
+// rule.RelationField. The update is rejected if the total changes or diverges from the sum.
+//
+// The children are loaded with a single FindRecordsByIds query per update
+// (one extra "WHERE id IN (...)" read on the child collection).
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeAggregateImmutable(rule AggregateRule, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeAggregateImmutable", args)
+	if cfg.setupErr == nil {
+		switch {
+		case rule.TotalField == "" || rule.RelationField == "" || rule.ChildField == "":
+			cfg.setupErr = errors.New("pbimmutable.MakeAggregateImmutable: TotalField, RelationField and ChildField must be provided")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeAggregateImmutable: only a callback can be passed as additional argument")
+		}
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		pending := e.Record
+		change := newChange(original, pending, rule.TotalField, RuleAggregate)
+
+		if !cfg.cmp.equalValues(pending, rule.TotalField, change.Old, change.New) {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Attempt to modify immutable field '%s'.", rule.TotalField)
+			return ChangeSet{change}, nil
+		}
+
+		sum, err := sumChildren(e.App, pending, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		total := pending.GetFloat(rule.TotalField)
+		if math.Abs(total-sum) > aggregateTolerance {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' (%v) doesn't match the sum of '%s.%s' (%v).", rule.TotalField, total, rule.RelationField, rule.ChildField, sum)
+			change.Details = map[string]any{
+				"total": total,
+				"sum":   sum,
+			}
+		}
+
+		return ChangeSet{change}, nil
+	}
+
+	return newHook(cfg)
+}
+
+// sumChildren loads the child records referenced by the pending record and sums their ChildField.
+func sumChildren(app core.App, pending *models.Record, rule AggregateRule) (float64, error) {
+	field := pending.Schema().GetFieldByName(rule.RelationField)
+	if field == nil || field.Type != schema.FieldTypeRelation {
+		return 0, apis.NewBadRequestError(fmt.Sprintf("MakeAggregateImmutable setup error: '%s' is not a relation field.", rule.RelationField), nil)
+	}
+
+	ids := pending.GetStringSlice(rule.RelationField)
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	options, _ := field.Options.(*schema.RelationOptions)
+	if options == nil {
+		return 0, apis.NewBadRequestError(fmt.Sprintf("MakeAggregateImmutable setup error: missing relation options for '%s'.", rule.RelationField), nil)
+	}
+
+	children, err := app.Dao().FindRecordsByIds(options.CollectionId, ids)
+	if err != nil {
+		return 0, apis.NewBadRequestError(fmt.Sprintf("Failed to fetch the '%s' records of record %s.", rule.RelationField, pending.Id), err)
+	}
+	if len(children) != len(ids) {
+		return 0, apis.NewBadRequestError(fmt.Sprintf("Some '%s' records of record %s are missing.", rule.RelationField, pending.Id), nil)
+	}
+
+	var sum float64
+	for _, child := range children {
+		sum += child.GetFloat(rule.ChildField)
+	}
+
+	return sum, nil
+}
@@ -0,0 +1,101 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeAggregateImmutable(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	lineItems := &models.Collection{
+		Name: "test_line_items",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "amount", Type: schema.FieldTypeNumber},
+		),
+	}
+	if err := app.Dao().SaveCollection(lineItems); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	invoices := &models.Collection{
+		Name: "test_invoices",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "total", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "note", Type: schema.FieldTypeText},
+			&schema.SchemaField{
+				Name:    "items",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{CollectionId: lineItems.Id},
+			},
+		),
+	}
+	if err := app.Dao().SaveCollection(invoices); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	var itemIds []string
+	for _, amount := range []float64{10.5, 20, 30} {
+		item := models.NewRecord(lineItems)
+		item.Set("amount", amount)
+		if err := app.Dao().SaveRecord(item); err != nil {
+			t.Fatalf("Failed to save line item: %v", err)
+		}
+		itemIds = append(itemIds, item.Id)
+	}
+
+	invoice := models.NewRecord(invoices)
+	invoice.Set("total", 60.5)
+	invoice.Set("items", itemIds)
+	if err := app.Dao().SaveRecord(invoice); err != nil {
+		t.Fatalf("Failed to save invoice: %v", err)
+	}
+
+	hookFunc := MakeAggregateImmutable(AggregateRule{TotalField: "total", RelationField: "items", ChildField: "amount"})
+
+	tests := []struct {
+		name                string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{
+			name:    "consistent total",
+			updates: map[string]interface{}{"note": "paid"},
+		},
+		{
+			name:                "changed total",
+			updates:             map[string]interface{}{"total": 70},
+			expectErrorContains: "Attempt to modify immutable field 'total'",
+		},
+		{
+			name:                "removed child diverges",
+			updates:             map[string]interface{}{"items": itemIds[:2]},
+			expectErrorContains: "doesn't match the sum of 'items.amount' (30.5)",
+		},
+		{
+			name:                "unknown child",
+			updates:             map[string]interface{}{"items": append([]string{"missing_item_id"}, itemIds...)},
+			expectErrorContains: "are missing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := hookFunc(newUpdateEvent(app, invoice, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// RuleAppendOnly is the kind of the rule created by MakeAppendOnly.
+const RuleAppendOnly RuleKind = "append_only"
+
+// MakeAppendOnly returns a hook that lets a multiple relation or select field only grow,
+// e.g. MakeAppendOnly("participants") for an append-only membership. New ids (or options)
+// can be added, but every id of the original value must still be present. The values are
+// compared as sets, so reordering them is allowed.
+//
+// For a JSON field holding an array, e.g. an event log, the original array must be a prefix
+// of the pending one: new elements can only be appended, and the existing elements can't be
+// removed, reordered or edited. An unset value counts as an empty array. A value that isn't
+// an array rejects the update.
+//
+// The field must be a multiple relation or select field or a JSON field of the collection
+// schema, otherwise the first update fails with a setup error.
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeAppendOnly(field string, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeAppendOnly", args)
+	if cfg.setupErr == nil {
+		switch {
+		case field == "":
+			cfg.setupErr = errors.New("pbimmutable.MakeAppendOnly: a field name must be provided")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeAppendOnly: only a callback can be passed as additional argument")
+		}
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		pending := e.Record
+		schemaField := pending.Schema().GetFieldByName(field)
+		if schemaField != nil && schemaField.Type == schema.FieldTypeJson {
+			return evaluateAppendOnlyJSON(original, pending, field)
+		}
+		if !isMultiValueField(schemaField) {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeAppendOnly setup error: '%s' is not a multiple relation or select field or a JSON field of collection %s.", field, pending.Collection().Name), nil)
+		}
+
+		change := newChange(original, pending, field, RuleAppendOnly)
+
+		before, _ := toStrings(change.Old)
+		after, _ := toStrings(change.New)
+		kept := make(map[string]bool, len(after))
+		for _, id := range after {
+			kept[id] = true
+		}
+
+		var removed []string
+		for _, id := range before {
+			if !kept[id] {
+				removed = append(removed, id)
+			}
+		}
+		if len(removed) > 0 {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' is append-only, values can't be removed (removed %v).", field, removed)
+			change.Details = map[string]any{"removed": removed}
+		}
+
+		return ChangeSet{change}, nil
+	}
+
+	return newHook(cfg)
+}
+
+// isMultiValueField reports whether the schema field is a relation or select field
+// that allows multiple values.
+func isMultiValueField(field *schema.SchemaField) bool {
+	if field == nil || (field.Type != schema.FieldTypeRelation && field.Type != schema.FieldTypeSelect) {
+		return false
+	}
+
+	options, ok := field.Options.(schema.MultiValuer)
+	return ok && options.IsMultiple()
+}
+
+// evaluateAppendOnlyJSON checks that the JSON array of the original record is a prefix
+// of the JSON array of the pending record.
+func evaluateAppendOnlyJSON(original, pending *models.Record, field string) (ChangeSet, error) {
+	change := newChange(original, pending, field, RuleAppendOnly)
+
+	before, ok := toJSONArray(change.Old)
+	if !ok {
+		return nil, apis.NewBadRequestError(fmt.Sprintf("Field '%s' of record %s doesn't hold a JSON array and can't be append-only.", field, original.Id), nil)
+	}
+	after, ok := toJSONArray(change.New)
+	if !ok {
+		return nil, apis.NewBadRequestError(fmt.Sprintf("Field '%s' must be a JSON array.", field), nil)
+	}
+
+	for i, element := range before {
+		if i >= len(after) {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' is append-only, elements can't be removed.", field)
+			change.Details = map[string]any{"index": i}
+			break
+		}
+		if !reflect.DeepEqual(element, after[i]) {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' is append-only, element %d can't be changed.", field, i)
+			change.Details = map[string]any{"index": i}
+			break
+		}
+	}
+
+	return ChangeSet{change}, nil
+}
+
+// toJSONArray decodes the value of a JSON field into its elements.
+// An unset value is an empty array, any other non-array value is not ok.
+func toJSONArray(value any) ([]any, bool) {
+	decoded, ok := toJSONValue(value)
+	if !ok {
+		return nil, false
+	}
+
+	switch v := decoded.(type) {
+	case nil:
+		return nil, true
+	case []any:
+		return v, true
+	default:
+		return nil, false
+	}
+}
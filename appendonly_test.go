@@ -0,0 +1,147 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestMakeAppendOnly(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	var ids []string
+	for _, name := range []string{"alice", "bob", "carol"} {
+		item := models.NewRecord(coll)
+		item.Set("name", name)
+		if err := app.Dao().SaveRecord(item); err != nil {
+			t.Fatalf("Failed to save related record: %v", err)
+		}
+		ids = append(ids, item.Id)
+	}
+
+	meetings := &models.Collection{
+		Name: "test_meetings",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "participants", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: coll.Id}},
+			&schema.SchemaField{Name: "host", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: coll.Id, MaxSelect: types.Pointer(1)}},
+			&schema.SchemaField{Name: "roles", Type: schema.FieldTypeSelect, Options: &schema.SelectOptions{MaxSelect: 3, Values: []string{"admin", "editor", "viewer"}}},
+			&schema.SchemaField{Name: "kind", Type: schema.FieldTypeSelect, Options: &schema.SelectOptions{MaxSelect: 1, Values: []string{"a", "b"}}},
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(meetings); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	meeting := models.NewRecord(meetings)
+	meeting.Set("participants", ids[:2])
+	meeting.Set("host", ids[0])
+	meeting.Set("roles", []string{"admin", "editor"})
+	if err := app.Dao().SaveRecord(meeting); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		field               string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"add a participant", "participants", map[string]interface{}{"participants": ids}, ""},
+		{"reorder participants", "participants", map[string]interface{}{"participants": []string{ids[1], ids[0]}}, ""},
+		{"unchanged", "participants", map[string]interface{}{"title": "renamed"}, ""},
+		{"remove a participant", "participants", map[string]interface{}{"participants": []string{ids[1], ids[2]}}, "Field 'participants' is append-only, values can't be removed (removed [" + ids[0] + "])."},
+		{"clear participants", "participants", map[string]interface{}{"participants": nil}, "Field 'participants' is append-only"},
+		{"add an option", "roles", map[string]interface{}{"roles": []string{"editor", "admin", "viewer"}}, ""},
+		{"remove an option", "roles", map[string]interface{}{"roles": []string{"admin"}}, "Field 'roles' is append-only, values can't be removed (removed [editor])."},
+		{"single relation", "host", map[string]interface{}{}, "MakeAppendOnly setup error: 'host' is not a multiple relation or select field or a JSON field of collection test_meetings."},
+		{"single select", "kind", map[string]interface{}{}, "'kind' is not a multiple relation or select field or a JSON field"},
+		{"text field", "title", map[string]interface{}{}, "'title' is not a multiple relation or select field or a JSON field"},
+		{"empty field name", "", map[string]interface{}{}, "pbimmutable.MakeAppendOnly: a field name must be provided"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeAppendOnly(tc.field)
+
+			err := hookFunc(newUpdateEvent(app, meeting, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestMakeAppendOnly_JSONArray(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	logs := &models.Collection{
+		Name: "test_logs",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "history", Type: schema.FieldTypeJson},
+		),
+	}
+	if err := app.Dao().SaveCollection(logs); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newLog := func(history string) *models.Record {
+		record := models.NewRecord(logs)
+		if history != "" {
+			record.Set("history", history)
+		}
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	log := newLog(`[{"event":"created","by":"a"},{"event":"approved"}]`)
+	empty := newLog("")
+	object := newLog(`{"event":"created"}`)
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		history             any
+		expectErrorContains string
+	}{
+		{"append an element", log, `[{"by":"a","event":"created"},{"event":"approved"},{"event":"paid"}]`, ""},
+		{"unchanged", log, `[{"event":"created","by":"a"},{"event":"approved"}]`, ""},
+		{"first element of an empty log", empty, `[{"event":"created"}]`, ""},
+		{"remove the last element", log, `[{"event":"created","by":"a"}]`, "Field 'history' is append-only, elements can't be removed."},
+		{"clear the log", log, nil, "Field 'history' is append-only, elements can't be removed."},
+		{"edit an element", log, `[{"event":"created","by":"b"},{"event":"approved"}]`, "Field 'history' is append-only, element 0 can't be changed."},
+		{"reorder elements", log, `[{"event":"approved"},{"event":"created","by":"a"}]`, "element 0 can't be changed."},
+		{"pending is not an array", log, `{"event":"paid"}`, "Field 'history' must be a JSON array."},
+		{"original is not an array", object, `[{"event":"created"}]`, "Field 'history' of record " + object.Id + " doesn't hold a JSON array and can't be append-only."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeAppendOnly("history")
+
+			err := hookFunc(newUpdateEvent(app, tc.original, map[string]interface{}{"history": tc.history}))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
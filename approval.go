@@ -0,0 +1,144 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// RuleFourEyes is the kind of the violations reported by MakeApprovedImmutable.
+const RuleFourEyes RuleKind = "four_eyes"
+
+// DefaultApprovalKey is the request body key read by MakeApprovedImmutable
+// when ApprovalRule.Key is not set.
+const DefaultApprovalKey = "approvalToken"
+
+// ApprovalRule configures the four-eyes approval of changes to frozen fields.
+type ApprovalRule struct {
+	// Key is the request body key carrying the approval token
+	// (defaults to DefaultApprovalKey).
+	Key string
+
+	// ApprovalsCollection is the name of the collection holding the issued approvals.
+	//
+	// It is expected to have the following fields:
+	//  - token      (text, unique) the secret approval token
+	//  - recordId   (text)         id of the record the approval is valid for
+	//  - collection (text)         name of the record collection
+	//  - approver   (text)         id of the auth record or admin that issued the approval
+	//  - expires    (date)         optional expiration date
+	//  - used       (bool)         set once the approval was consumed
+	ApprovalsCollection string
+}
+
+// MakeApprovedImmutable returns a hook that freezes the provided fields (or all user-defined
+// fields when none are given) unless the request carries a valid approval token that was
+// issued by a different actor than the requester (four-eyes principle).
+//
+// An approval is valid when it exists in rule.ApprovalsCollection, matches the updated
+// record and collection, is not expired and not used yet. Self-approvals, guests and
+// non-HTTP events are always rejected. A consumed approval is marked as used in the same
+// transaction as the record update, with a conditional update that fails if the approval
+// was used in the meantime, so every approval allows exactly one update.
+//
+// It accepts the same arguments as MakeImmutable.
+func MakeApprovedImmutable(rule ApprovalRule, args ...interface{}) func(e *core.RecordEvent) error {
+	if rule.Key == "" {
+		rule.Key = DefaultApprovalKey
+	}
+
+	cfg := parseArgs("MakeApprovedImmutable", args)
+	cfg.requestKeys = append(cfg.requestKeys, rule.Key) // the approval token isn't a record field
+	if cfg.setupErr == nil && rule.ApprovalsCollection == "" {
+		cfg.setupErr = errors.New("pbimmutable.MakeApprovedImmutable: an approvals collection must be provided")
+	}
+
+	cfg.override = func(e *core.RecordEvent, violations ChangeSet) (bool, func(txDao *daos.Dao) error) {
+		approval, reason := findApproval(e, rule)
+		if approval == nil {
+			if reason != "" {
+				rejectApproval(violations, reason)
+			}
+			return false, nil
+		}
+
+		return true, func(txDao *daos.Dao) error {
+			// the approval is only consumed if it is still unused in the transaction, so
+			// that concurrent requests with the same token can't both spend it
+			result, err := txDao.DB().Update(
+				approval.Collection().Name,
+				dbx.Params{"used": true, models.SystemFieldUpdated: types.NowDateTime()},
+				dbx.HashExp{models.SystemFieldId: approval.Id, "used": false},
+			).Execute()
+			if err != nil {
+				return fmt.Errorf("failed to consume approval %s: %w", approval.Id, err)
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to consume approval %s: %w", approval.Id, err)
+			}
+			if affected == 0 {
+				rejectApproval(violations, "the approval was already used")
+				return &rejectedInTx{err: cfg.rejectError(e, violations)}
+			}
+			return nil
+		}
+	}
+
+	return newHook(cfg)
+}
+
+// rejectApproval marks the violations as rejected for the reason why the approval isn't valid.
+func rejectApproval(violations ChangeSet, reason string) {
+	for i := range violations {
+		violations[i].RuleKind = RuleFourEyes
+		violations[i].Message = fmt.Sprintf("Field '%s' can only be modified with a valid approval: %s.", violations[i].Field, reason)
+	}
+}
+
+// findApproval returns the valid approval referenced by the event request, or the reason
+// why the request has no valid approval (empty when no approval token was submitted).
+func findApproval(e *core.RecordEvent, rule ApprovalRule) (*models.Record, string) {
+	info := requestInfo(e)
+	if info == nil {
+		return nil, ""
+	}
+
+	token, _ := info.Data[rule.Key].(string)
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ""
+	}
+
+	actor := requestActorId(info)
+	if actor == "" {
+		return nil, "the requester must be authenticated"
+	}
+
+	approval, err := e.App.Dao().FindFirstRecordByData(rule.ApprovalsCollection, "token", token)
+	if err != nil {
+		return nil, "unknown approval token"
+	}
+
+	switch {
+	case approval.GetString("recordId") != e.Record.Id || approval.GetString("collection") != e.Record.Collection().Name:
+		return nil, "the approval was issued for another record"
+	case approval.GetBool("used"):
+		return nil, "the approval was already used"
+	case !approval.GetDateTime("expires").IsZero() && approval.GetDateTime("expires").Time().Before(time.Now()):
+		return nil, "the approval has expired"
+	case approval.GetString("approver") == "":
+		return nil, "the approval has no approver"
+	case approval.GetString("approver") == actor:
+		return nil, "self-approval is not allowed"
+	}
+
+	return approval, ""
+}
@@ -0,0 +1,170 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/USERNAME/pbimmutable/pbimmutabletest"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestMakeApprovedImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	approvalsColl := &models.Collection{
+		Name: "immutable_approvals",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "token", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "recordId", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "collection", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "approver", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "expires", Type: schema.FieldTypeDate},
+			&schema.SchemaField{Name: "used", Type: schema.FieldTypeBool},
+		),
+	}
+	if err := app.Dao().SaveCollection(approvalsColl); err != nil {
+		t.Fatalf("Failed to save approvals collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "approval_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	issue := func(t *testing.T, token, recordId, approver string, expires time.Time) *models.Record {
+		approval := models.NewRecord(approvalsColl)
+		approval.Set("token", token)
+		approval.Set("recordId", recordId)
+		approval.Set("collection", coll.Name)
+		approval.Set("approver", approver)
+		if !expires.IsZero() {
+			expiresAt, _ := types.ParseDateTime(expires)
+			approval.Set("expires", expiresAt)
+		}
+		if err := app.Dao().SaveRecord(approval); err != nil {
+			t.Fatalf("Failed to save approval: %v", err)
+		}
+		return approval
+	}
+
+	issue(t, "valid_token", initialRecord.Id, "approver", time.Time{})
+	issue(t, "self_token", initialRecord.Id, "requester", time.Time{})
+	issue(t, "other_record_token", "other_record", "approver", time.Time{})
+	issue(t, "expired_token", initialRecord.Id, "approver", time.Now().Add(-time.Hour))
+
+	requester := models.NewRecord(coll)
+	requester.Id = "requester"
+
+	rule := ApprovalRule{ApprovalsCollection: approvalsColl.Name}
+	updates := map[string]interface{}{"value": 150}
+
+	tests := []struct {
+		name                string
+		info                *models.RequestInfo
+		expectErrorContains string
+	}{
+		{"no token", &models.RequestInfo{AuthRecord: requester, Data: map[string]any{}}, "Attempt to modify immutable field 'value'"},
+		{"guest", &models.RequestInfo{Data: map[string]any{"approvalToken": "valid_token"}}, "the requester must be authenticated"},
+		{"unknown token", &models.RequestInfo{AuthRecord: requester, Data: map[string]any{"approvalToken": "missing"}}, "unknown approval token"},
+		{"self-approval", &models.RequestInfo{AuthRecord: requester, Data: map[string]any{"approvalToken": "self_token"}}, "self-approval is not allowed"},
+		{"other record", &models.RequestInfo{AuthRecord: requester, Data: map[string]any{"approvalToken": "other_record_token"}}, "issued for another record"},
+		{"expired", &models.RequestInfo{AuthRecord: requester, Data: map[string]any{"approvalToken": "expired_token"}}, "the approval has expired"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeApprovedImmutable(rule, "value")
+
+			err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, tc.info))
+			if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+				t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+			}
+		})
+	}
+
+	t.Run("approved by a distinct actor", func(t *testing.T) {
+		hookFunc := MakeApprovedImmutable(rule, "value")
+		info := &models.RequestInfo{AuthRecord: requester, Data: map[string]any{"approvalToken": "valid_token"}}
+
+		if err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, info)); err != nil {
+			t.Fatalf("Expected approved update to pass, got: %v", err)
+		}
+
+		approval, err := app.Dao().FindFirstRecordByData(approvalsColl.Name, "token", "valid_token")
+		if err != nil {
+			t.Fatalf("Failed to fetch approval: %v", err)
+		}
+		if !approval.GetBool("used") {
+			t.Errorf("Expected the approval to be marked as used")
+		}
+
+		// approvals are single use
+		err = hookFunc(newRequestUpdateEvent(app, initialRecord, updates, info))
+		if err == nil || !strings.Contains(err.Error(), "the approval was already used") {
+			t.Errorf("Expected used approval error, got: %v", err)
+		}
+	})
+
+	t.Run("concurrent requests with the same approval", func(t *testing.T) {
+		issue(t, "race_token", initialRecord.Id, "approver", time.Time{})
+		info := &models.RequestInfo{AuthRecord: requester, Data: map[string]any{"approvalToken": "race_token"}}
+		save := func(e *core.RecordEvent) error {
+			return e.App.Dao().SaveRecord(e.Record)
+		}
+
+		// the first request is held in its save, after it consumed the approval in its
+		// transaction, while the second request finds the approval still unused
+		saving := make(chan struct{})
+		release := make(chan struct{})
+		firstErr := make(chan error, 1)
+		go func() {
+			event := pbimmutabletest.NewEvent(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 210}, info), func(e *core.RecordEvent) error {
+				close(saving)
+				<-release
+				return save(e)
+			})
+			firstErr <- event.Run(MakeApprovedImmutable(rule, "value"))
+		}()
+		<-saving
+
+		secondErr := make(chan error, 1)
+		go func() {
+			event := pbimmutabletest.NewEvent(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 220}, info), save)
+			secondErr <- event.Run(MakeApprovedImmutable(rule, "value"))
+		}()
+		time.Sleep(50 * time.Millisecond) // let the second request reach the consumption of the approval
+		close(release)
+
+		if err := <-firstErr; err != nil {
+			t.Fatalf("Expected the first request to pass, got: %v", err)
+		}
+		if err := <-secondErr; err == nil || !strings.Contains(err.Error(), "the approval was already used") {
+			t.Fatalf("Expected used approval error for the second request, got: %v", err)
+		}
+
+		current, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to reload record: %v", err)
+		}
+		if current.GetInt("value") != 210 {
+			t.Errorf("Expected the value of the first request, got %d", current.GetInt("value"))
+		}
+	})
+
+	t.Run("approvals collection is required", func(t *testing.T) {
+		hookFunc := MakeApprovedImmutable(ApprovalRule{}, "value")
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeApprovedImmutable setup error") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
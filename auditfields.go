@@ -0,0 +1,69 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// MakeAuditFieldsImmutable returns a hook that freezes the "created by" relation field
+// and the "created at" date field of a collection, plus the additionally provided fields,
+// e.g. models.SystemFieldCreated to freeze the built-in creation date as well:
+//
+//	MakeAuditFieldsImmutable("createdBy", "createdAt", models.SystemFieldCreated)
+//
+// The relation is compared as a set of ids and the dates as instants (like any relation
+// and date field; the system created field with DateNormalizer), so that the same time
+// written in another format or time zone isn't a change.
+//
+// createdBy must be a relation field and createdAt a date field of the collection schema,
+// otherwise the first update fails with a setup error.
+func MakeAuditFieldsImmutable(createdBy, createdAt string, fields ...string) func(e *core.RecordEvent) error {
+	args := []interface{}{createdBy, createdAt}
+	for _, field := range fields {
+		args = append(args, field)
+	}
+
+	cfg := parseArgs("MakeAuditFieldsImmutable", args)
+	if cfg.setupErr == nil && (createdBy == "" || createdAt == "") {
+		cfg.setupErr = errors.New("pbimmutable.MakeAuditFieldsImmutable: the created by and created at field names must be provided")
+	}
+	WithNormalizer(models.SystemFieldCreated, DateNormalizer())(&cfg)
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		pending := e.Record
+		if schemaField := pending.Schema().GetFieldByName(createdBy); schemaField == nil || schemaField.Type != schema.FieldTypeRelation {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeAuditFieldsImmutable setup error: '%s' is not a relation field of collection %s.", createdBy, pending.Collection().Name), nil)
+		}
+		if schemaField := pending.Schema().GetFieldByName(createdAt); schemaField == nil || schemaField.Type != schema.FieldTypeDate {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeAuditFieldsImmutable setup error: '%s' is not a date field of collection %s.", createdAt, pending.Collection().Name), nil)
+		}
+
+		return cfg.cmp.evaluate(original, pending, cfg.fields), nil
+	}
+
+	return newHook(cfg)
+}
+
+// DateNormalizer returns a Normalizer that converts a date value (a types.DateTime,
+// a time.Time or a date string) into a UTC time.Time, so that the same instant compares
+// equal regardless of its format or time zone. Unset values become the zero time. Values
+// that can't be interpreted are returned unchanged and compared strictly.
+//
+// Date schema fields are always compared as instants, so it is only needed for fields
+// without the date type, e.g. the system created field or a text field holding dates.
+//
+// Usage example:
+// MakeImmutable("created", WithNormalizer("created", DateNormalizer()))
+func DateNormalizer() Normalizer {
+	return func(value any) any {
+		if t, ok := toTime(value); ok {
+			return t
+		}
+		return value
+	}
+}
@@ -0,0 +1,127 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestMakeAuditFieldsImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	author := models.NewRecord(coll)
+	author.Set("name", "author")
+	if err := app.Dao().SaveRecord(author); err != nil {
+		t.Fatalf("Failed to save related record: %v", err)
+	}
+
+	notes := &models.Collection{
+		Name: "test_notes",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "body", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "createdBy", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: coll.Id, MaxSelect: types.Pointer(1)}},
+			&schema.SchemaField{Name: "createdAt", Type: schema.FieldTypeDate},
+		),
+	}
+	if err := app.Dao().SaveCollection(notes); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(notes)
+	initialRecord.Set("body", "audit_test")
+	initialRecord.Set("createdBy", author.Id)
+	initialRecord.Set("createdAt", "2024-05-01 10:00:00.000Z")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	sameInstant := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	tests := []struct {
+		name                string
+		fields              []string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"other field changed", nil, map[string]interface{}{"body": "changed"}, ""},
+		{"same creator as a list", nil, map[string]interface{}{"createdBy": []string{author.Id}}, ""},
+		{"same instant in another zone", nil, map[string]interface{}{"createdAt": sameInstant}, ""},
+		{"creator changed", nil, map[string]interface{}{"createdBy": "other_id"}, "Attempt to modify immutable field 'createdBy'"},
+		{"creator cleared", nil, map[string]interface{}{"createdBy": ""}, "Attempt to modify immutable field 'createdBy'"},
+		{"date changed", nil, map[string]interface{}{"createdAt": "2024-05-02 10:00:00.000Z"}, "Attempt to modify immutable field 'createdAt'"},
+		{"system created changed", []string{models.SystemFieldCreated}, map[string]interface{}{"created": "2020-01-01 00:00:00.000Z"}, "Attempt to modify immutable field 'created'"},
+		{"system created not frozen by default", nil, map[string]interface{}{"created": "2020-01-01 00:00:00.000Z"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeAuditFieldsImmutable("createdBy", "createdAt", tc.fields...)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("setup errors", func(t *testing.T) {
+		setupTests := []struct {
+			createdBy, createdAt string
+			expectErrorContains  string
+		}{
+			{"", "createdAt", "the created by and created at field names must be provided"},
+			{"body", "createdAt", "'body' is not a relation field of collection test_notes"},
+			{"createdBy", "body", "'body' is not a date field of collection test_notes"},
+		}
+
+		for _, tc := range setupTests {
+			err := MakeAuditFieldsImmutable(tc.createdBy, tc.createdAt)(newUpdateEvent(app, initialRecord, map[string]interface{}{"body": "changed"}))
+			if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+			}
+		}
+	})
+}
+
+func TestDateNormalizer(t *testing.T) {
+	normalize := DateNormalizer()
+	utc := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    any
+		expected any
+	}{
+		{"nil", nil, time.Time{}},
+		{"empty string", "", time.Time{}},
+		{"zoned time", time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60)), utc},
+		{"date string", "2024-05-01 10:00:00.000Z", utc},
+		{"unsupported value", 42, 42},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := normalize(tc.value)
+			if gotTime, ok := got.(time.Time); ok {
+				if expectedTime, ok := tc.expected.(time.Time); !ok || !gotTime.Equal(expectedTime) {
+					t.Errorf("Expected %v, got %v", tc.expected, got)
+				}
+				return
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
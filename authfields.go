@@ -0,0 +1,65 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// DefaultAuthFields lists the auth collection fields frozen by MakeAuthFieldsImmutable.
+var DefaultAuthFields = []string{
+	schema.FieldNameVerified,
+	schema.FieldNameEmailVisibility,
+	schema.FieldNameTokenKey,
+}
+
+// MakeAuthFieldsImmutable returns a hook for auth collections that freezes the
+// DefaultAuthFields and the additionally provided fields, e.g. "email" or "username".
+//
+// The auth fields aren't part of the collection schema, so MakeImmutable() without field
+// names never freezes them. They can be listed explicitly, but PocketBase changes some of
+// them in its own flows. This preset keeps those flows working:
+//   - verified may change from false to true (the email verification confirmation),
+//     but a verified record can't be unverified;
+//   - tokenKey may change together with passwordHash (PocketBase renews it on every
+//     password change and reset, which invalidates the existing auth tokens);
+//   - emailVisibility is never changed by PocketBase itself and is always frozen.
+//
+// Don't freeze passwordHash, lastResetSentAt or lastVerificationSentAt: they are written
+// by the password reset and verification flows. Freezing email also blocks the email
+// change confirmation flow.
+//
+// On a collection that isn't an auth collection, the auth fields are reported as unknown
+// fields (see MakeImmutable).
+func MakeAuthFieldsImmutable(fields ...string) func(e *core.RecordEvent) error {
+	args := make([]interface{}, 0, len(DefaultAuthFields)+len(fields))
+	for _, field := range DefaultAuthFields {
+		args = append(args, field)
+	}
+	for _, field := range fields {
+		if !containsString(DefaultAuthFields, field) {
+			args = append(args, field)
+		}
+	}
+
+	cfg := parseArgs("MakeAuthFieldsImmutable", args)
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		changes := cfg.cmp.evaluate(original, e.Record, cfg.fields)
+		for i, change := range changes {
+			if !change.Violated {
+				continue
+			}
+
+			switch change.Field {
+			case schema.FieldNameVerified:
+				changes[i].Violated = original.Verified() || !e.Record.Verified()
+			case schema.FieldNameTokenKey:
+				changes[i].Violated = original.PasswordHash() == e.Record.PasswordHash()
+			}
+		}
+		return changes, nil
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,87 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeAuthFieldsImmutable(t *testing.T) {
+	app, baseColl, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name:   "test_members",
+		Type:   models.CollectionTypeAuth,
+		Schema: schema.NewSchema(&schema.SchemaField{Name: "nickname", Type: schema.FieldTypeText}),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newMember := func(email string, verified bool) *models.Record {
+		record := models.NewRecord(coll)
+		record.SetEmail(email)
+		record.SetUsername(strings.Split(email, "@")[0])
+		record.SetVerified(verified)
+		record.SetPassword("1234567890")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	unverified := newMember("unverified@example.com", false)
+	verified := newMember("verified@example.com", true)
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		fields              []string
+		update              func(record *models.Record)
+		expectErrorContains string
+	}{
+		{"verify", unverified, nil, func(r *models.Record) { r.SetVerified(true) }, ""},
+		{"unverify", verified, nil, func(r *models.Record) { r.SetVerified(false) }, "Attempt to modify immutable field 'verified'"},
+		{"email visibility", verified, nil, func(r *models.Record) { r.SetEmailVisibility(true) }, "Attempt to modify immutable field 'emailVisibility'"},
+		{"token key alone", verified, nil, func(r *models.Record) { r.RefreshTokenKey() }, "Attempt to modify immutable field 'tokenKey'"},
+		{"password change renews token key", verified, nil, func(r *models.Record) { r.SetPassword("0987654321") }, ""},
+		{"schema field stays editable", verified, nil, func(r *models.Record) { r.Set("nickname", "changed") }, ""},
+		{"additional field", verified, []string{"email"}, func(r *models.Record) { r.SetEmail("changed@example.com") }, "Attempt to modify immutable field 'email'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeAuthFieldsImmutable(tc.fields...)
+
+			record := tc.original.CleanCopy()
+			tc.update(record)
+
+			err := hookFunc(&core.RecordEvent{App: app, Record: record})
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("base collection", func(t *testing.T) {
+		record := models.NewRecord(baseColl)
+		record.Set("name", "not_an_auth_record")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+
+		err := MakeAuthFieldsImmutable()(newUpdateEvent(app, record, map[string]interface{}{"name": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), `unknown fields "verified", "emailVisibility", "tokenKey"`) {
+			t.Errorf("Expected unknown fields error, got: %v", err)
+		}
+	})
+}
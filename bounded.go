@@ -0,0 +1,53 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RuleBoundedChange is the kind of the rule created by MakeBoundedChange.
+const RuleBoundedChange RuleKind = "bounded_change"
+
+// MakeBoundedChange returns a hook that allows a numeric field to change only within
+// the given bound of its original value, e.g. MakeBoundedChange("score", 5) accepts
+// any new score within ±5 of the stored one.
+//
+// Both values are coerced to float64 before computing the absolute delta.
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeBoundedChange(field string, bound float64, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeBoundedChange", args)
+	if cfg.setupErr == nil {
+		switch {
+		case field == "":
+			cfg.setupErr = errors.New("pbimmutable.MakeBoundedChange: a field name must be provided")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeBoundedChange: only a callback can be passed as additional argument")
+		case bound < 0 || math.IsNaN(bound):
+			cfg.setupErr = fmt.Errorf("pbimmutable.MakeBoundedChange: invalid bound %v", bound)
+		}
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		pending := e.Record
+		change := newChange(original, pending, field, RuleBoundedChange)
+
+		delta := math.Abs(pending.GetFloat(field) - original.GetFloat(field))
+		if delta > bound {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' can't change by %v (allowed bound is %v).", field, delta, bound)
+			change.Details = map[string]any{
+				"delta": delta,
+				"bound": bound,
+			}
+		}
+
+		return ChangeSet{change}, nil
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,64 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeBoundedChange(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "bounded_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		bound               float64
+		value               interface{}
+		expectErrorContains string
+	}{
+		{"unchanged", 5, 100, ""},
+		{"increase within bound", 5, 104, ""},
+		{"decrease within bound", 5, 96.5, ""},
+		{"exactly at bound", 5, 105, ""},
+		{"numeric string within bound", 5, "103", ""},
+		{"increase above bound", 5, 106, "can't change by 6 (allowed bound is 5)"},
+		{"decrease above bound", 5, 90, "can't change by 10 (allowed bound is 5)"},
+		{"zero bound behaves like immutable", 0, 101, "can't change by 1 (allowed bound is 0)"},
+		{"negative bound", -1, 100, "MakeBoundedChange setup error"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeBoundedChange("value", tc.bound)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"value": tc.value}))
+
+			if tc.expectErrorContains != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tc.expectErrorContains)
+				} else if !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("field names are not accepted", func(t *testing.T) {
+		hookFunc := MakeBoundedChange("value", 5, "name")
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, nil))
+		if err == nil || !strings.Contains(err.Error(), "only a callback can be passed") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// ImmutableBuilder builds an ImmutableConfig with chained method calls
+// (see NewImmutable).
+type ImmutableBuilder struct {
+	config ImmutableConfig
+}
+
+// NewImmutable returns a builder for a hook configured by chained method calls,
+// a more readable alternative to the variadic MakeImmutable arguments, e.g.
+//
+//	pbimmutable.NewImmutable().
+//		Fields("sku", "price").
+//		AllowSuperusers().
+//		OnCommit(myCallback).
+//		Build()
+//
+// Every method sets the ImmutableConfig field of the same name (see MakeImmutableWith).
+// Build checks the combination, e.g. FreezeAll together with Fields, and returns a hook
+// that fails with the setup error.
+func NewImmutable() *ImmutableBuilder {
+	return &ImmutableBuilder{}
+}
+
+// Fields adds frozen fields (see ImmutableConfig.Fields).
+func (b *ImmutableBuilder) Fields(fields ...string) *ImmutableBuilder {
+	b.config.Fields = append(b.config.Fields, fields...)
+	return b
+}
+
+// FreezeAll freezes all user-defined fields (see ImmutableConfig.FreezeAll).
+func (b *ImmutableBuilder) FreezeAll() *ImmutableBuilder {
+	b.config.FreezeAll = true
+	return b
+}
+
+// CollectionFields freezes the provided fields instead of the default ones in the named
+// collection (see ImmutableConfig.CollectionFields), replacing any previous entry.
+func (b *ImmutableBuilder) CollectionFields(collection string, fields ...string) *ImmutableBuilder {
+	if b.config.CollectionFields == nil {
+		b.config.CollectionFields = make(map[string][]string)
+	}
+	b.config.CollectionFields[collection] = fields
+	return b
+}
+
+// OnCommit sets the callback (see ImmutableConfig.Callback), replacing any previous one.
+func (b *ImmutableBuilder) OnCommit(callback func(e *core.RecordEvent) error) *ImmutableBuilder {
+	b.config.Callback = callback
+	b.config.OriginalCallback = nil
+	return b
+}
+
+// OnCommitWithOriginal sets a callback that also receives the original record
+// (see ImmutableConfig.OriginalCallback), replacing any previous one.
+func (b *ImmutableBuilder) OnCommitWithOriginal(callback func(e *core.RecordEvent, original *models.Record) error) *ImmutableBuilder {
+	b.config.OriginalCallback = callback
+	b.config.Callback = nil
+	return b
+}
+
+// RunAfterCommit runs the callback after e.Next() (see ImmutableConfig.RunAfterCommit).
+func (b *ImmutableBuilder) RunAfterCommit() *ImmutableBuilder {
+	b.config.RunAfterCommit = true
+	return b
+}
+
+// AllowSuperusers lets superusers change the frozen fields (see ImmutableConfig.AllowSuperusers).
+func (b *ImmutableBuilder) AllowSuperusers() *ImmutableBuilder {
+	b.config.AllowSuperusers = true
+	return b
+}
+
+// AllowActors adds auth record ids that may change the frozen fields
+// (see ImmutableConfig.AllowedActors).
+func (b *ImmutableBuilder) AllowActors(authRecordIds ...string) *ImmutableBuilder {
+	b.config.AllowedActors = append(b.config.AllowedActors, authRecordIds...)
+	return b
+}
+
+// CaseInsensitive matches the fields case-insensitively (see ImmutableConfig.CaseInsensitive).
+func (b *ImmutableBuilder) CaseInsensitive() *ImmutableBuilder {
+	b.config.CaseInsensitive = true
+	return b
+}
+
+// TreatEmptyAsEqual treats nil and "" as equal (see ImmutableConfig.TreatEmptyAsEqual).
+func (b *ImmutableBuilder) TreatEmptyAsEqual() *ImmutableBuilder {
+	b.config.TreatEmptyAsEqual = true
+	return b
+}
+
+// Messages sets the custom violation messages (see ImmutableConfig.Messages).
+func (b *ImmutableBuilder) Messages(messages map[string]string) *ImmutableBuilder {
+	b.config.Messages = messages
+	return b
+}
+
+// LogViolations logs the rejected updates (see ImmutableConfig.LogViolations).
+func (b *ImmutableBuilder) LogViolations() *ImmutableBuilder {
+	b.config.LogViolations = true
+	return b
+}
+
+// Metrics reports the hook outcomes to metrics (see ImmutableConfig.Metrics).
+func (b *ImmutableBuilder) Metrics(metrics Metrics) *ImmutableBuilder {
+	b.config.Metrics = metrics
+	return b
+}
+
+// With adds options, e.g. WithRequireMFA or FreezeWhen (see ImmutableConfig.Options).
+func (b *ImmutableBuilder) With(options ...Option) *ImmutableBuilder {
+	b.config.Options = append(b.config.Options, options...)
+	return b
+}
+
+// Config returns a copy of the built configuration.
+func (b *ImmutableBuilder) Config() ImmutableConfig {
+	config := b.config
+	config.Fields = append([]string(nil), b.config.Fields...)
+	config.AllowedActors = append([]string(nil), b.config.AllowedActors...)
+	config.Options = append([]Option(nil), b.config.Options...)
+	return config
+}
+
+// Build returns the hook for the configuration. Invalid combinations are reported
+// as a setup error when the hook runs, like for MakeImmutableWith.
+func (b *ImmutableBuilder) Build() func(e *core.RecordEvent) error {
+	return newHook(b.Config().build("NewImmutable"))
+}
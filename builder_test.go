@@ -0,0 +1,103 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestNewImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "builder_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	failingCallback := func(e *core.RecordEvent) error {
+		return errors.New("callback failed")
+	}
+
+	tests := []struct {
+		name                string
+		builder             *ImmutableBuilder
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"listed field unchanged", NewImmutable().Fields("name"), map[string]interface{}{"status": "changed"}, ""},
+		{"listed field changed", NewImmutable().Fields("name"), map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"chained fields", NewImmutable().Fields("name").Fields("status"), map[string]interface{}{"status": "changed"}, "Attempt to modify immutable field 'status'"},
+		{"freeze all", NewImmutable().FreezeAll(), map[string]interface{}{"value": 5}, "Attempt to modify immutable field 'value'"},
+		{"callback", NewImmutable().Fields("name").OnCommit(failingCallback), map[string]interface{}{}, "user callback failed, record changes were not saved: callback failed"},
+		{"callback after commit", NewImmutable().Fields("name").OnCommit(failingCallback).RunAfterCommit(), map[string]interface{}{}, "user callback failed AFTER record commit: callback failed"},
+		{"messages", NewImmutable().Fields("name").Messages(map[string]string{"name": "The name of {field} is fixed."}), map[string]interface{}{"name": "changed"}, "The name of name is fixed."},
+		{"options", NewImmutable().Fields("name").With(WithNormalizer("name", func(v any) any { return strings.ToLower(v.(string)) })), map[string]interface{}{"name": "BUILDER_TEST"}, ""},
+		{"freeze all with fields", NewImmutable().Fields("name").FreezeAll(), map[string]interface{}{}, "NewImmutable setup error: pbimmutable.NewImmutable: FreezeAll can't be combined with Fields"},
+		{"no fields", NewImmutable(), map[string]interface{}{}, "pbimmutable.NewImmutable: no fields provided"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.builder.Build()(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("superusers and actors", func(t *testing.T) {
+		admin := &models.Admin{}
+		admin.Id = "admin_id"
+		service := models.NewRecord(coll)
+		service.Id = "service_id"
+
+		hookFunc := NewImmutable().Fields("name").AllowSuperusers().AllowActors("service_id").Build()
+		updates := map[string]interface{}{"name": "changed"}
+
+		for _, info := range []*models.RequestInfo{{Admin: admin}, {AuthRecord: service}} {
+			if err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, info)); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		}
+		if err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, &models.RequestInfo{})); err == nil {
+			t.Error("Expected the guest update to be rejected")
+		}
+	})
+
+	t.Run("callback replacement", func(t *testing.T) {
+		var gotOriginal string
+		builder := NewImmutable().Fields("name").
+			OnCommit(failingCallback).
+			OnCommitWithOriginal(func(e *core.RecordEvent, original *models.Record) error {
+				gotOriginal = original.GetString("name")
+				return nil
+			})
+
+		if err := builder.Build()(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "changed"})); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if gotOriginal != "builder_test" {
+			t.Errorf("Expected the original record, got name %q", gotOriginal)
+		}
+	})
+
+	t.Run("config copy", func(t *testing.T) {
+		builder := NewImmutable().Fields("name")
+		config := builder.Config()
+		builder.Fields("status")
+
+		if len(config.Fields) != 1 || config.Fields[0] != "name" {
+			t.Errorf("Expected the config copy to be unaffected, got %v", config.Fields)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package pbimmutable
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// fieldCacheEnabled toggles the shared resolved fields cache (disabled by default).
+var fieldCacheEnabled atomic.Bool
+
+// fieldCache stores the resolved user-defined field names per collection and the
+// resolved fields of the hooks per hook and collection (see resolvedFields).
+var fieldCache = struct {
+	sync.RWMutex
+	entries  map[string]fieldCacheEntry
+	resolved map[resolvedCacheKey]resolvedCacheEntry
+}{entries: map[string]fieldCacheEntry{}, resolved: map[resolvedCacheKey]resolvedCacheEntry{}}
+
+type fieldCacheEntry struct {
+	fingerprint uint64
+	fields      []string
+}
+
+// SetFieldCache enables or disables the shared cache of resolved field names.
+//
+// When enabled, the list of user-defined fields checked by hooks that freeze "all" fields
+// is computed once per collection and reused across requests, and so are the fields each
+// hook compares (with their field patterns expanded and their schema lookups done). Entries
+// are keyed on a fingerprint of the collection schema, so a schema change invalidates them
+// automatically. Disabling the cache also drops all cached entries.
+func SetFieldCache(enabled bool) {
+	fieldCacheEnabled.Store(enabled)
+
+	if !enabled {
+		fieldCache.Lock()
+		fieldCache.entries = map[string]fieldCacheEntry{}
+		fieldCache.resolved = map[resolvedCacheKey]resolvedCacheEntry{}
+		fieldCache.Unlock()
+	}
+}
+
+// fieldCacheKey returns the key of the record collection in the field cache.
+func fieldCacheKey(record *models.Record) string {
+	if id := record.Collection().Id; id != "" {
+		return id
+	}
+	return record.Collection().Name
+}
+
+// cachedUserFields returns the cached user-defined field names of the record collection,
+// resolving and storing them if missing or stale.
+//
+// The returned slice is shared and must not be modified.
+func cachedUserFields(record *models.Record) []string {
+	key := fieldCacheKey(record)
+	fingerprint := schemaFingerprint(record)
+
+	fieldCache.RLock()
+	entry, ok := fieldCache.entries[key]
+	fieldCache.RUnlock()
+	if ok && entry.fingerprint == fingerprint {
+		return entry.fields
+	}
+
+	fields := resolveUserFields(record)
+
+	fieldCache.Lock()
+	fieldCache.entries[key] = fieldCacheEntry{fingerprint: fingerprint, fields: fields}
+	fieldCache.Unlock()
+
+	return fields
+}
+
+// schemaFingerprint computes an allocation free FNV-1a hash of the record schema field names and types.
+func schemaFingerprint(record *models.Record) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+
+	hash := uint64(offset64)
+	write := func(s string) {
+		for i := 0; i < len(s); i++ {
+			hash ^= uint64(s[i])
+			hash *= prime64
+		}
+		// separator to avoid ambiguous concatenations
+		hash ^= 0xff
+		hash *= prime64
+	}
+
+	for _, field := range record.Schema().Fields() {
+		write(field.Name)
+		write(field.Type)
+	}
+
+	return hash
+}
+
+// hookIds provides the ids of the hooks in the field cache (see hookConfig.cacheId).
+var hookIds atomic.Uint64
+
+// resolvedCacheKey identifies the resolved fields of a hook for a collection.
+type resolvedCacheKey struct {
+	hook       uint64
+	collection string
+}
+
+// resolvedCacheEntry holds the resolved fields of a hook for a collection (see resolvedFields).
+type resolvedCacheEntry struct {
+	fingerprint uint64
+	fields      []resolvedField
+	ok          bool
+}
+
+// resolvedFields returns the fields compared by the default immutability check for the
+// record collection (see fieldsFor and comparer.resolve). ok is false when nothing is frozen.
+//
+// With the field cache enabled (see SetFieldCache), the result is cached per hook and
+// collection, so that the field list (including the expansion of all user-defined fields
+// and of the patterns) and the schema lookups are resolved once instead of on every update.
+// Like the cached user-defined fields, an entry is keyed on the schema fingerprint.
+//
+// The returned slice is shared and must not be modified.
+func (cfg *hookConfig) resolvedFields(record *models.Record) ([]resolvedField, bool) {
+	if !fieldCacheEnabled.Load() {
+		fields, ok := cfg.fieldsFor(record)
+		if !ok {
+			return nil, false
+		}
+		return cfg.cmp.resolve(record, fields), true
+	}
+
+	key := resolvedCacheKey{hook: cfg.cacheId, collection: fieldCacheKey(record)}
+	fingerprint := schemaFingerprint(record)
+
+	fieldCache.RLock()
+	entry, ok := fieldCache.resolved[key]
+	fieldCache.RUnlock()
+	if ok && entry.fingerprint == fingerprint {
+		return entry.fields, entry.ok
+	}
+
+	entry = resolvedCacheEntry{fingerprint: fingerprint}
+	if fields, ok := cfg.fieldsFor(record); ok {
+		entry.fields, entry.ok = cfg.cmp.resolve(record, fields), true
+	}
+
+	fieldCache.Lock()
+	fieldCache.resolved[key] = entry
+	fieldCache.Unlock()
+
+	return entry.fields, entry.ok
+}
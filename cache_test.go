@@ -0,0 +1,202 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func newCacheTestCollection(id string, fields ...string) *models.Collection {
+	coll := &models.Collection{Name: id, Type: models.CollectionTypeBase}
+	coll.Id = id
+	for _, name := range fields {
+		coll.Schema.AddField(&schema.SchemaField{Name: name, Type: schema.FieldTypeText})
+	}
+	return coll
+}
+
+func TestFieldCache(t *testing.T) {
+	SetFieldCache(true)
+	defer SetFieldCache(false)
+
+	coll := newCacheTestCollection("cache_a", "name", "status")
+	record := models.NewRecord(coll)
+
+	if fields := userFields(record); len(fields) != 2 {
+		t.Fatalf("Expected 2 fields, got %v", fields)
+	}
+
+	t.Run("schema change invalidates the entry", func(t *testing.T) {
+		coll.Schema.AddField(&schema.SchemaField{Name: "description", Type: schema.FieldTypeText})
+
+		if fields := userFields(record); len(fields) != 3 {
+			t.Errorf("Expected the new field to be resolved, got %v", fields)
+		}
+	})
+
+	t.Run("disabling drops the entries", func(t *testing.T) {
+		SetFieldCache(false)
+		defer SetFieldCache(true)
+
+		fieldCache.RLock()
+		size := len(fieldCache.entries)
+		fieldCache.RUnlock()
+		if size != 0 {
+			t.Errorf("Expected an empty cache, got %d entries", size)
+		}
+	})
+
+	t.Run("concurrent access to different collections", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				fieldNames := make([]string, i+1)
+				for j := range fieldNames {
+					fieldNames[j] = fmt.Sprintf("field%d", j)
+				}
+				record := models.NewRecord(newCacheTestCollection(fmt.Sprintf("cache_concurrent_%d", i), fieldNames...))
+
+				for n := 0; n < 100; n++ {
+					if fields := userFields(record); len(fields) != i+1 {
+						t.Errorf("Collection %d: expected %d fields, got %v", i, i+1, fields)
+						return
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func BenchmarkUserFields(b *testing.B) {
+	fieldNames := make([]string, 40)
+	for i := range fieldNames {
+		fieldNames[i] = fmt.Sprintf("field%d", i)
+	}
+	record := models.NewRecord(newCacheTestCollection("cache_bench", fieldNames...))
+
+	b.Run("uncached", func(b *testing.B) {
+		SetFieldCache(false)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			userFields(record)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		SetFieldCache(true)
+		defer SetFieldCache(false)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			userFields(record)
+		}
+	})
+}
+
+func TestResolvedFields(t *testing.T) {
+	coll := newCacheTestCollection("resolved_a", "name", "status")
+	record := models.NewRecord(coll)
+
+	cfg := parseArgs("MakeImmutable", nil)
+
+	names := func() string {
+		fields, ok := cfg.resolvedFields(record)
+		if !ok {
+			return "-"
+		}
+		list := make([]string, len(fields))
+		for i, field := range fields {
+			list[i] = field.name
+		}
+		return strings.Join(list, ",")
+	}
+
+	cached := func() bool {
+		fieldCache.RLock()
+		defer fieldCache.RUnlock()
+		_, ok := fieldCache.resolved[resolvedCacheKey{hook: cfg.cacheId, collection: coll.Name}]
+		return ok
+	}
+
+	t.Run("disabled cache", func(t *testing.T) {
+		if got := names(); got != "name,status" {
+			t.Fatalf("Expected the user-defined fields, got %q", got)
+		}
+		if cached() {
+			t.Errorf("Expected no cache entry while the cache is disabled")
+		}
+	})
+
+	SetFieldCache(true)
+	defer SetFieldCache(false)
+
+	t.Run("entry is stored", func(t *testing.T) {
+		if got := names(); got != "name,status" {
+			t.Fatalf("Expected the user-defined fields, got %q", got)
+		}
+		if !cached() {
+			t.Errorf("Expected a cache entry")
+		}
+	})
+
+	t.Run("schema change with the same timestamp and field count invalidates the entry", func(t *testing.T) {
+		coll.Schema.GetFieldByName("status").Name = "state"
+		defer func() { coll.Schema.GetFieldByName("state").Name = "status" }()
+
+		if got := names(); got != "name,state" {
+			t.Errorf("Expected the renamed field to be resolved, got %q", got)
+		}
+	})
+
+	t.Run("added field invalidates the entry", func(t *testing.T) {
+		coll.Schema.AddField(&schema.SchemaField{Name: "description", Type: schema.FieldTypeText})
+
+		if got := names(); got != "name,status,description" {
+			t.Errorf("Expected the new field to be resolved, got %q", got)
+		}
+	})
+
+	t.Run("disabling drops the entries", func(t *testing.T) {
+		SetFieldCache(false)
+		defer SetFieldCache(true)
+
+		if cached() {
+			t.Errorf("Expected the cache entries to be dropped")
+		}
+	})
+}
+
+func BenchmarkResolvedFields(b *testing.B) {
+	fieldNames := make([]string, 40)
+	for i := range fieldNames {
+		fieldNames[i] = fmt.Sprintf("field%d", i)
+	}
+	coll := newCacheTestCollection("resolved_bench", fieldNames...)
+	record := models.NewRecord(coll)
+
+	cfg := parseArgs("MakeImmutable", nil)
+
+	b.Run("per request", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			fields, _ := cfg.fieldsFor(record)
+			cfg.cmp.resolve(record, fields)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		SetFieldCache(true)
+		defer SetFieldCache(false)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cfg.resolvedFields(record)
+		}
+	})
+}
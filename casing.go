@@ -0,0 +1,63 @@
+package pbimmutable
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// WithCaseInsensitiveFields matches the field names of the hook against the collection
+// schema case-insensitively, e.g. "emailaddress" freezes the "emailAddress" field.
+//
+// The names are resolved to the schema casing before they are checked, so violations
+// and error messages always report the canonical field name. Patterns and the keys
+// of JSON paths are still matched case-sensitively.
+func WithCaseInsensitiveFields() Option {
+	return func(cfg *hookConfig) {
+		cfg.cmp.caseInsensitive = true
+	}
+}
+
+// canonicalFieldNames returns names with every name resolved to the casing of the matching
+// system, schema or auth field of the record collection (see canonicalFieldName).
+func canonicalFieldNames(record *models.Record, names []string) []string {
+	canonical := make([]string, len(names))
+	for i, name := range names {
+		canonical[i] = canonicalFieldName(record, name)
+	}
+	return canonical
+}
+
+// canonicalFieldName returns the name of the field of the record collection that equals
+// name case-insensitively. For dotted JSON paths only the field part is resolved.
+// Exact matches win, and unknown names and patterns are returned unchanged.
+func canonicalFieldName(record *models.Record, name string) string {
+	if isFieldPattern(name) {
+		return name
+	}
+
+	field, path, dotted := strings.Cut(name, ".")
+	if IsSystemField(field) || record.Schema().GetFieldByName(field) != nil {
+		return name
+	}
+
+	candidates := append([]string{}, systemFieldNames...)
+	for _, schemaField := range record.Schema().Fields() {
+		candidates = append(candidates, schemaField.Name)
+	}
+	if record.Collection().IsAuth() {
+		candidates = append(candidates, schema.AuthFieldNames()...)
+	}
+
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, field) {
+			if dotted {
+				return candidate + "." + path
+			}
+			return candidate
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,61 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestWithCaseInsensitiveFields(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_contacts",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "emailAddress", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "note", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("emailAddress", "a@example.com")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		hookFunc            func(e *core.RecordEvent) error
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"case-sensitive by default", MakeImmutable("emailaddress"), map[string]interface{}{}, `unknown fields "emailaddress" in collection test_contacts`},
+		{"resolved to schema casing", MakeImmutable("emailaddress", WithCaseInsensitiveFields()), map[string]interface{}{"emailAddress": "b@example.com"}, "Attempt to modify immutable field 'emailAddress'"},
+		{"other fields stay editable", MakeImmutable("EMAILADDRESS", WithCaseInsensitiveFields()), map[string]interface{}{"note": "changed"}, ""},
+		{"system field", MakeImmutable("Created", WithCaseInsensitiveFields()), map[string]interface{}{"note": "changed"}, ""},
+		{"typed config", MakeImmutableWith(ImmutableConfig{Fields: []string{"EmailAddress"}, CaseInsensitive: true}), map[string]interface{}{"emailAddress": "b@example.com"}, "Attempt to modify immutable field 'emailAddress'"},
+		{"unknown field", MakeImmutable("mail", WithCaseInsensitiveFields()), map[string]interface{}{}, `unknown fields "mail"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package pbimmutable
+
+import (
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// chainState collects the passed hooks of a Chain run.
+type chainState struct {
+	steps []commitStep
+}
+
+// chains holds the state of the running Chain hooks per event.
+var chains sync.Map // map[*core.RecordEvent]*chainState
+
+// chainOf returns the state of the Chain hook running for the event, if any.
+func chainOf(e *core.RecordEvent) (*chainState, bool) {
+	state, ok := chains.Load(e)
+	if !ok {
+		return nil, false
+	}
+	return state.(*chainState), true
+}
+
+// Chain combines several hooks of this package into one hook, e.g. to freeze some fields
+// and guard the transitions of another field of the same collection:
+//
+//	app.OnRecordUpdate("articles").Add(pbimmutable.Chain(
+//		pbimmutable.MakeImmutable("slug"),
+//		pbimmutable.MakeTransitionGuard("status", transitions),
+//	))
+//
+// The hooks run in order and only check the update; the first rejection is returned.
+// Once all of them passed, the callbacks run as configured for each hook and e.Next()
+// is called exactly once.
+//
+// Only hooks created by this package (including nested Chain hooks) can be combined.
+// Other functions are called as-is and must not call e.Next() themselves.
+func Chain(hooks ...func(e *core.RecordEvent) error) func(e *core.RecordEvent) error {
+	return func(e *core.RecordEvent) error {
+		if _, ok := chainOf(e); ok {
+			// nested chain: the outer chain commits
+			return runHooks(e, hooks)
+		}
+
+		state := &chainState{}
+		err := func() error {
+			chains.Store(e, state)
+			defer chains.Delete(e)
+			return runHooks(e, hooks)
+		}()
+		if err != nil {
+			return err
+		}
+
+		return commit(e, state.steps)
+	}
+}
+
+// runHooks runs the hooks in order and returns the first error.
+func runHooks(e *core.RecordEvent, hooks []func(e *core.RecordEvent) error) error {
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		if err := hook(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
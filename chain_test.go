@@ -0,0 +1,89 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestChain(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "chain_test")
+	initialRecord.Set("status", "draft")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	transitions := map[string][]string{"draft": {"review"}}
+
+	tests := []struct {
+		name                string
+		updates             map[string]interface{}
+		expectErrorContains string
+		expectCalls         string
+	}{
+		{"all hooks pass", map[string]interface{}{"status": "review", "value": 5}, "", "freeze,guard,nested"},
+		{"first hook rejects", map[string]interface{}{"name": "changed", "status": "review"}, "Attempt to modify immutable field 'name'", ""},
+		{"second hook rejects", map[string]interface{}{"status": "published"}, "Field 'status' can't change from 'draft' to 'published'.", ""},
+		{"nested hook rejects", map[string]interface{}{"description": "changed"}, "Attempt to modify immutable field 'description'", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			callback := func(name string) func(e *core.RecordEvent) error {
+				return func(e *core.RecordEvent) error {
+					calls = append(calls, name)
+					return nil
+				}
+			}
+
+			hookFunc := Chain(
+				MakeImmutable("name", callback("freeze")),
+				MakeTransitionGuard("status", transitions, callback("guard")),
+				Chain(MakeImmutable("description", callback("nested"))),
+			)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if strings.Join(calls, ",") != tc.expectCalls {
+				t.Errorf("Expected callbacks %q, got %q", tc.expectCalls, strings.Join(calls, ","))
+			}
+		})
+	}
+
+	t.Run("callback error", func(t *testing.T) {
+		hookFunc := Chain(
+			MakeImmutable("name", func(e *core.RecordEvent) error { return errors.New("callback failed") }),
+			MakeImmutable("description"),
+		)
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "review"}))
+		if err == nil || !strings.Contains(err.Error(), "user callback failed, record changes were not saved: callback failed") {
+			t.Errorf("Expected callback error, got: %v", err)
+		}
+	})
+
+	t.Run("chain state is released", func(t *testing.T) {
+		event := newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"})
+		if err := Chain(MakeImmutable("name"))(event); err == nil {
+			t.Fatalf("Expected immutable field error, got nil")
+		}
+		if _, ok := chainOf(event); ok {
+			t.Errorf("Expected the chain state to be removed after the run")
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RuleKind identifies the rule that evaluated a field.
+type RuleKind string
+
+// RuleImmutable is the kind of the plain "value must not change" rule.
+const RuleImmutable RuleKind = "immutable"
+
+// Change describes the evaluation result of a single field.
+type Change struct {
+	Field    string   // The field name.
+	Type     string   // The schema field type (empty for system fields).
+	Old      any      // The value stored in the original record.
+	New      any      // The value carried by the pending record.
+	Violated bool     // Whether the change breaks the rule.
+	RuleKind RuleKind // The rule that evaluated the field.
+
+	Message string         // Optional human readable explanation of the violation.
+	Details map[string]any // Optional rule specific data, e.g. for a WithViolationResponder.
+}
+
+// ChangeSet holds one Change entry for every evaluated field, in evaluation order.
+type ChangeSet []Change
+
+// Violations returns only the entries that violated their rule.
+func (cs ChangeSet) Violations() ChangeSet {
+	var violations ChangeSet
+	for _, change := range cs {
+		if change.Violated {
+			violations = append(violations, change)
+		}
+	}
+	return violations
+}
+
+// HasViolations reports whether any entry violated its rule.
+func (cs ChangeSet) HasViolations() bool {
+	for _, change := range cs {
+		if change.Violated {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the entry for the given field name, if it was evaluated.
+func (cs ChangeSet) Get(field string) (Change, bool) {
+	for _, change := range cs {
+		if change.Field == field {
+			return change, true
+		}
+	}
+	return Change{}, false
+}
+
+// Evaluate compares the original and pending state of a record and returns a ChangeSet
+// describing every checked field. It has no side effects and can be used directly by
+// callbacks, audits or custom hooks.
+//
+// If fields is empty, all non-system fields of the record collection are evaluated.
+// The system "updated" field is never reported as a violation.
+func Evaluate(original, pending *models.Record, fields []string) ChangeSet {
+	return comparer{}.evaluate(original, pending, fields)
+}
+
+// DiffImmutable returns the names of the given fields whose value differs between the
+// original and pending record, in the order they were given. It uses the same comparison
+// as Evaluate and the hooks, so e.g. reordered relations or "100" and 100 in a number
+// field aren't reported.
+//
+// If fields is empty, all non-system fields of the record collection are compared.
+func DiffImmutable(original, pending *models.Record, fields []string) []string {
+	var changed []string
+	for _, change := range Evaluate(original, pending, fields).Violations() {
+		changed = append(changed, change.Field)
+	}
+	return changed
+}
+
+// newChange returns a not yet violated Change entry for the given field.
+func newChange(original, pending *models.Record, fieldName string, kind RuleKind) Change {
+	change := Change{
+		Field:    fieldName,
+		Old:      original.Get(fieldName),
+		New:      pending.Get(fieldName),
+		RuleKind: kind,
+	}
+	if field := pending.Schema().GetFieldByName(fieldName); field != nil {
+		change.Type = field.Type
+	}
+	return change
+}
+
+// userFields returns the names of all non-system schema fields of the record.
+//
+// The returned slice may be shared (see SetFieldCache) and must not be modified.
+func userFields(record *models.Record) []string {
+	if fieldCacheEnabled.Load() {
+		return cachedUserFields(record)
+	}
+	return resolveUserFields(record)
+}
+
+// resolveUserFields computes the names of all non-system schema fields of the record.
+func resolveUserFields(record *models.Record) []string {
+	schemaFields := record.Schema().Fields()
+	names := make([]string, 0, len(schemaFields))
+	for _, field := range schemaFields {
+		if !IsSystemField(field.Name) {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,109 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestEvaluate_ChangeSet(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	original := models.NewRecord(coll)
+	original.Set("name", "initial_name")
+	original.Set("value", 100)
+	original.Set("status", "active")
+	if err := app.Dao().SaveRecord(original); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	pending := original.CleanCopy()
+	pending.Set("status", "inactive")
+
+	t.Run("explicit fields", func(t *testing.T) {
+		changes := Evaluate(original, pending, []string{"name", "status"})
+		if len(changes) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(changes))
+		}
+
+		name, ok := changes.Get("name")
+		if !ok || name.Violated || name.RuleKind != RuleImmutable || name.Type != "text" {
+			t.Errorf("Unexpected entry for 'name': %+v", name)
+		}
+
+		status, ok := changes.Get("status")
+		if !ok || !status.Violated || status.Old != "active" || status.New != "inactive" {
+			t.Errorf("Unexpected entry for 'status': %+v", status)
+		}
+
+		if violations := changes.Violations(); len(violations) != 1 || violations[0].Field != "status" {
+			t.Errorf("Expected only 'status' to be violated, got %+v", violations)
+		}
+	})
+
+	t.Run("all user fields", func(t *testing.T) {
+		changes := Evaluate(original, pending, nil)
+		if len(changes) != 4 {
+			t.Fatalf("Expected an entry for every user-defined field, got %d", len(changes))
+		}
+		if _, ok := changes.Get("id"); ok {
+			t.Errorf("System fields should not be evaluated when no fields are given")
+		}
+		if !changes.HasViolations() {
+			t.Errorf("Expected the 'status' change to be reported as a violation")
+		}
+	})
+
+	t.Run("updated is never violated", func(t *testing.T) {
+		pending := original.CleanCopy()
+		pending.Set(models.SystemFieldUpdated, "2030-01-01 00:00:00.000Z")
+
+		changes := Evaluate(original, pending, []string{models.SystemFieldUpdated})
+		if changes.HasViolations() {
+			t.Errorf("Expected no violation for the 'updated' field, got %+v", changes)
+		}
+	})
+}
+
+func TestDiffImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	original := models.NewRecord(coll)
+	original.Set("name", "initial_name")
+	original.Set("value", 100)
+	original.Set("status", "active")
+	if err := app.Dao().SaveRecord(original); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		updates  map[string]any
+		fields   []string
+		expected []string
+	}{
+		{"no changes", nil, []string{"name", "value"}, nil},
+		{"changed field", map[string]any{"status": "inactive"}, []string{"name", "status"}, []string{"status"}},
+		{"change outside the given fields", map[string]any{"status": "inactive"}, []string{"name"}, nil},
+		{"same number as string", map[string]any{"value": "100"}, []string{"value"}, nil},
+		{"keeps the given order", map[string]any{"name": "x", "status": "inactive"}, []string{"status", "name"}, []string{"status", "name"}},
+		{"all user fields", map[string]any{"description": "new"}, nil, []string{"description"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pending := original.CleanCopy()
+			for field, value := range tc.updates {
+				pending.Set(field, value)
+			}
+
+			got := DiffImmutable(original, pending, tc.fields)
+			if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package pbimmutable
+
+import (
+	"reflect"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// Normalizer converts a field value into a canonical form before it is compared.
+type Normalizer func(value any) any
+
+// WithNormalizer registers a normalizer for a single field. It is applied to both
+// the original and the pending value before they are compared, so that semantically
+// equal values with a different representation are not reported as a change.
+//
+// See DurationNormalizer for a ready-made normalizer.
+func WithNormalizer(field string, normalizer Normalizer) Option {
+	return func(cfg *hookConfig) {
+		if cfg.cmp.normalizers == nil {
+			cfg.cmp.normalizers = map[string]Normalizer{}
+		}
+		cfg.cmp.normalizers[field] = normalizer
+	}
+}
+
+// comparer holds the value comparison settings of a hook.
+type comparer struct {
+	normalizers    map[string]Normalizer
+	strictJSONNull bool
+
+	// emptyAsEqual treats nil and "" as equal values of text-like fields
+	// (see WithEmptyAsEqual).
+	emptyAsEqual bool
+
+	// strictJSON compares JSON field values as they are instead of decoding them
+	// (see WithStrictJSONCompare).
+	strictJSON bool
+
+	// caseInsensitive resolves the field names to their schema casing (see WithCaseInsensitiveFields).
+	caseInsensitive bool
+
+	// systemFields, when set, reports the additional fields that are never checked
+	// (see WithSystemFields).
+	systemFields func(name string) bool
+
+	// mutableSystemFields and immutableSystemFields override the default handling of the
+	// listed system fields (see WithMutableSystemFields and WithImmutableSystemFields).
+	mutableSystemFields   []string
+	immutableSystemFields []string
+}
+
+// evaluate compares the fields of the original and pending record (see Evaluate).
+func (c comparer) evaluate(original, pending *models.Record, fields []string) ChangeSet {
+	return c.compare(original, pending, c.resolve(pending, fields))
+}
+
+// resolvedField is a field compared by the comparer, with the schema lookups done upfront.
+type resolvedField struct {
+	name        string
+	schemaField *schema.SchemaField // nil for system fields and JSON paths
+
+	// jsonField and jsonPath are set for JSON paths (see splitJSONPath)
+	jsonField string
+	jsonPath  []string
+}
+
+// resolve resolves the fields compared for the record collection (see fieldsToCheck)
+// and looks up their schema fields.
+func (c comparer) resolve(record *models.Record, fields []string) []resolvedField {
+	names := c.fieldsToCheck(record, fields)
+
+	resolved := make([]resolvedField, len(names))
+	for i, name := range names {
+		resolved[i].name = name
+		if field, path, ok := splitJSONPath(record, name); ok {
+			resolved[i].jsonField, resolved[i].jsonPath = field, path
+			continue
+		}
+		resolved[i].schemaField = record.Schema().GetFieldByName(name)
+	}
+
+	return resolved
+}
+
+// compare compares the resolved fields of the original and pending record.
+func (c comparer) compare(original, pending *models.Record, fields []resolvedField) ChangeSet {
+	changes := make(ChangeSet, 0, len(fields))
+	for _, field := range fields {
+		if field.jsonPath != nil {
+			changes = append(changes, c.evaluateJSONPath(original, pending, field.name, field.jsonField, field.jsonPath))
+			continue
+		}
+
+		change := Change{
+			Field:    field.name,
+			Old:      original.Get(field.name),
+			New:      pending.Get(field.name),
+			RuleKind: RuleImmutable,
+		}
+		if field.schemaField != nil {
+			change.Type = field.schemaField.Type
+		}
+		if !c.equalField(field.schemaField, field.name, change.Old, change.New) {
+			change.Violated = c.changeViolates(field.name)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// fieldsToCheck resolves the field names compared by evaluate for the record collection:
+// the canonical names (see WithCaseInsensitiveFields) with the patterns expanded, or all
+// user-defined fields when fields is empty, plus the immutable system fields and without
+// the fields treated as system fields (see WithSystemFields).
+func (c comparer) fieldsToCheck(record *models.Record, fields []string) []string {
+	if c.caseInsensitive {
+		fields = canonicalFieldNames(record, fields)
+	}
+
+	resolved := expandFieldPatterns(record, fields)
+	if len(fields) == 0 {
+		// If no specific fields are provided, all non-system fields are considered immutable.
+		resolved = userFields(record)
+	}
+
+	// userFields may return a shared slice, so the result is always a new one
+	names := make([]string, 0, len(resolved)+len(c.immutableSystemFields))
+	for _, name := range resolved {
+		if c.systemFields == nil || !c.systemFields(name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range c.immutableSystemFields {
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// equalValues reports whether two values of the named field of the record are considered
+// equal once both are converted to their stored form with the PrepareValue method of the
+// schema field (the same preparation PocketBase applies before saving), e.g. "true" and
+// true for a bool field or ["a"] and "a" for a single select field.
+//
+// The preparation is skipped for fields with a normalizer (which expect the raw value)
+// and for nil values of text-like fields, so that WithEmptyAsEqual decides whether nil
+// and "" are equal. Fields without a schema field (e.g. system fields) are compared as is.
+func (c comparer) equalValues(record *models.Record, field string, a, b any) bool {
+	return c.equalField(record.Schema().GetFieldByName(field), field, a, b)
+}
+
+// equalField is equalValues with the schema field of the named field already looked up
+// (nil for fields without a schema field).
+func (c comparer) equalField(schemaField *schema.SchemaField, field string, a, b any) bool {
+	if schemaField == nil {
+		return c.equal(field, "", a, b)
+	}
+
+	if _, ok := c.normalizers[field]; !ok {
+		a, b = prepareValue(schemaField, a), prepareValue(schemaField, b)
+	}
+
+	return c.equal(field, schemaField.Type, a, b)
+}
+
+// prepareValue converts value to the stored form of the schema field (see equalValues).
+func prepareValue(field *schema.SchemaField, value any) any {
+	if value == nil && isTextLikeType(field.Type) {
+		return nil
+	}
+	return field.PrepareValue(value)
+}
+
+// equal reports whether the original and pending value of a field are considered equal.
+//
+// fieldType is the schema type of the field (empty if unknown). Number field values are
+// coerced to float64 before the comparison, so that 100, 100.0 and "100" are equal.
+// Relation and file field values are compared as sets of ids and filenames, so that
+// reordering the same relations or files isn't a change; so are the values of multiple
+// select fields. Date field values are compared as instants, so that the same time in
+// another format or time zone is equal; a value that can't be parsed as a date is always
+// a change. JSON field values are decoded and compared structurally: object keys in
+// another order are equal, array items are not.
+func (c comparer) equal(field string, fieldType string, a, b any) bool {
+	if normalize, ok := c.normalizers[field]; ok {
+		a, b = normalize(a), normalize(b)
+	}
+
+	if c.emptyAsEqual && isTextLikeType(fieldType) && isNilOrEmptyString(a) && isNilOrEmptyString(b) {
+		return true
+	}
+
+	if typedEqual := comparatorFor(fieldType); typedEqual != nil {
+		if equal, ok := typedEqual(a, b); ok {
+			return equal
+		}
+		return reflect.DeepEqual(a, b)
+	}
+
+	switch fieldType {
+	case schema.FieldTypeSelect:
+		// only multiple selects hold lists (single selects are compared as scalars)
+		if !isList(a) && !isList(b) {
+			break
+		}
+		ia, okA := toStrings(a)
+		ib, okB := toStrings(b)
+		if okA && okB {
+			return equalStringSets(ia, ib)
+		}
+	case schema.FieldTypeRelation, schema.FieldTypeFile:
+		ia, okA := toStrings(a)
+		ib, okB := toStrings(b)
+		if okA && okB {
+			return equalStringSets(ia, ib)
+		}
+	case schema.FieldTypeDate:
+		ta, okA := toTime(a)
+		tb, okB := toTime(b)
+		// a value that isn't a date can't be proven equal
+		return okA && okB && ta.Equal(tb)
+	case schema.FieldTypeJson:
+		if c.strictJSON {
+			break
+		}
+		ja, okA := toJSONValue(a)
+		jb, okB := toJSONValue(b)
+		if okA && okB {
+			return reflect.DeepEqual(ja, jb)
+		}
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// typedComparator compares two values of a field type once both are converted to their
+// Go type. ok is false when either value can't be converted.
+type typedComparator func(a, b any) (equal bool, ok bool)
+
+// comparatorFor returns the typed comparator of a number, bool or text-like field type,
+// or nil for the other field types (compared by equal on their own).
+//
+// The values are converted with the coercion of the record getters (GetFloat, GetBool
+// and GetString), so that e.g. 100, 100.0 and "100" are equal in a number field and
+// 100 and "100" in a text field.
+func comparatorFor(fieldType string) typedComparator {
+	switch fieldType {
+	case schema.FieldTypeNumber:
+		return typedEqual(toFloat)
+	case schema.FieldTypeBool:
+		return typedEqual(toBool)
+	case schema.FieldTypeText, schema.FieldTypeEmail, schema.FieldTypeUrl, schema.FieldTypeEditor:
+		return typedEqual(toText)
+	}
+	return nil
+}
+
+// typedEqual returns a typedComparator that converts both values with convert.
+func typedEqual[T comparable](convert func(value any) (T, bool)) typedComparator {
+	return func(a, b any) (bool, bool) {
+		ta, okA := convert(a)
+		tb, okB := convert(b)
+		if !okA || !okB {
+			return false, false
+		}
+		return ta == tb, true
+	}
+}
+
+// WithEmptyAsEqual treats nil and the empty string as equal values of text-like fields
+// (text, email, url, editor and select fields, and fields without a schema type),
+// e.g. when a client submits "" for an optional text field that is stored as nil.
+//
+// By default both are compared strictly. Number fields are unaffected: they always treat
+// nil as 0. Other field types (e.g. bool or date) keep their own comparison.
+func WithEmptyAsEqual() Option {
+	return func(cfg *hookConfig) {
+		cfg.cmp.emptyAsEqual = true
+	}
+}
+
+// isTextLikeType reports whether values of the schema field type are plain strings.
+// The empty type stands for fields without a schema type, e.g. system fields.
+func isTextLikeType(fieldType string) bool {
+	switch fieldType {
+	case "", schema.FieldTypeText, schema.FieldTypeEmail, schema.FieldTypeUrl, schema.FieldTypeEditor, schema.FieldTypeSelect:
+		return true
+	}
+	return false
+}
+
+// isNilOrEmptyString reports whether value is nil or "".
+func isNilOrEmptyString(value any) bool {
+	s, ok := value.(string)
+	return value == nil || (ok && s == "")
+}
+
+// equalStringSets reports whether a and b contain the same strings, ignoring their order
+// and duplicates.
+func equalStringSets(a, b []string) bool {
+	set := make(map[string]bool, len(a))
+	for _, item := range a {
+		set[item] = false
+	}
+	for _, item := range b {
+		if _, ok := set[item]; !ok {
+			return false
+		}
+		set[item] = true
+	}
+	for _, seen := range set {
+		if !seen {
+			return false
+		}
+	}
+	return true
+}
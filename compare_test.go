@@ -0,0 +1,445 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestComparerEqual_Numbers(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		a, b      any
+		expected  bool
+	}{
+		{"int and float", schema.FieldTypeNumber, int(100), float64(100), true},
+		{"int64 and float", schema.FieldTypeNumber, int64(100), 100.0, true},
+		{"uint and int", schema.FieldTypeNumber, uint(7), 7, true},
+		{"numeric string and float", schema.FieldTypeNumber, "100", float64(100), true},
+		{"decimal string and float", schema.FieldTypeNumber, " 100.50 ", 100.5, true},
+		{"nil and zero", schema.FieldTypeNumber, nil, 0, true},
+		{"empty string and zero", schema.FieldTypeNumber, "", float64(0), true},
+		{"different numbers", schema.FieldTypeNumber, 100, 100.5, false},
+		{"different numeric string", schema.FieldTypeNumber, "101", 100, false},
+		{"non numeric string", schema.FieldTypeNumber, "abc", 0, false},
+		{"text field compares as text", schema.FieldTypeText, "100", float64(100), true},
+		{"text field with fraction", schema.FieldTypeText, "100", 100.5, false},
+		{"unknown type is not coerced", "", int(100), float64(100), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (comparer{}).equal("value", tc.fieldType, tc.a, tc.b); got != tc.expected {
+				t.Errorf("Expected equal(%#v, %#v) to be %v, got %v", tc.a, tc.b, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestComparatorFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		a, b      any
+		expected  bool
+		ok        bool
+	}{
+		{"number int and float", schema.FieldTypeNumber, 7, 7.0, true, true},
+		{"number string and int", schema.FieldTypeNumber, "7", 7, true, true},
+		{"number changed", schema.FieldTypeNumber, 7, 8, false, true},
+		{"number not numeric", schema.FieldTypeNumber, "seven", 7, false, false},
+		{"bool string and bool", schema.FieldTypeBool, "true", true, true, true},
+		{"bool int and bool", schema.FieldTypeBool, 1, true, true, true},
+		{"bool nil and false", schema.FieldTypeBool, nil, false, true, true},
+		{"bool changed", schema.FieldTypeBool, "false", true, false, true},
+		{"bool not a bool", schema.FieldTypeBool, "maybe", true, false, false},
+		{"text number and string", schema.FieldTypeText, 42, "42", true, true},
+		{"text bool and string", schema.FieldTypeEditor, true, "true", true, true},
+		{"text changed", schema.FieldTypeEmail, "a@example.com", "b@example.com", false, true},
+		{"text nil falls back", schema.FieldTypeText, nil, "", false, false},
+		{"text list falls back", schema.FieldTypeUrl, []string{"a"}, "a", false, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typedEqual := comparatorFor(tc.fieldType)
+			if typedEqual == nil {
+				t.Fatalf("Expected a typed comparator for %q", tc.fieldType)
+			}
+			if equal, ok := typedEqual(tc.a, tc.b); equal != tc.expected || ok != tc.ok {
+				t.Errorf("Expected (%v, %v) for %#v and %#v, got (%v, %v)", tc.expected, tc.ok, tc.a, tc.b, equal, ok)
+			}
+		})
+	}
+
+	for _, fieldType := range []string{"", schema.FieldTypeSelect, schema.FieldTypeRelation, schema.FieldTypeDate, schema.FieldTypeJson} {
+		if comparatorFor(fieldType) != nil {
+			t.Errorf("Expected no typed comparator for %q", fieldType)
+		}
+	}
+}
+
+func TestComparerEqual_Relations(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		a, b      any
+		expected  bool
+	}{
+		{"same single id", schema.FieldTypeRelation, "a1", "a1", true},
+		{"different single id", schema.FieldTypeRelation, "a1", "a2", false},
+		{"unset single id", schema.FieldTypeRelation, "", nil, true},
+		{"same ids in another order", schema.FieldTypeRelation, []string{"t1", "t2", "t3"}, []string{"t3", "t1", "t2"}, true},
+		{"untyped list in another order", schema.FieldTypeRelation, []string{"t1", "t2"}, []any{"t2", "t1"}, true},
+		{"single id and list of one", schema.FieldTypeRelation, "t1", []string{"t1"}, true},
+		{"added id", schema.FieldTypeRelation, []string{"t1", "t2"}, []string{"t1", "t2", "t3"}, false},
+		{"removed id", schema.FieldTypeRelation, []string{"t1", "t2"}, []string{"t2"}, false},
+		{"replaced id", schema.FieldTypeRelation, []string{"t1", "t2"}, []string{"t1", "t3"}, false},
+		{"empty and nil", schema.FieldTypeRelation, []string{}, nil, true},
+		{"json list keeps its order", schema.FieldTypeJson, []string{"t1", "t2"}, []string{"t2", "t1"}, false},
+		{"same options in another order", schema.FieldTypeSelect, []string{"admin", "editor"}, []string{"editor", "admin"}, true},
+		{"added option", schema.FieldTypeSelect, []string{"admin"}, []string{"admin", "editor"}, false},
+		{"same single option", schema.FieldTypeSelect, "admin", "admin", true},
+		{"different single option", schema.FieldTypeSelect, "admin", "editor", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (comparer{}).equal("tags", tc.fieldType, tc.a, tc.b); got != tc.expected {
+				t.Errorf("Expected equal(%#v, %#v) to be %v, got %v", tc.a, tc.b, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestComparerEqual_Dates(t *testing.T) {
+	stored, err := types.ParseDateTime("2024-01-01 00:00:00.000Z")
+	if err != nil {
+		t.Fatalf("Failed to parse date: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		a, b     any
+		expected bool
+	}{
+		{"without fractional seconds", stored, "2024-01-01 00:00:00Z", true},
+		{"more fractional digits", stored, "2024-01-01 00:00:00.000000Z", true},
+		{"RFC 3339", stored, "2024-01-01T00:00:00Z", true},
+		{"other time zone", stored, "2024-01-01T02:00:00+02:00", true},
+		{"time.Time in another zone", stored, time.Date(2023, 12, 31, 19, 0, 0, 0, time.FixedZone("EST", -5*60*60)), true},
+		{"unset values", "", nil, true},
+		{"different instant", stored, "2024-01-01 00:00:01Z", false},
+		{"cleared", stored, "", false},
+		{"unparseable pending", stored, "not a date", false},
+		{"unparseable on both sides", "not a date", "not a date", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (comparer{}).equal("dueDate", schema.FieldTypeDate, tc.a, tc.b); got != tc.expected {
+				t.Errorf("Expected equal(%#v, %#v) to be %v, got %v", tc.a, tc.b, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMakeImmutable_DateFields(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_tasks",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "dueDate", Type: schema.FieldTypeDate},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("title", "date_test")
+	initialRecord.Set("dueDate", "2024-01-01 00:00:00.000Z")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		dueDate             any
+		expectErrorContains string
+	}{
+		{"same instant, other format", "2024-01-01 00:00:00Z", ""},
+		{"same instant, other zone", "2024-01-01T01:00:00+01:00", ""},
+		{"other instant", "2024-01-02 00:00:00.000Z", "Attempt to modify immutable field 'dueDate'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := MakeImmutable("dueDate")(newUpdateEvent(app, initialRecord, map[string]interface{}{"dueDate": tc.dueDate}))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestComparerEqual_EmptyAsEqual(t *testing.T) {
+	tests := []struct {
+		name         string
+		fieldType    string
+		emptyAsEqual bool
+		a, b         any
+		expected     bool
+	}{
+		{"nil and empty text (strict)", schema.FieldTypeText, false, nil, "", false},
+		{"nil and empty text", schema.FieldTypeText, true, nil, "", true},
+		{"empty text and nil", schema.FieldTypeText, true, "", nil, true},
+		{"nil and empty email", schema.FieldTypeEmail, true, nil, "", true},
+		{"nil and empty select", schema.FieldTypeSelect, true, nil, "", true},
+		{"untyped field", "", true, "", nil, true},
+		{"nil and non-empty text", schema.FieldTypeText, true, nil, "a", false},
+		{"empty and whitespace text", schema.FieldTypeText, true, "", " ", false},
+		{"nil and empty bool", schema.FieldTypeBool, true, nil, "", false},
+		// number fields treat nil as 0 with and without the option
+		{"nil and zero number (strict)", schema.FieldTypeNumber, false, nil, 0, true},
+		{"nil and zero number", schema.FieldTypeNumber, true, nil, 0, true},
+		{"empty and zero number", schema.FieldTypeNumber, true, "", 0.0, true},
+		{"nil and non-zero number", schema.FieldTypeNumber, true, nil, 1, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (comparer{emptyAsEqual: tc.emptyAsEqual}).equal("note", tc.fieldType, tc.a, tc.b); got != tc.expected {
+				t.Errorf("Expected equal(%#v, %#v) to be %v, got %v", tc.a, tc.b, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestComparerEqualValues(t *testing.T) {
+	coll := &models.Collection{
+		Name: "test_prepared",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "published", Type: schema.FieldTypeBool},
+			&schema.SchemaField{Name: "price", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "category", Type: schema.FieldTypeSelect, Options: &schema.SelectOptions{MaxSelect: 1, Values: []string{"a", "b"}}},
+			&schema.SchemaField{Name: "timeout", Type: schema.FieldTypeText},
+		),
+	}
+	record := models.NewRecord(coll)
+
+	tests := []struct {
+		name     string
+		cmp      comparer
+		field    string
+		a, b     any
+		expected bool
+	}{
+		{"bool from form value", comparer{}, "published", true, "true", true},
+		{"changed bool", comparer{}, "published", true, "false", false},
+		{"number from string", comparer{}, "price", 100, "100", true},
+		{"single select from list", comparer{}, "category", "a", []string{"a"}, true},
+		{"changed single select", comparer{}, "category", "a", []string{"b"}, false},
+		{"text from number", comparer{}, "title", "42", 42, true},
+		{"nil and empty text stay strict", comparer{}, "title", nil, "", false},
+		{"nil and empty text with option", comparer{emptyAsEqual: true}, "title", nil, "", true},
+		{"normalizer gets the raw value", comparer{normalizers: map[string]Normalizer{"timeout": DurationNormalizer(time.Second)}}, "timeout", "60m", "3600", true},
+		{"system field compared as is", comparer{}, models.SystemFieldId, "abc", "abc", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.cmp.equalValues(record, tc.field, tc.a, tc.b); got != tc.expected {
+				t.Errorf("Expected equalValues(%#v, %#v) to be %v, got %v", tc.a, tc.b, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestComparerEqual_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		strict   bool
+		a, b     any
+		expected bool
+	}{
+		{"reordered keys", false, types.JsonRaw(`{"a":1,"b":2}`), types.JsonRaw(`{"b":2,"a":1}`), true},
+		{"reordered nested keys", false, types.JsonRaw(`{"theme":{"dark":true,"accent":"red"},"size":3}`), types.JsonRaw(`{"size":3,"theme":{"accent":"red","dark":true}}`), true},
+		{"whitespace", false, types.JsonRaw(`{"a": 1}`), types.JsonRaw(`{"a":1}`), true},
+		{"raw and decoded value", false, types.JsonRaw(`{"b":[1,2],"a":"x"}`), map[string]any{"a": "x", "b": []int{1, 2}}, true},
+		{"changed nested value", false, types.JsonRaw(`{"theme":{"dark":true}}`), types.JsonRaw(`{"theme":{"dark":false}}`), false},
+		{"added key", false, types.JsonRaw(`{"a":1}`), types.JsonRaw(`{"a":1,"b":2}`), false},
+		{"reordered array", false, types.JsonRaw(`[1,2]`), types.JsonRaw(`[2,1]`), false},
+		{"unset and null", false, types.JsonRaw(``), types.JsonRaw(`null`), true},
+		{"strict reordered keys", true, types.JsonRaw(`{"a":1,"b":2}`), types.JsonRaw(`{"b":2,"a":1}`), false},
+		{"strict same bytes", true, types.JsonRaw(`{"a":1}`), types.JsonRaw(`{"a":1}`), true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (comparer{strictJSON: tc.strict}).equal("config", schema.FieldTypeJson, tc.a, tc.b); got != tc.expected {
+				t.Errorf("Expected equal(%#v, %#v) to be %v, got %v", tc.a, tc.b, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMakeImmutable_RelationFields(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	var tagIds []string
+	for _, name := range []string{"tag_1", "tag_2", "tag_3"} {
+		tag := models.NewRecord(coll)
+		tag.Set("name", name)
+		if err := app.Dao().SaveRecord(tag); err != nil {
+			t.Fatalf("Failed to save related record: %v", err)
+		}
+		tagIds = append(tagIds, tag.Id)
+	}
+
+	posts := &models.Collection{
+		Name: "test_posts",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "author", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: coll.Id, MaxSelect: types.Pointer(1)}},
+			&schema.SchemaField{Name: "tags", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: coll.Id}},
+		),
+	}
+	if err := app.Dao().SaveCollection(posts); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(posts)
+	initialRecord.Set("title", "relation_test")
+	initialRecord.Set("author", tagIds[0])
+	initialRecord.Set("tags", []string{tagIds[0], tagIds[1]})
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"reordered multiple relation", map[string]interface{}{"tags": []string{tagIds[1], tagIds[0]}}, ""},
+		{"same single relation", map[string]interface{}{"author": tagIds[0]}, ""},
+		{"added relation", map[string]interface{}{"tags": []string{tagIds[1], tagIds[0], tagIds[2]}}, "Attempt to modify immutable field 'tags'"},
+		{"replaced single relation", map[string]interface{}{"author": tagIds[1]}, "Attempt to modify immutable field 'author'"},
+	}
+
+	hookFunc := MakeImmutable("author", "tags")
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestComparerEqual_Files(t *testing.T) {
+	upload := func(name string) *filesystem.File {
+		return &filesystem.File{Name: name, OriginalName: "upload.png", Size: 42}
+	}
+
+	tests := []struct {
+		name     string
+		a, b     any
+		expected bool
+	}{
+		{"same filename", "avatar_a1.png", "avatar_a1.png", true},
+		{"same filename as uploaded file", "avatar_a1.png", upload("avatar_a1.png"), true},
+		{"new upload", "avatar_a1.png", upload("avatar_b2.png"), false},
+		{"removed file", "avatar_a1.png", "", false},
+		{"same files in another order", []string{"doc_1.pdf", "doc_2.pdf"}, []any{"doc_2.pdf", "doc_1.pdf"}, true},
+		{"same files as uploaded files", []string{"doc_1.pdf", "doc_2.pdf"}, []*filesystem.File{upload("doc_2.pdf"), upload("doc_1.pdf")}, true},
+		{"added upload", []string{"doc_1.pdf"}, []any{"doc_1.pdf", upload("doc_3.pdf")}, false},
+		{"no files", []string{}, nil, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (comparer{}).equal("avatar", schema.FieldTypeFile, tc.a, tc.b); got != tc.expected {
+				t.Errorf("Expected equal(%#v, %#v) to be %v, got %v", tc.a, tc.b, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMakeImmutable_FileFields(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	profiles := &models.Collection{
+		Name: "test_profiles",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "avatar", Type: schema.FieldTypeFile, Options: &schema.FileOptions{MaxSelect: 1, MaxSize: 1 << 20}},
+			&schema.SchemaField{Name: "documents", Type: schema.FieldTypeFile, Options: &schema.FileOptions{MaxSelect: 5, MaxSize: 1 << 20}},
+		),
+	}
+	if err := app.Dao().SaveCollection(profiles); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(profiles)
+	initialRecord.Set("avatar", "avatar_a1.png")
+	initialRecord.Set("documents", []string{"doc_1.pdf", "doc_2.pdf"})
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"same file", map[string]interface{}{"avatar": "avatar_a1.png"}, ""},
+		{"reordered files", map[string]interface{}{"documents": []string{"doc_2.pdf", "doc_1.pdf"}}, ""},
+		{"new upload", map[string]interface{}{"avatar": "avatar_b2.png"}, "Attempt to modify immutable field 'avatar'"},
+		{"added file", map[string]interface{}{"documents": []string{"doc_1.pdf", "doc_2.pdf", "doc_3.pdf"}}, "Attempt to modify immutable field 'documents'"},
+	}
+
+	hookFunc := MakeImmutable("avatar", "documents")
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RuleCompositeKey is the kind of the rule created by MakeCompositeKeyImmutable.
+const RuleCompositeKey RuleKind = "composite_key"
+
+// MakeCompositeKeyImmutable returns a hook that treats the given fields as a composite
+// natural key, e.g. MakeCompositeKeyImmutable([]string{"region", "code"}).
+//
+// While any member of the key is still empty in the original record, all members
+// remain editable so that a partially populated key can be completed. Once all members
+// are non-empty, none of them may change, neither individually nor together.
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeCompositeKeyImmutable(keyFields []string, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeCompositeKeyImmutable", args)
+	if cfg.setupErr == nil {
+		switch {
+		case len(keyFields) < 2:
+			cfg.setupErr = errors.New("pbimmutable.MakeCompositeKeyImmutable: a composite key needs at least two fields")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeCompositeKeyImmutable: only a callback can be passed as additional argument")
+		}
+	}
+
+	key := strings.Join(keyFields, ", ")
+
+	cfg.freezeOn(func(original *models.Record) bool {
+		for _, name := range keyFields {
+			if isEmptyValue(original.Get(name)) {
+				return false
+			}
+		}
+		return true
+	}, nil)
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		changes := cfg.cmp.evaluate(original, e.Record, keyFields)
+		for i := range changes {
+			changes[i].RuleKind = RuleCompositeKey
+			if changes[i].Violated {
+				changes[i].Message = fmt.Sprintf("Field '%s' is part of the composite key (%s) and can't be changed.", changes[i].Field, key)
+				changes[i].Details = map[string]any{
+					"compositeKey": keyFields,
+				}
+			}
+		}
+		return changes, nil
+	}
+
+	return newHook(cfg)
+}
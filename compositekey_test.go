@@ -0,0 +1,93 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeCompositeKeyImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	completeRecord := models.NewRecord(coll)
+	completeRecord.Set("name", "composite_test")
+	completeRecord.Set("status", "eu")
+	completeRecord.Set("description", "A-1")
+	if err := app.Dao().SaveRecord(completeRecord); err != nil {
+		t.Fatalf("Failed to save complete record: %v", err)
+	}
+
+	partialRecord := models.NewRecord(coll)
+	partialRecord.Set("name", "composite_partial_test")
+	partialRecord.Set("status", "eu")
+	if err := app.Dao().SaveRecord(partialRecord); err != nil {
+		t.Fatalf("Failed to save partial record: %v", err)
+	}
+
+	key := []string{"status", "description"}
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"complete key unchanged", completeRecord, map[string]interface{}{"name": "renamed"}, ""},
+		{"complete key member changed", completeRecord, map[string]interface{}{"description": "A-2"}, "Field 'description' is part of the composite key (status, description)"},
+		{"complete key changed together", completeRecord, map[string]interface{}{"status": "us", "description": "B-1"}, "Attempt to modify 2 immutable fields: status, description."},
+		{"complete key member cleared", completeRecord, map[string]interface{}{"status": ""}, "Field 'status' is part of the composite key"},
+		{"partial key completed", partialRecord, map[string]interface{}{"description": "A-1"}, ""},
+		{"partial key member changed", partialRecord, map[string]interface{}{"status": "us", "description": "A-1"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeCompositeKeyImmutable(key)
+
+			err := hookFunc(newUpdateEvent(app, tc.original, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tc.expectErrorContains)
+				} else if !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("error data", func(t *testing.T) {
+		hookFunc := MakeCompositeKeyImmutable(key)
+
+		err := hookFunc(newUpdateEvent(app, completeRecord, map[string]interface{}{"description": "A-2"}))
+		var apiErr *apis.ApiError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expected *apis.ApiError, got %T", err)
+		}
+		data, _ := apiErr.RawData().(validation.Errors)
+		if fieldErr, _ := data["description"].(validation.Error); fieldErr == nil || fieldErr.Code() != ViolationCode {
+			t.Errorf("Unexpected error data: %v", data)
+		}
+
+		var immutableErr *ImmutableFieldError
+		if !errors.As(err, &immutableErr) || immutableErr.Violations[0].RuleKind != RuleCompositeKey {
+			t.Errorf("Expected a composite key violation, got: %v", err)
+		}
+	})
+
+	t.Run("single field key", func(t *testing.T) {
+		hookFunc := MakeCompositeKeyImmutable([]string{"status"})
+
+		err := hookFunc(newUpdateEvent(app, completeRecord, nil))
+		if err == nil || !strings.Contains(err.Error(), "at least two fields") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
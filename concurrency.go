@@ -0,0 +1,104 @@
+package pbimmutable
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// WithOptimisticConcurrency makes sure that the record wasn't changed by a concurrent
+// update since the hook compared against it. Without it, two updates of the same record
+// may both be checked against the same stale original and pass, so that the second
+// one overwrites a frozen field set by the first one.
+//
+// The "updated" timestamp of the original is checked again with a conditional UPDATE in
+// the transaction that saves the record, right before e.Next() runs. This is one extra
+// query per accepted update, and it locks out concurrent writers until the save is
+// committed. If the timestamp moved (or the record was deleted), the update is rejected
+// with a *ConcurrentUpdateError (a 409 Conflict response) and the client can retry with
+// the current record.
+func WithOptimisticConcurrency() Option {
+	return func(cfg *hookConfig) {
+		cfg.optimisticConcurrency = true
+	}
+}
+
+// ConcurrentUpdateError is the error returned by hooks with WithOptimisticConcurrency when
+// the record was changed by another update after it was checked.
+//
+// It wraps the *apis.ApiError with the 409 response sent to the client, so errors.As with
+// an *apis.ApiError target still works.
+type ConcurrentUpdateError struct {
+	Collection string         // The name of the record collection.
+	RecordId   string         // The id of the updated record.
+	Expected   types.DateTime // The "updated" timestamp the checks compared against.
+	Actual     types.DateTime // The current "updated" timestamp (zero if the record was deleted).
+
+	apiErr *apis.ApiError
+}
+
+// Error returns the message of the wrapped API error.
+func (e *ConcurrentUpdateError) Error() string {
+	return e.apiErr.Error()
+}
+
+// Unwrap returns the wrapped API error.
+func (e *ConcurrentUpdateError) Unwrap() error {
+	return e.apiErr
+}
+
+// withVersionCheck returns inTx preceded by the check that the "updated" timestamp
+// of the original record is still the stored one.
+//
+// The check is a conditional UPDATE of the record row in the transaction of e.Next(),
+// which also saves the record (see commit). It takes the write lock, so no other update
+// can be committed between the check and the save.
+func withVersionCheck(e *core.RecordEvent, original *models.Record, inTx func(txDao *daos.Dao) error) func(txDao *daos.Dao) error {
+	return func(txDao *daos.Dao) error {
+		result, err := txDao.DB().Update(
+			original.Collection().Name,
+			dbx.Params{models.SystemFieldUpdated: original.Updated},
+			dbx.HashExp{models.SystemFieldId: original.Id, models.SystemFieldUpdated: original.Updated},
+		).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to lock record %s of collection %s for the concurrency check: %w", original.Id, original.Collection().Name, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to lock record %s of collection %s for the concurrency check: %w", original.Id, original.Collection().Name, err)
+		}
+
+		if affected == 0 {
+			// the record moved on (or was deleted), read its current timestamp for the error
+			var actual types.DateTime
+			current, err := txDao.FindRecordById(original.Collection().Id, original.Id)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to read record %s of collection %s for the concurrency check: %w", original.Id, original.Collection().Name, err)
+			}
+			if current != nil {
+				actual = current.Updated
+			}
+
+			return &ConcurrentUpdateError{
+				Collection: original.Collection().Name,
+				RecordId:   original.Id,
+				Expected:   original.Updated,
+				Actual:     actual,
+				apiErr:     apis.NewApiError(http.StatusConflict, fmt.Sprintf("Record %s of collection %s was changed by another update, please retry.", e.Record.Id, e.Record.Collection().Name), nil),
+			}
+		}
+
+		if inTx != nil {
+			return inTx(txDao)
+		}
+		return nil
+	}
+}
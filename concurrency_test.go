@@ -0,0 +1,133 @@
+package pbimmutable
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/USERNAME/pbimmutable/pbimmutabletest"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithOptimisticConcurrency(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "concurrency_test")
+	initialRecord.Set("status", "draft")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	// load loads the record, so that it tracks its current state as the original
+	load := func() *models.Record {
+		record, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to load record: %v", err)
+		}
+		return record
+	}
+
+	// concurrentUpdate saves another update of the record, so that its "updated" timestamp moves
+	concurrentUpdate := func(status string) {
+		time.Sleep(5 * time.Millisecond)
+		other := load()
+		other.Set("status", status)
+		if err := app.Dao().SaveRecord(other); err != nil {
+			t.Fatalf("Failed to save concurrent update: %v", err)
+		}
+	}
+
+	t.Run("unchanged record", func(t *testing.T) {
+		record := load()
+		record.Set("status", "review")
+
+		err := MakeImmutable("name", WithOptimisticConcurrency())(&core.RecordEvent{App: app, Record: record})
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("record changed after it was loaded", func(t *testing.T) {
+		stale := load()
+		concurrentUpdate("published")
+		stale.Set("status", "archived")
+
+		err := MakeImmutable("name", WithOptimisticConcurrency())(&core.RecordEvent{App: app, Record: stale})
+
+		var conflictErr *ConcurrentUpdateError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("Expected a *ConcurrentUpdateError, got %T (%v)", err, err)
+		}
+		if conflictErr.RecordId != initialRecord.Id || conflictErr.Expected.String() != stale.OriginalCopy().Updated.String() || conflictErr.Actual.String() == conflictErr.Expected.String() {
+			t.Errorf("Unexpected conflict details: %+v", conflictErr)
+		}
+
+		var apiErr *apis.ApiError
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusConflict {
+			t.Errorf("Expected a 409 API error, got: %v", err)
+		}
+
+		if current := load(); current.GetString("status") != "published" {
+			t.Errorf("Expected the concurrent update to be kept, got status %q", current.GetString("status"))
+		}
+	})
+
+	t.Run("interleaved updates", func(t *testing.T) {
+		first, second := load(), load()
+		first.Set("status", "first")
+		second.Set("status", "second")
+
+		// the first update is held in its save until the second one has been started,
+		// so that both passed their checks against the same original
+		saving := make(chan struct{})
+		release := make(chan struct{})
+		save := func(e *core.RecordEvent) error {
+			return e.App.Dao().SaveRecord(e.Record)
+		}
+
+		firstErr := make(chan error, 1)
+		go func() {
+			event := pbimmutabletest.NewEvent(&core.RecordEvent{App: app, Record: first}, func(e *core.RecordEvent) error {
+				close(saving)
+				<-release
+				return save(e)
+			})
+			firstErr <- event.Run(MakeImmutable("name", WithOptimisticConcurrency()))
+		}()
+		<-saving
+
+		secondErr := make(chan error, 1)
+		go func() {
+			event := pbimmutabletest.NewEvent(&core.RecordEvent{App: app, Record: second}, save)
+			secondErr <- event.Run(MakeImmutable("name", WithOptimisticConcurrency()))
+		}()
+		time.Sleep(50 * time.Millisecond) // let the second update reach its concurrency check
+		close(release)
+
+		if err := <-firstErr; err != nil {
+			t.Fatalf("Expected the first update to pass, got: %v", err)
+		}
+		var conflictErr *ConcurrentUpdateError
+		if err := <-secondErr; !errors.As(err, &conflictErr) {
+			t.Fatalf("Expected a *ConcurrentUpdateError for the second update, got %T (%v)", err, err)
+		}
+		if current := load(); current.GetString("status") != "first" {
+			t.Errorf("Expected the first update to be kept, got status %q", current.GetString("status"))
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		stale := load()
+		concurrentUpdate("draft")
+		stale.Set("status", "archived")
+
+		if err := MakeImmutable("name")(&core.RecordEvent{App: app, Record: stale}); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package pbimmutable
+
+import (
+	"errors"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// MakeImmutableIf returns a hook that freezes the provided fields (or all user-defined
+// fields when none are given) only while predicate returns true for the original record,
+// e.g. to lock the price once a product is published:
+//
+//	MakeImmutableIf(func(original *models.Record) bool {
+//		return original.GetString("status") == "published"
+//	}, "price")
+//
+// The predicate always receives the persisted record state, never the pending one,
+// so an update can't unlock the fields by changing the state in the same request.
+func MakeImmutableIf(predicate func(original *models.Record) bool, fields ...string) func(e *core.RecordEvent) error {
+	args := make([]interface{}, len(fields))
+	for i, field := range fields {
+		args[i] = field
+	}
+
+	cfg := parseArgs("MakeImmutableIf", args)
+	if predicate == nil {
+		cfg.setupErr = errors.New("pbimmutable.MakeImmutableIf: a predicate must be provided")
+	}
+	cfg.freezeOn(predicate, nil)
+
+	return newHook(cfg)
+}
+
+// FreezeWhen freezes the fields of the hook only while the field of the original record
+// equals value, e.g. to lock the amount once the record is locked:
+//
+//	MakeImmutable("amount", FreezeWhen("locked", true))
+//
+// It is the declarative form of MakeImmutableIf for plain equality conditions. The values
+// are compared like the frozen fields (e.g. "true" equals true for a bool field).
+// Multiple FreezeWhen options are combined with AND. An unknown field is reported as
+// a setup error, like the frozen field names.
+//
+// Like with MakeImmutableIf, the condition is evaluated against the persisted record. If
+// the condition field is frozen as well, it can't be changed while the condition holds,
+// so the lock is permanent; otherwise an update may change the condition field first and
+// the fields are editable from the next update on.
+func FreezeWhen(field string, value any) Option {
+	return func(cfg *hookConfig) {
+		cfg.conditionFields = append(cfg.conditionFields, field)
+
+		previous, describe := cfg.condition, cfg.describeFreeze
+		cfg.freezeOn(func(original *models.Record) bool {
+			if previous != nil && !previous(original) {
+				return false
+			}
+
+			return cfg.cmp.equalValues(original, field, original.Get(field), value)
+		}, func(record *models.Record) FreezeInfo {
+			if describe != nil {
+				return describe(record) // reported by the first FreezeWhen
+			}
+			return FreezeInfo{Reason: FreezeReasonState, Data: map[string]any{"field": field, "state": record.Get(field)}}
+		})
+	}
+}
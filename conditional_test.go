@@ -0,0 +1,150 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeImmutableIf(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newRecord := func(status string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("name", "conditional_test")
+		record.Set("value", 100)
+		record.Set("status", status)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	draft := newRecord("draft")
+	published := newRecord("published")
+
+	isPublished := func(original *models.Record) bool {
+		return original.GetString("status") == "published"
+	}
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"draft is editable", draft, map[string]interface{}{"value": 200}, ""},
+		{"publishing with a new value is allowed", draft, map[string]interface{}{"value": 200, "status": "published"}, ""},
+		{"published is frozen", published, map[string]interface{}{"value": 200}, "Attempt to modify immutable field 'value'"},
+		{"unpublishing doesn't unlock", published, map[string]interface{}{"value": 200, "status": "draft"}, "Attempt to modify immutable field 'value'"},
+		{"published unrelated field", published, map[string]interface{}{"description": "new"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableIf(isPublished, "value")
+
+			err := hookFunc(newUpdateEvent(app, tc.original, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("predicate receives the original record", func(t *testing.T) {
+		var received *models.Record
+		hookFunc := MakeImmutableIf(func(original *models.Record) bool {
+			received = original
+			return false
+		}, "value")
+
+		if err := hookFunc(newUpdateEvent(app, draft, map[string]interface{}{"status": "published"})); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if received == nil || received.GetString("status") != "draft" {
+			t.Errorf("Expected the persisted record state, got: %v", received)
+		}
+	})
+
+	t.Run("nil predicate", func(t *testing.T) {
+		hookFunc := MakeImmutableIf(nil, "value")
+
+		err := hookFunc(newUpdateEvent(app, draft, nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeImmutableIf setup error: pbimmutable.MakeImmutableIf: a predicate must be provided") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
+
+func TestFreezeWhen(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_payments",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "amount", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "locked", Type: schema.FieldTypeBool},
+			&schema.SchemaField{Name: "currency", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newRecord := func(locked bool, currency string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("amount", 100)
+		record.Set("locked", locked)
+		record.Set("currency", currency)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	unlocked := newRecord(false, "EUR")
+	locked := newRecord(true, "EUR")
+	lockedUSD := newRecord(true, "USD")
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		args                []interface{}
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"condition not met", unlocked, []interface{}{"amount", FreezeWhen("locked", true)}, map[string]interface{}{"amount": 200}, ""},
+		{"condition met", locked, []interface{}{"amount", FreezeWhen("locked", true)}, map[string]interface{}{"amount": 200}, "Attempt to modify immutable field 'amount'"},
+		{"unlocking in the same update", locked, []interface{}{"amount", FreezeWhen("locked", true)}, map[string]interface{}{"amount": 200, "locked": false}, "Attempt to modify immutable field 'amount'"},
+		{"unlocking alone", locked, []interface{}{"amount", FreezeWhen("locked", true)}, map[string]interface{}{"locked": false}, ""},
+		{"frozen condition field", locked, []interface{}{"amount", "locked", FreezeWhen("locked", true)}, map[string]interface{}{"locked": false}, "Attempt to modify immutable field 'locked'"},
+		{"all conditions met", locked, []interface{}{"amount", FreezeWhen("locked", true), FreezeWhen("currency", "EUR")}, map[string]interface{}{"amount": 200}, "Attempt to modify immutable field 'amount'"},
+		{"one condition not met", lockedUSD, []interface{}{"amount", FreezeWhen("locked", true), FreezeWhen("currency", "EUR")}, map[string]interface{}{"amount": 200}, ""},
+		{"number condition", locked, []interface{}{"currency", FreezeWhen("amount", 100.0)}, map[string]interface{}{"currency": "USD"}, "Attempt to modify immutable field 'currency'"},
+		{"unknown condition field", locked, []interface{}{"amount", FreezeWhen("lockd", true)}, map[string]interface{}{"amount": 200}, `unknown fields "lockd"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := MakeImmutable(tc.args...)(newUpdateEvent(app, tc.original, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// ImmutableConfig is the typed configuration of MakeImmutableWith.
+type ImmutableConfig struct {
+	// Fields lists the frozen fields.
+	Fields []string
+
+	// FreezeAll freezes all user-defined fields. It can't be combined with Fields.
+	FreezeAll bool
+
+	// CollectionFields overrides Fields (or FreezeAll) for the listed collection names, so
+	// that a hook shared by several collections freezes different fields in each of them.
+	// An empty list freezes nothing in that collection. Fields or FreezeAll remain the
+	// default rule for the collections that aren't listed.
+	CollectionFields map[string][]string
+
+	// Callback is the optional callback executed once the checks passed (see MakeImmutable).
+	Callback func(e *core.RecordEvent) error
+
+	// OriginalCallback is like Callback, but also receives the original record the
+	// checks compared against, e.g. to notify about the changed values. Only one of
+	// Callback and OriginalCallback can be set.
+	OriginalCallback func(e *core.RecordEvent, original *models.Record) error
+
+	// RunAfterCommit runs the Callback after e.Next() committed the update.
+	//
+	// By default the Callback runs right before e.Next(): a Callback error aborts
+	// the update and rolls back the surrounding transaction, but the Callback can't
+	// see the saved record. With RunAfterCommit the Callback sees the committed
+	// record, but a Callback error is reported to the client although the update
+	// was already saved.
+	RunAfterCommit bool
+
+	// AllowSuperusers lets superuser (admin) requests change the frozen fields
+	// (see WithAllowSuperusers).
+	AllowSuperusers bool
+
+	// AllowedActors lists the auth record ids whose requests may change the frozen
+	// fields (see WithAllowedActors). An empty list allows nobody.
+	AllowedActors []string
+
+	// SkipPaths lists the request path prefixes whose requests may change the frozen
+	// fields (see WithSkipPaths). Non-HTTP events are always checked.
+	SkipPaths []string
+
+	// CaseInsensitive matches Fields against the collection schema case-insensitively
+	// (see WithCaseInsensitiveFields). By default field names are case-sensitive.
+	CaseInsensitive bool
+
+	// TreatEmptyAsEqual treats nil and "" as equal values of text-like fields
+	// (see WithEmptyAsEqual). By default both are compared strictly.
+	TreatEmptyAsEqual bool
+
+	// SystemFields, when set, reports the additional fields that are treated like
+	// the PocketBase system fields and never checked (see WithSystemFields).
+	SystemFields func(name string) bool
+
+	// MutableSystemFields lists the PocketBase system fields that may change even when
+	// they are listed in Fields, e.g. "created" (see WithMutableSystemFields).
+	MutableSystemFields []string
+
+	// ImmutableSystemFields lists the PocketBase system fields that are always frozen,
+	// including "updated" (see WithImmutableSystemFields).
+	ImmutableSystemFields []string
+
+	// Messages replaces the default violation message of the listed fields.
+	// A "{field}" placeholder is replaced with the field name (see WithMessages).
+	Messages map[string]string
+
+	// MessageResolver, when set, produces the message of each violated field, e.g. a
+	// localized one. An empty result keeps the default (see WithMessageResolver).
+	MessageResolver func(e *core.RecordEvent, field string) string
+
+	// OnViolation, when set, is called with the violated fields of every rejected
+	// update (see WithOnViolation). Its error is logged, not returned.
+	OnViolation func(e *core.RecordEvent, fields []string) error
+
+	// ForbiddenStatus rejects violations with a 403 instead of a 400 error
+	// (see WithForbiddenStatus).
+	ForbiddenStatus bool
+
+	// GracePeriods keeps the listed frozen fields editable for the given duration after
+	// the record was created. The other fields use DefaultGracePeriod, where 0 freezes
+	// them immediately (see WithGracePeriods).
+	GracePeriods       map[string]time.Duration
+	DefaultGracePeriod time.Duration
+
+	// AgeSourceField is the date field the grace periods are measured from instead of
+	// "created" (see WithAgeSourceField).
+	AgeSourceField string
+
+	// OverrideHeader names a request header that allows changes to all frozen fields when
+	// its value is true, e.g. "X-Allow-Immutable-Override" (see WithOverrideHeader).
+	// Empty (the default) disables the override. The header must be validated upstream.
+	OverrideHeader string
+
+	// SubmittedFieldsOnly only reports violations of the fields submitted in the request
+	// body of HTTP events (see WithSubmittedFieldsOnly).
+	SubmittedFieldsOnly bool
+
+	// OptimisticConcurrency rejects updates of records that were changed by a concurrent
+	// update after they were checked (see WithOptimisticConcurrency).
+	OptimisticConcurrency bool
+
+	// RejectUnknownFields rejects updates carrying fields the collection schema doesn't
+	// have (see WithRejectUnknownFields).
+	RejectUnknownFields bool
+
+	// LogViolations writes a log entry for every rejected update (see WithViolationLog).
+	LogViolations bool
+
+	// Metrics, when set, receives the outcome of every hook run (see WithMetrics).
+	Metrics Metrics
+
+	// Options customize the hook, e.g. WithRequireMFA or WithTracer.
+	Options []Option
+}
+
+// MakeImmutableWith returns the same hook as MakeImmutable, configured with a typed
+// ImmutableConfig instead of untyped variadic arguments, e.g.
+//
+//	MakeImmutableWith(ImmutableConfig{Fields: []string{"sku"}, Callback: myCallback})
+//
+// Unlike MakeImmutable(), an empty config doesn't freeze anything implicitly:
+// either Fields or FreezeAll must be set.
+func MakeImmutableWith(config ImmutableConfig) func(e *core.RecordEvent) error {
+	return newHook(config.build("MakeImmutableWith"))
+}
+
+// build converts the config into the hook config of the named constructor.
+func (c ImmutableConfig) build(name string) hookConfig {
+	cfg := hookConfig{
+		name:             name,
+		fields:           c.Fields,
+		collectionFields: c.CollectionFields,
+		runAfterCommit:   c.RunAfterCommit,
+		validated:        &sync.Map{},
+		cacheId:          hookIds.Add(1),
+	}
+
+	switch {
+	case c.Callback != nil && c.OriginalCallback != nil:
+		cfg.setupErr = fmt.Errorf("pbimmutable.%s: Callback can't be combined with OriginalCallback", name)
+	case c.Callback != nil:
+		callback := c.Callback
+		cfg.callback = func(e *core.RecordEvent, _ *models.Record) error {
+			return callback(e)
+		}
+	case c.OriginalCallback != nil:
+		cfg.callback = c.OriginalCallback
+		cfg.callbackTakesOriginal = true
+	}
+
+	switch {
+	case c.FreezeAll && len(c.Fields) > 0:
+		cfg.setupErr = fmt.Errorf("pbimmutable.%s: FreezeAll can't be combined with Fields", name)
+	case !c.FreezeAll && len(c.Fields) == 0:
+		cfg.setupErr = fmt.Errorf("pbimmutable.%s: no fields provided (set FreezeAll to freeze all user-defined fields)", name)
+	}
+
+	options := c.Options
+	if c.AllowSuperusers {
+		options = append([]Option{WithAllowSuperusers()}, options...)
+	}
+	if len(c.AllowedActors) > 0 {
+		options = append([]Option{WithAllowedActors(c.AllowedActors...)}, options...)
+	}
+	if len(c.SkipPaths) > 0 {
+		options = append([]Option{WithSkipPaths(c.SkipPaths...)}, options...)
+	}
+	if c.CaseInsensitive {
+		options = append([]Option{WithCaseInsensitiveFields()}, options...)
+	}
+	if c.TreatEmptyAsEqual {
+		options = append([]Option{WithEmptyAsEqual()}, options...)
+	}
+	if c.SystemFields != nil {
+		options = append([]Option{WithSystemFields(c.SystemFields)}, options...)
+	}
+	if len(c.MutableSystemFields) > 0 {
+		options = append([]Option{WithMutableSystemFields(c.MutableSystemFields...)}, options...)
+	}
+	if len(c.ImmutableSystemFields) > 0 {
+		options = append([]Option{WithImmutableSystemFields(c.ImmutableSystemFields...)}, options...)
+	}
+	if len(c.Messages) > 0 {
+		options = append([]Option{WithMessages(c.Messages)}, options...)
+	}
+	if c.MessageResolver != nil {
+		options = append([]Option{WithMessageResolver(c.MessageResolver)}, options...)
+	}
+	if c.OnViolation != nil {
+		options = append([]Option{WithOnViolation(c.OnViolation)}, options...)
+	}
+	if c.AgeSourceField != "" {
+		options = append([]Option{WithAgeSourceField(c.AgeSourceField)}, options...)
+	}
+	if len(c.GracePeriods) > 0 || c.DefaultGracePeriod != 0 {
+		options = append([]Option{WithGracePeriods(c.GracePeriods, c.DefaultGracePeriod)}, options...)
+	}
+	if c.OverrideHeader != "" {
+		options = append([]Option{WithOverrideHeader(c.OverrideHeader)}, options...)
+	}
+	if c.SubmittedFieldsOnly {
+		options = append([]Option{WithSubmittedFieldsOnly()}, options...)
+	}
+	if c.OptimisticConcurrency {
+		options = append([]Option{WithOptimisticConcurrency()}, options...)
+	}
+	if c.RejectUnknownFields {
+		options = append([]Option{WithRejectUnknownFields()}, options...)
+	}
+	if c.ForbiddenStatus {
+		options = append([]Option{WithForbiddenStatus()}, options...)
+	}
+	if c.Metrics != nil {
+		options = append([]Option{WithMetrics(c.Metrics)}, options...)
+	}
+	if c.LogViolations {
+		options = append([]Option{WithViolationLog()}, options...)
+	}
+
+	// options are applied last so that they can see all field names
+	for _, option := range options {
+		if option == nil {
+			cfg.setupErr = fmt.Errorf("pbimmutable.%s: nil option", name)
+			continue
+		}
+		option(&cfg)
+	}
+
+	if cfg.setupErr == nil && cfg.ageSourceField != "" && !cfg.agesRecords {
+		cfg.setupErr = fmt.Errorf("pbimmutable.%s: the age source field %q is only used with grace periods or a time window", name, cfg.ageSourceField)
+	}
+
+	return cfg
+}
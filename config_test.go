@@ -0,0 +1,63 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeImmutableWith(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "config_test")
+	initialRecord.Set("value", 100)
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	failingCallback := func(e *core.RecordEvent) error {
+		return errors.New("callback failed")
+	}
+
+	tests := []struct {
+		name                string
+		config              ImmutableConfig
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"listed field unchanged", ImmutableConfig{Fields: []string{"name"}}, map[string]interface{}{"status": "inactive"}, ""},
+		{"listed field changed", ImmutableConfig{Fields: []string{"name"}}, map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"freeze all", ImmutableConfig{FreezeAll: true}, map[string]interface{}{"status": "inactive"}, "Attempt to modify immutable field 'status'"},
+		{"callback", ImmutableConfig{Fields: []string{"name"}, Callback: failingCallback}, map[string]interface{}{"status": "inactive"}, "user callback failed, record changes were not saved: callback failed"},
+		{"callback after commit", ImmutableConfig{Fields: []string{"name"}, Callback: failingCallback, RunAfterCommit: true}, map[string]interface{}{"status": "inactive"}, "user callback failed AFTER record commit: callback failed"},
+		{"options", ImmutableConfig{Fields: []string{"name"}, Options: []Option{WithNormalizer("name", func(v any) any { return strings.ToLower(v.(string)) })}}, map[string]interface{}{"name": "CONFIG_TEST"}, ""},
+		{"empty config", ImmutableConfig{}, map[string]interface{}{}, "MakeImmutableWith setup error: pbimmutable.MakeImmutableWith: no fields provided"},
+		{"freeze all with fields", ImmutableConfig{Fields: []string{"name"}, FreezeAll: true}, map[string]interface{}{}, "FreezeAll can't be combined with Fields"},
+		{"nil option", ImmutableConfig{Fields: []string{"name"}, Options: []Option{nil}}, map[string]interface{}{}, "nil option"},
+		{"both callbacks", ImmutableConfig{Fields: []string{"name"}, Callback: failingCallback, OriginalCallback: func(e *core.RecordEvent, original *models.Record) error { return nil }}, map[string]interface{}{}, "Callback can't be combined with OriginalCallback"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableWith(tc.config)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tc.expectErrorContains)
+				} else if !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
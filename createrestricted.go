@@ -0,0 +1,55 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RuleCreateRestricted is the kind of the rule created by MakeCreateRestricted.
+const RuleCreateRestricted RuleKind = "create_restricted"
+
+// MakeCreateRestricted returns a create hook that rejects the new record when any of
+// the provided fields carries a non-empty value, so that the fields can only be
+// assigned by the server:
+//
+//	app.OnRecordCreate("orders").Add(pbimmutable.MakeCreateRestricted("number", "status"))
+//
+// A new record has no original state, so unlike MakeImmutable the hook doesn't compare
+// anything: it checks the values the record is created with. "Empty" follows the stored
+// value of the field type, like in MakeWriteOnce. At least one field must be provided.
+//
+// Hooks run in registration order, so register the hooks that assign the server values
+// after this one (or assign them after e.Next() returns); otherwise their values are
+// rejected too. To keep the fields server controlled after the creation as well, also
+// bind MakeImmutable with the same fields to the update hook of the collection.
+//
+// An optional callback and options can be passed with the fields and behave the same
+// as in MakeImmutable.
+func MakeCreateRestricted(args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeCreateRestricted", args)
+	if cfg.setupErr == nil && len(cfg.fields) == 0 {
+		cfg.setupErr = errors.New("pbimmutable.MakeCreateRestricted: no fields provided")
+	}
+	cfg.checkNew = true
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		// the zero record stands in for the missing original, so Old is always the empty value
+		blank := models.NewRecord(e.Record.Collection())
+
+		changes := make(ChangeSet, 0, len(cfg.fields))
+		for _, field := range cfg.fields {
+			change := newChange(blank, e.Record, field, RuleCreateRestricted)
+			if !isEmptyValue(change.New) {
+				change.Violated = true
+				change.Message = fmt.Sprintf("Field '%s' is assigned by the server and can't be set on create.", field)
+			}
+			changes = append(changes, change)
+		}
+		return changes, nil
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,125 @@
+package pbimmutable
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/USERNAME/pbimmutable/pbimmutabletest"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeCreateRestricted(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	tests := []struct {
+		name                string
+		fields              []string
+		values              map[string]interface{}
+		expectErrorContains string
+	}{
+		{"restricted fields not set", []string{"status", "value"}, map[string]interface{}{"name": "new"}, ""},
+		{"restricted fields empty", []string{"status", "value"}, map[string]interface{}{"name": "new", "status": "", "value": 0}, ""},
+		{"restricted text set", []string{"status", "value"}, map[string]interface{}{"name": "new", "status": "paid"}, "Field 'status' is assigned by the server and can't be set on create."},
+		{"restricted number set", []string{"status", "value"}, map[string]interface{}{"name": "new", "value": 5}, "Field 'value' is assigned by the server"},
+		{"several fields set", []string{"status", "value"}, map[string]interface{}{"status": "paid", "value": 5}, "Attempt to modify 2 immutable fields: status, value."},
+		{"no fields", nil, map[string]interface{}{}, "pbimmutable.MakeCreateRestricted: no fields provided"},
+		{"unknown field", []string{"nmae"}, map[string]interface{}{}, `unknown fields "nmae"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			record := models.NewRecord(coll)
+			for k, v := range tc.values {
+				record.Set(k, v)
+			}
+
+			args := make([]interface{}, len(tc.fields))
+			for i, field := range tc.fields {
+				args[i] = field
+			}
+
+			err := MakeCreateRestricted(args...)(&core.RecordEvent{App: app, Record: record})
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestMakeCreateRestricted_ServerAssigned(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	// the server value is assigned by a hook registered after the guard
+	app.OnRecordCreate(coll.Name).Add(MakeCreateRestricted("status"))
+	app.OnRecordCreate(coll.Name).Add(func(e *core.RecordEvent) error {
+		e.Record.Set("status", "pending")
+		return e.Next()
+	})
+
+	record := models.NewRecord(coll)
+	record.Set("name", "server_assigned")
+	if err := app.OnRecordCreate(coll.Name).Trigger(&core.RecordEvent{App: app, Record: record}); err != nil {
+		t.Fatalf("Expected the server assigned value to pass, got: %v", err)
+	}
+	if record.GetString("status") != "pending" {
+		t.Errorf("Expected status 'pending', got %q", record.GetString("status"))
+	}
+
+	clientRecord := models.NewRecord(coll)
+	clientRecord.Set("name", "client_assigned")
+	clientRecord.Set("status", "paid")
+	err := app.OnRecordCreate(coll.Name).Trigger(&core.RecordEvent{App: app, Record: clientRecord})
+	if err == nil || !strings.Contains(err.Error(), "Field 'status' is assigned by the server") {
+		t.Errorf("Expected the client value to be rejected, got: %v", err)
+	}
+}
+
+func TestMakeCreateRestricted_Chain(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	var order []string
+	callback := func(e *core.RecordEvent) error {
+		order = append(order, "callback")
+		return nil
+	}
+	hookFunc := Chain(MakePinned("description", "v1"), MakeCreateRestricted("status", callback, WithForbiddenStatus()))
+
+	record := models.NewRecord(coll)
+	record.Set("name", "chained")
+	record.Set("description", "v1")
+	event := pbimmutabletest.NewEvent(&core.RecordEvent{App: app, Record: record}, func(e *core.RecordEvent) error {
+		order = append(order, "next")
+		return nil
+	})
+	if err := event.Run(hookFunc); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if event.NextCalls() != 1 || strings.Join(order, ",") != "callback,next" {
+		t.Errorf("Expected the callback and a single e.Next() call, got %d calls and %v", event.NextCalls(), order)
+	}
+
+	record = models.NewRecord(coll)
+	record.Set("description", "v1")
+	record.Set("status", "paid")
+	event = pbimmutabletest.NewEvent(&core.RecordEvent{App: app, Record: record}, nil)
+
+	var apiErr *apis.ApiError
+	if err := event.Run(hookFunc); !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		t.Errorf("Expected a 403 error, got: %v", err)
+	}
+	if event.NextCalled() {
+		t.Errorf("Expected the rejected record not to reach e.Next()")
+	}
+}
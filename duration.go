@@ -0,0 +1,47 @@
+package pbimmutable
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationNormalizer returns a Normalizer for interval-like fields that converts
+// the stored representation into a time.Duration, so that equal durations compare
+// equal regardless of the unit they were submitted in.
+//
+// Plain numbers (and numeric strings) are interpreted as a count of unit,
+// e.g. with unit time.Second the number 3600 is one hour. Strings with a unit
+// suffix accepted by time.ParseDuration ("60m", "1h", "90s") are parsed as is.
+// Values that can't be interpreted are returned unchanged and compared strictly.
+//
+// Unit strings only reach the normalizer for text and JSON fields. A number field
+// casts every value when it is set on the record, before any hook runs, so "60m"
+// is already stored as 0 and compares as zero seconds. For number fields, convert
+// unit strings into the count of unit before they are set on the record (e.g. in the
+// request handler), and the normalizer only evens out int, float and numeric values.
+//
+// Usage example:
+// MakeImmutable("timeout", WithNormalizer("timeout", DurationNormalizer(time.Second)))
+func DurationNormalizer(unit time.Duration) Normalizer {
+	return func(value any) any {
+		switch v := value.(type) {
+		case int:
+			return time.Duration(v) * unit
+		case int64:
+			return time.Duration(v) * unit
+		case float64:
+			return time.Duration(math.Round(v * float64(unit)))
+		case string:
+			s := strings.TrimSpace(v)
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return time.Duration(math.Round(f * float64(unit)))
+			}
+			if d, err := time.ParseDuration(s); err == nil {
+				return d
+			}
+		}
+		return value
+	}
+}
@@ -0,0 +1,83 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestDurationNormalizer(t *testing.T) {
+	normalize := DurationNormalizer(time.Second)
+
+	tests := []struct {
+		value    any
+		expected any
+	}{
+		{3600, time.Hour},
+		{3600.0, time.Hour},
+		{"3600", time.Hour},
+		{"60m", time.Hour},
+		{"1h", time.Hour},
+		{"1.5", 1500 * time.Millisecond},
+		{"not a duration", "not a duration"},
+		{nil, nil},
+	}
+
+	for _, tc := range tests {
+		if got := normalize(tc.value); got != tc.expected {
+			t.Errorf("normalize(%#v): expected %v, got %v", tc.value, tc.expected, got)
+		}
+	}
+}
+
+func TestWithNormalizer_Duration(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	// "value" is a number field storing the duration in seconds,
+	// "description" a text field storing it as a string.
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "duration_test")
+	initialRecord.Set("value", 3600)
+	initialRecord.Set("description", "3600")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		field       string
+		value       any
+		expectError bool
+	}{
+		{"number: same seconds", "value", 3600, false},
+		{"number: same seconds as float", "value", 3600.0, false},
+		{"number: same seconds as string", "value", "3600", false},
+		{"number: different seconds", "value", 3601, true},
+		// the number field casts the unit string to 0 on Set, before the normalizer runs
+		{"number: unit string is cast to zero", "value", "60m", true},
+		{"text: same seconds", "description", "3600", false},
+		{"text: same duration in minutes", "description", "60m", false},
+		{"text: same duration in hours", "description", "1h", false},
+		{"text: different duration in minutes", "description", "61m", true},
+		{"text: different seconds", "description", "3601", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable(tc.field, WithNormalizer(tc.field, DurationNormalizer(time.Second)))
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{tc.field: tc.value}))
+
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field '"+tc.field+"'") {
+					t.Errorf("Expected immutable field error, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package pbimmutable
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// enforcementDisabled is the inverse of the global toggle of SetEnabled
+// (so that the zero value means enabled).
+var enforcementDisabled atomic.Bool
+
+// SetEnabled turns the enforcement of all hooks of this package on or off at runtime,
+// e.g. for an emergency data fix without a redeploy. It is safe for concurrent use.
+//
+// While disabled, the hooks skip their checks (only setup errors are still reported),
+// run their callbacks and call e.Next() as for an accepted update. The record maintenance
+// of the hooks keeps running, so e.g. MakeLockOnSeal still stamps the records sealed in the
+// meantime and MakeLimitedChanges still counts the changes. CheckRecord, CheckImmutable and
+// ValidateImmutable report no violations either. Enforcement is enabled by default.
+//
+// Every change of the toggle is logged with slog.Default(), as a warning when
+// enforcement is turned off.
+func SetEnabled(enabled bool) {
+	if wasDisabled := enforcementDisabled.Swap(!enabled); wasDisabled == !enabled {
+		return // unchanged
+	}
+
+	if enabled {
+		slog.Info("pbimmutable: immutability enforcement enabled")
+	} else {
+		slog.Warn("pbimmutable: immutability enforcement DISABLED, all immutability hooks pass updates through")
+	}
+}
+
+// Enabled reports whether the hooks of this package enforce their checks (see SetEnabled).
+func Enabled() bool {
+	return !enforcementDisabled.Load()
+}
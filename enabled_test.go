@@ -0,0 +1,124 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestSetEnabled(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "toggle_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	if !Enabled() {
+		t.Fatal("Expected enforcement to be enabled by default")
+	}
+
+	updates := map[string]interface{}{"name": "changed"}
+
+	SetEnabled(false)
+	defer SetEnabled(true)
+
+	if Enabled() {
+		t.Fatal("Expected enforcement to be disabled")
+	}
+
+	t.Run("hooks pass through and run the callback", func(t *testing.T) {
+		var gotOriginal *models.Record
+		called := false
+		hookFunc := MakeImmutable("name", func(e *core.RecordEvent, original *models.Record) error {
+			called = true
+			gotOriginal = original
+			return nil
+		})
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, updates)); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !called || gotOriginal == nil || gotOriginal.GetString("name") != "toggle_test" {
+			t.Errorf("Expected the callback to run with the original record, called=%v original=%v", called, gotOriginal)
+		}
+	})
+
+	t.Run("record maintenance keeps running", func(t *testing.T) {
+		lockColl := &models.Collection{
+			Name: "test_toggle_locks",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{Name: "status", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: DefaultLockedByField, Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: DefaultLockedAtField, Type: schema.FieldTypeDate},
+			),
+		}
+		if err := app.Dao().SaveCollection(lockColl); err != nil {
+			t.Fatalf("Failed to save collection: %v", err)
+		}
+
+		record := models.NewRecord(lockColl)
+		record.Set("status", "draft")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+
+		event := newUpdateEvent(app, record, map[string]interface{}{"status": "completed"})
+		err := MakeLockOnSeal(LockRule{Seals: func(r *models.Record) bool {
+			return r.GetString("status") == "completed"
+		}})(event)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if event.Record.GetString(DefaultLockedByField) != SystemLockActor || event.Record.GetDateTime(DefaultLockedAtField).IsZero() {
+			t.Errorf("Expected the sealed record to be stamped, got %v", event.Record.PublicExport())
+		}
+	})
+
+	t.Run("callback errors are still reported", func(t *testing.T) {
+		err := MakeImmutable("name", func(e *core.RecordEvent) error {
+			return errors.New("callback failed")
+		})(newUpdateEvent(app, initialRecord, updates))
+		if err == nil || !strings.Contains(err.Error(), "callback failed") {
+			t.Errorf("Expected callback error, got: %v", err)
+		}
+	})
+
+	t.Run("other constructors and checks", func(t *testing.T) {
+		if err := MakeWriteOnce("name")(newUpdateEvent(app, initialRecord, updates)); err != nil {
+			t.Errorf("Expected MakeWriteOnce to pass, got: %v", err)
+		}
+		if err := CheckImmutable(newUpdateEvent(app, initialRecord, updates), "name"); err != nil {
+			t.Errorf("Expected CheckImmutable to pass, got: %v", err)
+		}
+
+		record := models.NewRecord(coll)
+		record.Set("status", "client_value")
+		if err := MakeCreateRestricted("status")(&core.RecordEvent{App: app, Record: record}); err != nil {
+			t.Errorf("Expected MakeCreateRestricted to pass, got: %v", err)
+		}
+	})
+
+	t.Run("setup errors are still reported", func(t *testing.T) {
+		err := MakeImmutable("nmae")(newUpdateEvent(app, initialRecord, updates))
+		if err == nil || !strings.Contains(err.Error(), `unknown fields "nmae"`) {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+
+	SetEnabled(true)
+
+	t.Run("re-enabled", func(t *testing.T) {
+		err := MakeImmutable("name")(newUpdateEvent(app, initialRecord, updates))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+}
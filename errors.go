@@ -0,0 +1,35 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/apis"
+)
+
+// ImmutableFieldError is the error returned by the hooks for an update rejected because
+// it changes frozen fields (unless replaced by WithViolationResponder), e.g.
+//
+//	var immutableErr *pbimmutable.ImmutableFieldError
+//	if errors.As(err, &immutableErr) {
+//		log.Printf("frozen fields %v of record %s", immutableErr.Fields, immutableErr.RecordId)
+//	}
+//
+// It wraps the *apis.ApiError with the 400 response sent to the client (403 with
+// WithForbiddenStatus), so errors.As with an *apis.ApiError target (as used by the
+// PocketBase error handler) still works.
+type ImmutableFieldError struct {
+	Collection string    // The name of the record collection.
+	RecordId   string    // The id of the updated record.
+	Fields     []string  // The violated fields, in evaluation order.
+	Violations ChangeSet // The violated changes, in evaluation order.
+
+	apiErr *apis.ApiError
+}
+
+// Error returns the message of the wrapped API error.
+func (e *ImmutableFieldError) Error() string {
+	return e.apiErr.Error()
+}
+
+// Unwrap returns the wrapped API error.
+func (e *ImmutableFieldError) Unwrap() error {
+	return e.apiErr
+}
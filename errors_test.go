@@ -0,0 +1,93 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestImmutableFieldError(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "typed_error_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	t.Run("violation", func(t *testing.T) {
+		hookFunc := MakeImmutable("name", "value", "status")
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed", "value": 200}))
+
+		// wrapped like the errors of nested hooks
+		err = fmt.Errorf("outer hook: %w", err)
+
+		var immutableErr *ImmutableFieldError
+		if !errors.As(err, &immutableErr) {
+			t.Fatalf("Expected *ImmutableFieldError, got %T (%v)", err, err)
+		}
+		if immutableErr.Collection != coll.Name || immutableErr.RecordId != initialRecord.Id {
+			t.Errorf("Unexpected record of the error: %s/%s", immutableErr.Collection, immutableErr.RecordId)
+		}
+		if strings.Join(immutableErr.Fields, ",") != "name,value" || len(immutableErr.Violations) != 2 {
+			t.Errorf("Unexpected violated fields: %v", immutableErr.Fields)
+		}
+		if immutableErr.Error() != "Attempt to modify 2 immutable fields: name, value." {
+			t.Errorf("Unexpected error message: %s", immutableErr.Error())
+		}
+
+		var apiErr *apis.ApiError
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest {
+			t.Errorf("Expected a wrapped 400 *apis.ApiError, got: %v", apiErr)
+		}
+	})
+
+	t.Run("forbidden status", func(t *testing.T) {
+		hookFuncs := map[string]func(e *core.RecordEvent) error{
+			"option":       MakeImmutable("name", "value", WithForbiddenStatus()),
+			"typed config": MakeImmutableWith(ImmutableConfig{Fields: []string{"name", "value"}, ForbiddenStatus: true}),
+		}
+
+		for name, hookFunc := range hookFuncs {
+			err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed", "value": 200}))
+
+			var immutableErr *ImmutableFieldError
+			if !errors.As(err, &immutableErr) || strings.Join(immutableErr.Fields, ",") != "name,value" {
+				t.Fatalf("%s: expected *ImmutableFieldError with the violated fields, got %T (%v)", name, err, err)
+			}
+
+			var apiErr *apis.ApiError
+			if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+				t.Errorf("%s: expected a wrapped 403 *apis.ApiError, got: %v", name, apiErr)
+			}
+			if apiErr != nil && apiErr.Message != "Attempt to modify 2 immutable fields: name, value." {
+				t.Errorf("%s: unexpected error message: %s", name, apiErr.Message)
+			}
+		}
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		var immutableErr *ImmutableFieldError
+
+		err := MakeImmutable("nmae")(newUpdateEvent(app, initialRecord, map[string]interface{}{}))
+		if err == nil || errors.As(err, &immutableErr) {
+			t.Errorf("Expected a setup error that is not an *ImmutableFieldError, got: %v", err)
+		}
+
+		err = MakeImmutable("name", WithViolationResponder(func(violations []Violation) error {
+			return errors.New("custom")
+		}))(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+		if err == nil || errors.As(err, &immutableErr) {
+			t.Errorf("Expected the responder error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// MakeExpandImmutable returns a hook that rejects updates carrying expand data of
+// relationField (see record.SetExpand) in which the frozen fields of the related records
+// differ from their stored values, or all their user-defined fields when no fields are given:
+//
+//	MakeExpandImmutable("customer", "email", "vat")
+//
+// PocketBase only saves the record itself, but hooks and custom handlers further down the
+// chain may act on the expanded records, e.g. by saving them as well. The hook closes that
+// path without checking the related collection on its own.
+//
+// Every expanded record is compared against the stored record with the same id in the
+// related collection. The violations are reported as "<relationField>.<field>". An
+// expanded record that doesn't exist is rejected with a 404 error. For HTTP requests, an
+// expanded record the caller can't view (see the view rule of the related collection) is
+// rejected with a 403 error; non-HTTP events skip the access check.
+//
+// relationField must be a relation field of the collection schema and the fields must
+// exist in the related collection, otherwise the first update fails with a setup error.
+func MakeExpandImmutable(relationField string, fields ...string) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeExpandImmutable", nil)
+	if relationField == "" {
+		cfg.setupErr = errors.New("pbimmutable.MakeExpandImmutable: a relation field must be provided")
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		field := original.Schema().GetFieldByName(relationField)
+		if field == nil || field.Type != schema.FieldTypeRelation {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeExpandImmutable setup error: '%s' is not a relation field of collection %s.", relationField, original.Collection().Name), nil)
+		}
+		options, _ := field.Options.(*schema.RelationOptions)
+		if options == nil {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeExpandImmutable setup error: missing relation options for '%s'.", relationField), nil)
+		}
+
+		expanded := e.Record.ExpandedAll(relationField)
+		if len(expanded) == 0 {
+			return nil, nil
+		}
+
+		info := requestInfo(e)
+
+		var changes ChangeSet
+		for _, related := range expanded {
+			stored, err := e.App.Dao().FindRecordById(options.CollectionId, related.Id)
+			if err != nil {
+				return nil, apis.NewNotFoundError(fmt.Sprintf("The expanded '%s' record %s doesn't exist.", relationField, related.Id), nil)
+			}
+
+			if info != nil {
+				canAccess, err := e.App.Dao().CanAccessRecord(stored, info, stored.Collection().ViewRule)
+				if err != nil || !canAccess {
+					return nil, apis.NewForbiddenError(fmt.Sprintf("The expanded '%s' record %s can't be accessed.", relationField, related.Id), nil)
+				}
+			}
+
+			if err := validateFieldNames(stored, fields); err != nil {
+				return nil, apis.NewBadRequestError(fmt.Sprintf("MakeExpandImmutable setup error: pbimmutable.MakeExpandImmutable: %v", err), nil)
+			}
+
+			for _, change := range cfg.cmp.evaluate(stored, related, fields) {
+				if change.Violated {
+					change.Message = fmt.Sprintf("Attempt to modify immutable field '%s' of the expanded '%s' record %s.", change.Field, relationField, related.Id)
+				}
+				change.Field = relationField + "." + change.Field
+				changes = append(changes, change)
+			}
+		}
+
+		return changes, nil
+	}
+
+	return newHook(cfg)
+}
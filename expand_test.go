@@ -0,0 +1,100 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestMakeExpandImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	orders := &models.Collection{
+		Name: "test_expand_orders",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "item", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: coll.Id, MaxSelect: types.Pointer(1)}},
+		),
+	}
+	if err := app.Dao().SaveCollection(orders); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	item := models.NewRecord(coll)
+	item.Set("name", "expanded_item")
+	item.Set("value", 10)
+	if err := app.Dao().SaveRecord(item); err != nil {
+		t.Fatalf("Failed to save item: %v", err)
+	}
+
+	order := models.NewRecord(orders)
+	order.Set("title", "order")
+	order.Set("item", item.Id)
+	if err := app.Dao().SaveRecord(order); err != nil {
+		t.Fatalf("Failed to save order: %v", err)
+	}
+
+	// expandedItem returns a copy of the stored item with the provided changes
+	expandedItem := func(id string, updates map[string]interface{}) *models.Record {
+		related := item.CleanCopy()
+		related.Id = id
+		for k, v := range updates {
+			related.Set(k, v)
+		}
+		return related
+	}
+
+	tests := []struct {
+		name                string
+		fields              []string
+		expand              *models.Record
+		info                *models.RequestInfo
+		expectErrorContains string
+	}{
+		{"no expand data", []string{"name"}, nil, nil, ""},
+		{"unchanged expand data", []string{"name"}, expandedItem(item.Id, nil), nil, ""},
+		{"changed mutable field", []string{"name"}, expandedItem(item.Id, map[string]interface{}{"value": 20}), nil, ""},
+		{"changed frozen field", []string{"name"}, expandedItem(item.Id, map[string]interface{}{"name": "changed"}), nil, "Attempt to modify immutable field 'name' of the expanded 'item' record"},
+		{"all fields", nil, expandedItem(item.Id, map[string]interface{}{"value": 20}), nil, "Attempt to modify immutable field 'value' of the expanded 'item' record"},
+		{"missing record", []string{"name"}, expandedItem("missing12345678", nil), nil, "The expanded 'item' record missing12345678 doesn't exist."},
+		{"inaccessible record", []string{"name"}, expandedItem(item.Id, nil), &models.RequestInfo{}, "The expanded 'item' record " + item.Id + " can't be accessed."},
+		{"admin can access", []string{"name"}, expandedItem(item.Id, nil), &models.RequestInfo{Admin: &models.Admin{}}, ""},
+		{"unknown related field", []string{"nmae"}, expandedItem(item.Id, nil), nil, `unknown fields "nmae"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeExpandImmutable("item", tc.fields...)
+
+			event := newUpdateEvent(app, order, nil)
+			if tc.info != nil {
+				event = newRequestUpdateEvent(app, order, nil, tc.info)
+			}
+			if tc.expand != nil {
+				event.Record.SetExpand(map[string]any{"item": tc.expand})
+			}
+
+			err := hookFunc(event)
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("not a relation field", func(t *testing.T) {
+		err := MakeExpandImmutable("title")(newUpdateEvent(app, order, nil))
+		if err == nil || !strings.Contains(err.Error(), "'title' is not a relation field") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package pbimmutable
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FieldOptionsCollection is the name of the collection holding the field options read by
+// MakeImmutableFromFieldOptions, one record per field (see NewFieldOptionsCollection).
+//
+// PocketBase only keeps the options it knows in a schema field: unknown keys such as
+// "immutable" are dropped when a collection is saved or loaded. So the options are
+// stored as records of their own collection, which survive like any other data and
+// can be edited in the admin UI.
+const FieldOptionsCollection = "pbimmutable_field_options"
+
+// FieldOptionImmutable is the bool field of the FieldOptionsCollection records that marks
+// their field as immutable.
+const FieldOptionImmutable = "immutable"
+
+// NewFieldOptionsCollection returns a new FieldOptionsCollection, to be saved once,
+// e.g. from a migration. Its records have the following fields:
+//   - collection (text, required) name of the collection of the field
+//   - field      (text, required) name of the field
+//   - immutable  (bool)           FieldOptionImmutable
+//   - secret     (bool)           FieldOptionSecret
+//
+// A field has at most one record. The collection is only accessible to admins.
+func NewFieldOptionsCollection() *models.Collection {
+	return &models.Collection{
+		Name: FieldOptionsCollection,
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "collection", Type: schema.FieldTypeText, Required: true},
+			&schema.SchemaField{Name: "field", Type: schema.FieldTypeText, Required: true},
+			&schema.SchemaField{Name: FieldOptionImmutable, Type: schema.FieldTypeBool},
+			&schema.SchemaField{Name: FieldOptionSecret, Type: schema.FieldTypeBool},
+		),
+		Indexes: types.JsonArray[string]{
+			fmt.Sprintf("CREATE UNIQUE INDEX idx_%s_field ON %s (collection, field)", FieldOptionsCollection, FieldOptionsCollection),
+		},
+	}
+}
+
+// SetFieldOption sets the bool option key (e.g. FieldOptionImmutable) of the field
+// of the named collection in the FieldOptionsCollection, creating the field record
+// when needed, and drops the cached marks so the hooks see the change right away.
+// It is intended for migrations.
+func SetFieldOption(dao *daos.Dao, collection, field, key string, value bool) error {
+	record, err := dao.FindFirstRecordByFilter(FieldOptionsCollection, "collection = {:collection} && field = {:field}", dbx.Params{"collection": collection, "field": field})
+	if errors.Is(err, sql.ErrNoRows) {
+		optionsCollection, findErr := dao.FindCollectionByNameOrId(FieldOptionsCollection)
+		if findErr != nil {
+			return fmt.Errorf("pbimmutable.SetFieldOption: failed to find collection %q: %w", FieldOptionsCollection, findErr)
+		}
+		record = models.NewRecord(optionsCollection)
+		record.Set("collection", collection)
+		record.Set("field", field)
+	} else if err != nil {
+		return fmt.Errorf("pbimmutable.SetFieldOption: failed to load the options of %s.%s: %w", collection, field, err)
+	}
+
+	record.Set(key, value)
+	if err := dao.SaveRecord(record); err != nil {
+		return fmt.Errorf("pbimmutable.SetFieldOption: failed to save the options of %s.%s: %w", collection, field, err)
+	}
+	resetFieldOptions()
+
+	return nil
+}
+
+// MakeImmutableFromFieldOptions returns a hook that freezes exactly the fields whose
+// record in the FieldOptionsCollection sets FieldOptionImmutable to true. The marks are
+// cached per collection for fieldOptionsTTL, so changes made in the admin UI apply within
+// seconds (and right away when made with SetFieldOption) without code changes.
+//
+// The convention is the FieldOptionImmutable field of the field's record:
+//   - true freezes the field,
+//   - false or no record (or no FieldOptionsCollection at all) leaves it editable.
+//
+// Field names can't be passed, but the optional callback and options behave the same
+// as in MakeImmutable. Use ValidateFieldOptions at startup to catch options of unknown
+// collections and fields.
+func MakeImmutableFromFieldOptions(args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeImmutableFromFieldOptions", args)
+	if cfg.setupErr == nil && len(cfg.fields) > 0 {
+		cfg.setupErr = errors.New("pbimmutable.MakeImmutableFromFieldOptions: the fields are read from the field options and can't be passed as arguments")
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		fields, err := optionFields(e.App.Dao(), e.Record.Collection(), FieldOptionImmutable)
+		if err != nil {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("Failed to load the field options of collection %s for immutability check.", e.Record.Collection().Name), err)
+		}
+		if len(fields) == 0 {
+			return nil, nil
+		}
+		return cfg.cmp.evaluate(original, e.Record, fields), nil
+	}
+
+	return newHook(cfg)
+}
+
+// fieldOptionsTTL is how long the marks loaded by optionFields are reused.
+const fieldOptionsTTL = 10 * time.Second
+
+// fieldOptions caches the marked fields per collection and option key (see optionFields).
+var fieldOptions = struct {
+	sync.Mutex
+	entries map[fieldOptionsKey]fieldOptionsEntry
+}{entries: map[fieldOptionsKey]fieldOptionsEntry{}}
+
+type fieldOptionsKey struct {
+	collection string // the id (or the name of an unsaved collection)
+	key        string
+}
+
+type fieldOptionsEntry struct {
+	fields   []string
+	loadedAt time.Time
+}
+
+// resetFieldOptions drops all cached marks.
+func resetFieldOptions() {
+	fieldOptions.Lock()
+	fieldOptions.entries = map[fieldOptionsKey]fieldOptionsEntry{}
+	fieldOptions.Unlock()
+}
+
+// optionFields returns the schema fields of the collection whose bool option key is set
+// in the FieldOptionsCollection. Options of fields missing from the schema are ignored.
+//
+// The marks are cached per collection for fieldOptionsTTL, so that checked updates don't
+// query the FieldOptionsCollection every time. Load errors are not cached.
+func optionFields(dao *daos.Dao, collection *models.Collection, key string) ([]string, error) {
+	cacheKey := fieldOptionsKey{collection: collection.Id, key: key}
+	if cacheKey.collection == "" {
+		cacheKey.collection = collection.Name
+	}
+
+	fieldOptions.Lock()
+	entry, ok := fieldOptions.entries[cacheKey]
+	fieldOptions.Unlock()
+
+	if !ok || time.Since(entry.loadedAt) >= fieldOptionsTTL {
+		marked, err := loadOptionFields(dao, collection.Name, key)
+		if err != nil {
+			return nil, err
+		}
+		entry = fieldOptionsEntry{fields: marked, loadedAt: time.Now()}
+
+		fieldOptions.Lock()
+		fieldOptions.entries[cacheKey] = entry
+		fieldOptions.Unlock()
+	}
+
+	// the schema may change while the marks are cached
+	var fields []string
+	for _, field := range entry.fields {
+		if collection.Schema.GetFieldByName(field) != nil {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// loadOptionFields queries the fields of the named collection whose bool option key
+// is set in the FieldOptionsCollection.
+func loadOptionFields(dao *daos.Dao, collection, key string) ([]string, error) {
+	if _, err := dao.FindCollectionByNameOrId(FieldOptionsCollection); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // no options were set up
+		}
+		return nil, err
+	}
+
+	records, err := dao.FindRecordsByFilter(FieldOptionsCollection, "collection = {:collection} && "+key+" = true", "", 0, 0, dbx.Params{"collection": collection})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(records))
+	for _, record := range records {
+		fields = append(fields, record.GetString("field"))
+	}
+	return fields, nil
+}
+
+// ValidateFieldOptions checks that the FieldOptionsCollection exists and that each of its
+// records refers to a field of a base or auth collection, and returns an error listing
+// every record that doesn't (e.g. left behind by a renamed field).
+//
+// It is intended to be called once at startup, e.g. from an OnBeforeServe hook.
+func ValidateFieldOptions(app core.App) error {
+	if _, err := app.Dao().FindCollectionByNameOrId(FieldOptionsCollection); err != nil {
+		return fmt.Errorf("pbimmutable.ValidateFieldOptions: failed to find collection %q (see NewFieldOptionsCollection): %w", FieldOptionsCollection, err)
+	}
+
+	records, err := app.Dao().FindRecordsByFilter(FieldOptionsCollection, "id != ''", "collection,field", 0, 0)
+	if err != nil {
+		return fmt.Errorf("pbimmutable.ValidateFieldOptions: failed to load the field options: %w", err)
+	}
+
+	var invalid []string
+	for _, record := range records {
+		collectionName, field := record.GetString("collection"), record.GetString("field")
+
+		collection, err := app.Dao().FindCollectionByNameOrId(collectionName)
+		switch {
+		case err != nil:
+			invalid = append(invalid, fmt.Sprintf("%s.%s (unknown collection)", collectionName, field))
+		case !collection.IsBase() && !collection.IsAuth():
+			invalid = append(invalid, fmt.Sprintf("%s.%s (%s collections are read only)", collectionName, field, collection.Type))
+		case collection.Schema.GetFieldByName(field) == nil:
+			invalid = append(invalid, fmt.Sprintf("%s.%s (unknown field)", collectionName, field))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("pbimmutable.ValidateFieldOptions: invalid field options of %s", strings.Join(invalid, ", "))
+	}
+
+	return nil
+}
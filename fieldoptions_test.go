@@ -0,0 +1,212 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeImmutableFromFieldOptions(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_products",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "sku", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "price", Type: schema.FieldTypeNumber},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	product := models.NewRecord(coll)
+	product.Set("sku", "SKU-1")
+	product.Set("title", "Product")
+	product.Set("price", 10)
+	if err := app.Dao().SaveRecord(product); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	// reload reloads the product with its collection from the database,
+	// as PocketBase does for an update request
+	reload := func(t *testing.T) *models.Record {
+		t.Helper()
+		collection, err := app.Dao().FindCollectionByNameOrId(coll.Name)
+		if err != nil {
+			t.Fatalf("Failed to reload collection: %v", err)
+		}
+		record, err := app.Dao().FindRecordById(collection.Id, product.Id)
+		if err != nil {
+			t.Fatalf("Failed to reload record: %v", err)
+		}
+		return record
+	}
+
+	hookFunc := MakeImmutableFromFieldOptions()
+
+	t.Run("no field options collection", func(t *testing.T) {
+		err := hookFunc(newUpdateEvent(app, reload(t), map[string]interface{}{"sku": "SKU-2"}))
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	if err := app.Dao().SaveCollection(NewFieldOptionsCollection()); err != nil {
+		t.Fatalf("Failed to save field options collection: %v", err)
+	}
+	if err := SetFieldOption(app.Dao(), coll.Name, "sku", FieldOptionImmutable, true); err != nil {
+		t.Fatalf("Failed to set field option: %v", err)
+	}
+	if err := SetFieldOption(app.Dao(), coll.Name, "title", FieldOptionImmutable, false); err != nil {
+		t.Fatalf("Failed to set field option: %v", err)
+	}
+
+	t.Run("marked field is frozen", func(t *testing.T) {
+		err := hookFunc(newUpdateEvent(app, reload(t), map[string]interface{}{"sku": "SKU-2"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'sku'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+
+	t.Run("unmarked fields stay editable", func(t *testing.T) {
+		err := hookFunc(newUpdateEvent(app, reload(t), map[string]interface{}{"title": "Renamed", "price": 20}))
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("unmarking a field unfreezes it", func(t *testing.T) {
+		if err := SetFieldOption(app.Dao(), coll.Name, "sku", FieldOptionImmutable, false); err != nil {
+			t.Fatalf("Failed to set field option: %v", err)
+		}
+		defer SetFieldOption(app.Dao(), coll.Name, "sku", FieldOptionImmutable, true)
+
+		err := hookFunc(newUpdateEvent(app, reload(t), map[string]interface{}{"sku": "SKU-2"}))
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("field names are not accepted", func(t *testing.T) {
+		err := MakeImmutableFromFieldOptions("title")(newUpdateEvent(app, reload(t), nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeImmutableFromFieldOptions setup error") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+
+	t.Run("startup validation", func(t *testing.T) {
+		if err := ValidateFieldOptions(app); err != nil {
+			t.Fatalf("Expected valid options, got: %v", err)
+		}
+
+		if err := SetFieldOption(app.Dao(), coll.Name, "removed", FieldOptionImmutable, true); err != nil {
+			t.Fatalf("Failed to set field option: %v", err)
+		}
+		if err := SetFieldOption(app.Dao(), "missing_collection", "code", FieldOptionImmutable, true); err != nil {
+			t.Fatalf("Failed to set field option: %v", err)
+		}
+
+		err := ValidateFieldOptions(app)
+		if err == nil || !strings.Contains(err.Error(), "test_products.removed (unknown field)") || !strings.Contains(err.Error(), "missing_collection.code (unknown collection)") {
+			t.Errorf("Expected validation errors for the unknown field and collection, got: %v", err)
+		}
+
+		// options of unknown fields don't break the hook
+		err = hookFunc(newUpdateEvent(app, reload(t), map[string]interface{}{"title": "Renamed again"}))
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("schema convention", func(t *testing.T) {
+		marked := &models.Collection{
+			Name: "test_schema_marked",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{Name: "code", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "label", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "plain", Type: schema.FieldTypeText},
+			),
+		}
+		if err := app.Dao().SaveCollection(marked); err != nil {
+			t.Fatalf("Failed to save collection: %v", err)
+		}
+		if err := SetFieldOption(app.Dao(), marked.Name, "code", FieldOptionImmutable, true); err != nil {
+			t.Fatalf("Failed to set field option: %v", err)
+		}
+		if err := SetFieldOption(app.Dao(), marked.Name, "label", FieldOptionImmutable, false); err != nil {
+			t.Fatalf("Failed to set field option: %v", err)
+		}
+
+		record := models.NewRecord(marked)
+		record.Set("code", "C-1")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+
+		// the collection and the record are loaded from the database, like for an update request
+		reloaded, err := app.Dao().FindCollectionByNameOrId(marked.Name)
+		if err != nil {
+			t.Fatalf("Failed to reload collection: %v", err)
+		}
+		stored, err := app.Dao().FindRecordById(reloaded.Id, record.Id)
+		if err != nil {
+			t.Fatalf("Failed to reload record: %v", err)
+		}
+
+		tests := []struct {
+			name                string
+			updates             map[string]interface{}
+			expectErrorContains string
+		}{
+			{"true freezes the field", map[string]interface{}{"code": "C-2"}, "Attempt to modify immutable field 'code'."},
+			{"false and missing options stay editable", map[string]interface{}{"label": "x", "plain": "z"}, ""},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				err := MakeImmutableFromFieldOptions()(newUpdateEvent(app, stored, tc.updates))
+
+				if tc.expectErrorContains != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+						t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+					}
+				} else if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+			})
+		}
+
+	})
+
+	t.Run("marks are cached", func(t *testing.T) {
+		// bypasses SetFieldOption, like an edit in the admin UI
+		option, err := app.Dao().FindFirstRecordByFilter(FieldOptionsCollection, "collection = {:collection} && field = 'sku'", dbx.Params{"collection": coll.Name})
+		if err != nil {
+			t.Fatalf("Failed to load field option: %v", err)
+		}
+		option.Set(FieldOptionImmutable, false)
+		if err := app.Dao().SaveRecord(option); err != nil {
+			t.Fatalf("Failed to save field option: %v", err)
+		}
+
+		err = hookFunc(newUpdateEvent(app, reload(t), map[string]interface{}{"sku": "SKU-2"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'sku'") {
+			t.Errorf("Expected the cached mark to still freeze sku, got: %v", err)
+		}
+
+		resetFieldOptions() // as if fieldOptionsTTL elapsed
+
+		err = hookFunc(newUpdateEvent(app, reload(t), map[string]interface{}{"sku": "SKU-2"}))
+		if err != nil {
+			t.Errorf("Expected the reloaded marks to unfreeze sku, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package pbimmutable
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// filterRecordIdParam is the placeholder binding the checked record id in MakeImmutableForFilter.
+const filterRecordIdParam = "pbimmutableRecordId"
+
+// MakeImmutableForFilter returns a hook that freezes the provided fields (or all
+// user-defined fields when none are given) only for records whose original state
+// matches filter, a PocketBase filter expression like the ones used for API rules:
+//
+//	MakeImmutableForFilter(`type = "contract"`, "price")
+//
+// The filter is evaluated with the same engine as the collection rules, against the
+// stored record, so it sees the original values rather than the pending update.
+// Records that don't match pass straight through to e.Next().
+//
+// Invalid filter syntax is reported as a setup error on the first update. References
+// to unknown fields can only be resolved against the collection and fail the update
+// that evaluates the filter.
+func MakeImmutableForFilter(filter string, fields ...string) func(e *core.RecordEvent) error {
+	args := make([]interface{}, len(fields))
+	for i, field := range fields {
+		args[i] = field
+	}
+
+	cfg := parseArgs("MakeImmutableForFilter", args)
+	if cfg.setupErr == nil {
+		if filter == "" {
+			cfg.setupErr = errors.New("pbimmutable.MakeImmutableForFilter: a filter must be provided")
+		} else if _, err := fexpr.Parse(filter); err != nil {
+			cfg.setupErr = fmt.Errorf("pbimmutable.MakeImmutableForFilter: invalid filter %q: %w", filter, err)
+		}
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		matched, err := matchesFilter(e, original, filter)
+		if err != nil {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeImmutableForFilter setup error: filter %q can't be evaluated for collection %s.", filter, original.Collection().Name), err)
+		}
+		if !matched {
+			return nil, nil
+		}
+
+		return cfg.cmp.evaluate(original, e.Record, cfg.fields), nil
+	}
+
+	return newHook(cfg)
+}
+
+// matchesFilter reports whether the stored state of original matches the filter expression.
+func matchesFilter(e *core.RecordEvent, original *models.Record, filter string) (bool, error) {
+	_, err := e.App.Dao().FindFirstRecordByFilter(
+		original.Collection().Id,
+		fmt.Sprintf("id = {:%s} && (%s)", filterRecordIdParam, filter),
+		dbx.Params{filterRecordIdParam: original.Id},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
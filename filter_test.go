@@ -0,0 +1,59 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeImmutableForFilter(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newRecord := func(status string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("name", "filter_test")
+		record.Set("status", status)
+		record.Set("value", 10)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	tests := []struct {
+		name                string
+		status              string
+		filter              string
+		fields              []string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"matching record is frozen", "contract", `status = "contract"`, []string{"value"}, map[string]interface{}{"value": 20}, "Attempt to modify immutable field 'value'"},
+		{"matching record keeps other fields editable", "contract", `status = "contract"`, []string{"value"}, map[string]interface{}{"name": "changed"}, ""},
+		{"other records pass through", "draft", `status = "contract"`, []string{"value"}, map[string]interface{}{"value": 20}, ""},
+		{"filter sees the original state", "draft", `status = "contract"`, []string{"value"}, map[string]interface{}{"status": "contract", "value": 20}, ""},
+		{"all fields without field names", "contract", `status = "contract" && value > 5`, nil, map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"invalid syntax", "contract", `status = `, []string{"value"}, nil, "pbimmutable.MakeImmutableForFilter: invalid filter"},
+		{"empty filter", "contract", "", []string{"value"}, nil, "pbimmutable.MakeImmutableForFilter: a filter must be provided"},
+		{"unknown filter field", "contract", `missing = "x"`, []string{"value"}, map[string]interface{}{"value": 20}, "MakeImmutableForFilter setup error"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			record := newRecord(tc.status)
+			hookFunc := MakeImmutableForFilter(tc.filter, tc.fields...)
+
+			err := hookFunc(newUpdateEvent(app, record, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
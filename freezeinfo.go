@@ -0,0 +1,180 @@
+package pbimmutable
+
+import (
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Reasons reported by FreezeInfo.Reason.
+const (
+	FreezeReasonImmutable   = "immutable"    // always frozen (MakeImmutable)
+	FreezeReasonState       = "state"        // frozen since a field reached a value (FreezeWhen)
+	FreezeReasonCondition   = "condition"    // frozen while the condition of the hook holds
+	FreezeReasonTimeWindow  = "time_window"  // frozen since the grace period elapsed (WithGracePeriods)
+	FreezeReasonLocked      = "locked"       // frozen since the record was sealed (MakeLockOnSeal)
+	FreezeReasonSoftDeleted = "soft_deleted" // frozen since the record was soft-deleted (MakeSoftDeleteImmutable)
+)
+
+// FreezeInfo describes the current freeze state of a single field.
+type FreezeInfo struct {
+	Frozen bool            `json:"frozen"`
+	Reason string          `json:"reason,omitempty"` // One of the FreezeReason* constants (empty if not frozen).
+	Since  *types.DateTime `json:"since,omitempty"`  // When the field was frozen, nil if not determinable.
+	Data   map[string]any  `json:"data,omitempty"`   // Optional reason specific data, e.g. the state value.
+}
+
+// FreezeStatus returns the freeze state of every field protected by the inspected hooks,
+// e.g. to render "This field was locked when the order was completed on <date>." tooltips:
+//
+//	var inspector pbimmutable.Inspector
+//	app.OnRecordUpdate("orders").Add(pbimmutable.MakeImmutable("total", pbimmutable.FreezeWhen("status", "completed"), pbimmutable.WithInspector(&inspector)))
+//
+//	status := pbimmutable.FreezeStatus(record, &inspector)
+//
+// The state is derived from the configuration each hook was built with (see WithInspector),
+// including its condition and grace periods, so it can't drift from what the hook enforces.
+// Request bypasses (e.g. WithAllowSuperusers) are not evaluated. Hooks with a custom check
+// (e.g. MakeMonotonic), a setup error or a detached inspector report no fields.
+//
+// It only reads the record. When several hooks cover a field, the first hook that
+// reports the field as frozen wins.
+func FreezeStatus(record *models.Record, inspectors ...*Inspector) map[string]FreezeInfo {
+	result := map[string]FreezeInfo{}
+	now := time.Now()
+
+	for _, inspector := range inspectors {
+		if inspector == nil || inspector.cfg == nil {
+			continue
+		}
+		for field, info := range inspector.cfg.freezeStatus(record, now) {
+			if current, ok := result[field]; ok && (current.Frozen || !info.Frozen) {
+				continue
+			}
+			result[field] = info
+		}
+	}
+
+	return result
+}
+
+// WithFrozenSinceField names the date field that stores when the fields of the hook were
+// frozen, e.g. "completedAt" for MakeImmutable("total", FreezeWhen("status", "completed")).
+// It is only reported by FreezeStatus and doesn't change what the hook enforces.
+func WithFrozenSinceField(field string) Option {
+	return func(cfg *hookConfig) {
+		cfg.frozenSinceField = field
+	}
+}
+
+// freezeOn sets the condition under which the hook checks its fields, together with the
+// description of that freeze reported by FreezeStatus, so that both describe the same rule.
+// A nil describe reports FreezeReasonCondition.
+func (cfg *hookConfig) freezeOn(condition func(original *models.Record) bool, describe func(record *models.Record) FreezeInfo) {
+	cfg.condition = condition
+	cfg.describeFreeze = describe
+}
+
+// freezeStatus returns the freeze state of the fields the hook protects in the record at now.
+func (cfg *hookConfig) freezeStatus(record *models.Record, now time.Time) map[string]FreezeInfo {
+	if cfg.setupErr != nil {
+		return nil
+	}
+
+	fields := cfg.protectedFields(record)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var info FreezeInfo
+	switch {
+	case cfg.condition == nil:
+		info = FreezeInfo{Reason: FreezeReasonImmutable, Since: dateTimeOf(record, models.SystemFieldCreated)}
+	case !cfg.condition(record):
+		// not frozen (yet)
+	case cfg.describeFreeze != nil:
+		info = cfg.describeFreeze(record)
+	default:
+		info = FreezeInfo{Reason: FreezeReasonCondition}
+	}
+	if info.Reason != "" {
+		info.Frozen = true
+		if cfg.frozenSinceField != "" {
+			info.Since = dateTimeOf(record, cfg.frozenSinceField)
+		}
+	}
+
+	result := make(map[string]FreezeInfo, len(fields))
+	for _, name := range fields {
+		result[name] = cfg.graceStatus(record, name, info, now)
+	}
+	return result
+}
+
+// protectedFields returns the fields the hook checks in records like record.
+// Hooks with a custom check (cfg.evaluate) don't declare their fields and report none.
+func (cfg *hookConfig) protectedFields(record *models.Record) []string {
+	if cfg.evaluate != nil {
+		return nil
+	}
+	if cfg.resolveFields != nil {
+		return cfg.resolveFields(record)
+	}
+
+	fields, ok := cfg.fieldsFor(record)
+	if !ok {
+		return nil
+	}
+
+	var protected []string
+	for _, name := range cfg.cmp.fieldsToCheck(record, fields) {
+		if cfg.cmp.changeViolates(name) {
+			protected = append(protected, name)
+		}
+	}
+	return protected
+}
+
+// graceStatus applies the grace period of field (see WithGracePeriods) to its freeze state:
+// the field isn't frozen before the period elapsed, and is frozen since the end of the period
+// when that is later than the freeze of the hook.
+func (cfg *hookConfig) graceStatus(record *models.Record, field string, info FreezeInfo, now time.Time) FreezeInfo {
+	if !info.Frozen || (cfg.gracePeriods == nil && cfg.defaultGracePeriod == 0) {
+		return info
+	}
+
+	period, ok := cfg.gracePeriods[field]
+	if !ok {
+		period = cfg.defaultGracePeriod
+	}
+	if !windowElapsed(record, cfg.ageSource(), period, now) {
+		return FreezeInfo{}
+	}
+
+	end := windowEnd(record, cfg.ageSource(), period)
+	if period == 0 || end == nil {
+		return info // frozen right away, or failed closed without a date to report
+	}
+
+	if info.Since == nil || info.Since.Time().Before(end.Time()) {
+		info.Since = end
+		if info.Reason == FreezeReasonImmutable {
+			info.Reason = FreezeReasonTimeWindow
+		}
+	}
+	return info
+}
+
+// dateTimeOf returns the non-zero date stored in the record field, or nil.
+func dateTimeOf(record *models.Record, field string) *types.DateTime {
+	if field == "" {
+		return nil
+	}
+
+	date := record.GetDateTime(field)
+	if date.IsZero() {
+		return nil
+	}
+	return &date
+}
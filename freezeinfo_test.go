@@ -0,0 +1,184 @@
+package pbimmutable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestFreezeStatus(t *testing.T) {
+	coll := &models.Collection{
+		Name: "orders",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "status", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "total", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "note", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "completedAt", Type: schema.FieldTypeDate},
+			&schema.SchemaField{Name: "deletedAt", Type: schema.FieldTypeDate},
+		),
+	}
+
+	created, _ := types.ParseDateTime("2024-01-01 10:00:00.000Z")
+	completedAt, _ := types.ParseDateTime("2024-02-01 10:00:00.000Z")
+
+	record := models.NewRecord(coll)
+	record.Set(models.SystemFieldCreated, created)
+	record.Set("status", "completed")
+	record.Set("total", 100)
+	record.Set("completedAt", completedAt)
+
+	inspect := func(build func(inspector Option)) *Inspector {
+		var inspector Inspector
+		build(WithInspector(&inspector))
+		return &inspector
+	}
+
+	t.Run("state and always frozen fields", func(t *testing.T) {
+		status := FreezeStatus(record,
+			inspect(func(o Option) { MakeImmutable("status", o) }),
+			inspect(func(o Option) {
+				MakeImmutable("total", "status", FreezeWhen("status", "completed"), WithFrozenSinceField("completedAt"), o)
+			}),
+			inspect(func(o Option) { MakeImmutable("note", FreezeWhen("status", "archived"), o) }),
+		)
+
+		if info := status["status"]; !info.Frozen || info.Reason != FreezeReasonImmutable || info.Since == nil || !info.Since.Time().Equal(created.Time()) {
+			t.Errorf("Expected status to be frozen since creation by the first hook, got %+v", info)
+		}
+		if info := status["total"]; !info.Frozen || info.Reason != FreezeReasonState || info.Since == nil || !info.Since.Time().Equal(completedAt.Time()) || info.Data["state"] != "completed" {
+			t.Errorf("Expected total to be frozen by the completed state, got %+v", info)
+		}
+		if info, ok := status["note"]; !ok || info.Frozen || info.Reason != "" {
+			t.Errorf("Expected note to be reported as not frozen, got %+v", info)
+		}
+	})
+
+	t.Run("frozen hook overrides a previous not frozen state", func(t *testing.T) {
+		status := FreezeStatus(record,
+			inspect(func(o Option) { MakeImmutable("total", FreezeWhen("status", "archived"), o) }),
+			inspect(func(o Option) { MakeImmutable("total", FreezeWhen("status", "completed"), o) }),
+		)
+
+		if info := status["total"]; !info.Frozen || info.Since != nil {
+			t.Errorf("Expected total to be frozen without since, got %+v", info)
+		}
+	})
+
+	t.Run("condition of another constructor", func(t *testing.T) {
+		status := FreezeStatus(record, inspect(func(o Option) {
+			MakeImmutableForSource(SourceRule{Field: "note", FreezeMissing: true}, "total", FreezeWhen("status", "archived"), o)
+		}))
+
+		if info := status["total"]; !info.Frozen || info.Reason != FreezeReasonCondition {
+			t.Errorf("Expected total to be frozen by the source condition, got %+v", info)
+		}
+	})
+
+	t.Run("grace periods", func(t *testing.T) {
+		periods := map[string]time.Duration{"note": time.Hour}
+
+		recent := record.CleanCopy()
+		recent.Set(models.SystemFieldCreated, types.NowDateTime())
+
+		status := FreezeStatus(recent, inspect(func(o Option) { MakeImmutable("note", "total", WithGracePeriods(periods, 0), o) }))
+		if info := status["note"]; info.Frozen {
+			t.Errorf("Expected note not to be frozen within its grace period, got %+v", info)
+		}
+		if info := status["total"]; !info.Frozen || info.Reason != FreezeReasonImmutable {
+			t.Errorf("Expected total to be frozen right away, got %+v", info)
+		}
+
+		status = FreezeStatus(record, inspect(func(o Option) { MakeImmutable("note", "total", WithGracePeriods(periods, 0), o) }))
+		if info := status["note"]; !info.Frozen || info.Reason != FreezeReasonTimeWindow || info.Since == nil || !info.Since.Time().Equal(created.Time().Add(time.Hour)) {
+			t.Errorf("Expected note to be frozen since the end of its grace period, got %+v", info)
+		}
+
+		status = FreezeStatus(record, inspect(func(o Option) {
+			MakeImmutable("total", FreezeWhen("status", "completed"), WithFrozenSinceField("completedAt"), WithGracePeriods(nil, 24*time.Hour), o)
+		}))
+		if info := status["total"]; !info.Frozen || info.Reason != FreezeReasonState || !info.Since.Time().Equal(completedAt.Time()) {
+			t.Errorf("Expected the later state freeze to be reported, got %+v", info)
+		}
+	})
+
+	t.Run("age source field", func(t *testing.T) {
+		status := FreezeStatus(record, inspect(func(o Option) {
+			MakeImmutable("note", WithGracePeriods(nil, time.Hour), WithAgeSourceField("completedAt"), o)
+		}))
+		if info := status["note"]; !info.Frozen || info.Since == nil || !info.Since.Time().Equal(completedAt.Time().Add(time.Hour)) {
+			t.Errorf("Expected note to be frozen an hour after completion, got %+v", info)
+		}
+
+		status = FreezeStatus(record, inspect(func(o Option) {
+			MakeImmutable("note", WithGracePeriods(nil, time.Hour), WithAgeSourceField("deletedAt"), o)
+		}))
+		if info := status["note"]; !info.Frozen || info.Reason != FreezeReasonImmutable {
+			t.Errorf("Expected note to fail closed without an age source value, got %+v", info)
+		}
+	})
+
+	t.Run("soft delete", func(t *testing.T) {
+		inspector := inspect(func(o Option) { MakeSoftDeleteImmutable(SoftDeleteRule{MutableFields: []string{"note"}}, o) })
+
+		status := FreezeStatus(record, inspector)
+		if _, ok := status["deletedAt"]; ok {
+			t.Errorf("Expected the soft delete field not to be reported")
+		}
+		if _, ok := status["note"]; ok {
+			t.Errorf("Expected mutable fields not to be reported")
+		}
+		if info := status["total"]; info.Frozen {
+			t.Errorf("Expected total not to be frozen before soft deletion, got %+v", info)
+		}
+
+		deleted := record.CleanCopy()
+		deleted.Set("deletedAt", completedAt)
+
+		status = FreezeStatus(deleted, inspector)
+		if info := status["total"]; !info.Frozen || info.Reason != FreezeReasonSoftDeleted || info.Since == nil {
+			t.Errorf("Expected total to be frozen by soft deletion, got %+v", info)
+		}
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		lockColl := &models.Collection{
+			Name: "locked_orders",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{Name: "total", Type: schema.FieldTypeNumber},
+				&schema.SchemaField{Name: DefaultLockedByField, Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: DefaultLockedAtField, Type: schema.FieldTypeDate},
+			),
+		}
+
+		locked := models.NewRecord(lockColl)
+		locked.Set(DefaultLockedByField, "user1")
+		locked.Set(DefaultLockedAtField, completedAt)
+
+		status := FreezeStatus(locked, inspect(func(o Option) {
+			MakeLockOnSeal(LockRule{Seals: func(*models.Record) bool { return true }}, "total", o)
+		}))
+		if len(status) != 3 {
+			t.Errorf("Expected the lock fields to be reported as well, got %v", status)
+		}
+		if info := status["total"]; !info.Frozen || info.Reason != FreezeReasonLocked || info.Data["lockedBy"] != "user1" {
+			t.Errorf("Expected total to be frozen by the lock, got %+v", info)
+		}
+	})
+
+	t.Run("hooks without freeze state", func(t *testing.T) {
+		status := FreezeStatus(record,
+			inspect(func(o Option) { MakeMonotonic("total", Increasing, o) }),
+			inspect(func(o Option) { MakeImmutable(o, 42) }),
+			&Inspector{},
+			nil,
+		)
+		if len(status) != 0 {
+			t.Errorf("Expected no fields to be reported, got %v", status)
+		}
+	})
+}
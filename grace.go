@@ -0,0 +1,108 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// WithGracePeriods keeps each frozen field editable for its own period after the record
+// was created, e.g. a title for an hour while the slug is frozen right away:
+//
+//	MakeImmutable("title", "slug", WithGracePeriods(map[string]time.Duration{"title": time.Hour}, 0))
+//
+// Fields without an entry use defaultPeriod, where 0 freezes them immediately. As with
+// MakeImmutableAfter, the periods are computed from the "created" timestamp of the original
+// record (or the field set with WithAgeSourceField), and a record without a valid value
+// has all its fields frozen.
+//
+// Every field of periods must be one of the frozen fields of the hook.
+func WithGracePeriods(periods map[string]time.Duration, defaultPeriod time.Duration) Option {
+	return func(cfg *hookConfig) {
+		if defaultPeriod < 0 {
+			cfg.setupErr = fmt.Errorf("pbimmutable.%s: the default grace period must not be negative, got %s", cfg.name, defaultPeriod)
+			return
+		}
+		for field, period := range periods {
+			if period < 0 {
+				cfg.setupErr = fmt.Errorf("pbimmutable.%s: the grace period of field %q must not be negative, got %s", cfg.name, field, period)
+				return
+			}
+			if len(cfg.fields) > 0 && !containsString(cfg.fields, field) {
+				cfg.setupErr = fmt.Errorf("pbimmutable.%s: grace period set for field %q, which is not frozen", cfg.name, field)
+				return
+			}
+		}
+
+		if len(cfg.fields) == 0 {
+			// all user-defined fields are frozen, so the names can only be checked against the schema
+			names := make([]string, 0, len(periods))
+			for field := range periods {
+				names = append(names, field)
+			}
+			sort.Strings(names)
+			cfg.conditionFields = append(cfg.conditionFields, names...)
+		}
+
+		cfg.gracePeriods = periods
+		cfg.defaultGracePeriod = defaultPeriod
+		cfg.agesRecords = true
+	}
+}
+
+// measuresAge marks a hook that measures the record age itself (see MakeImmutableAfter),
+// so that WithAgeSourceField applies to it without WithGracePeriods.
+func measuresAge() Option {
+	return func(cfg *hookConfig) {
+		cfg.agesRecords = true
+	}
+}
+
+// WithAgeSourceField measures the grace periods of WithGracePeriods (or the window of
+// MakeImmutableAfter) from the date stored in field instead of the "created" timestamp,
+// e.g. an "effectiveDate" business date. The value is read from the original record and
+// parsed as a date. A record whose value is missing or can't be parsed has all its fields
+// frozen (fail closed). Without grace periods or a window, it is a setup error.
+func WithAgeSourceField(field string) Option {
+	return func(cfg *hookConfig) {
+		if field == "" {
+			cfg.setupErr = fmt.Errorf("pbimmutable.%s: the age source field must not be empty", cfg.name)
+			return
+		}
+
+		cfg.ageSourceField = field
+		cfg.conditionFields = append(cfg.conditionFields, field)
+	}
+}
+
+// ageSource returns the field the grace periods are measured from.
+func (cfg *hookConfig) ageSource() string {
+	if cfg.ageSourceField != "" {
+		return cfg.ageSourceField
+	}
+	return models.SystemFieldCreated
+}
+
+// applyGracePeriods lifts the violations of the fields whose grace period (see WithGracePeriods)
+// hasn't elapsed at now.
+func (cfg *hookConfig) applyGracePeriods(original *models.Record, changes ChangeSet, now time.Time) {
+	if cfg.gracePeriods == nil && cfg.defaultGracePeriod == 0 {
+		return
+	}
+
+	for i, change := range changes {
+		if !change.Violated {
+			continue
+		}
+
+		period, ok := cfg.gracePeriods[change.Field]
+		if !ok {
+			period = cfg.defaultGracePeriod
+		}
+		if !windowElapsed(original, cfg.ageSource(), period, now) {
+			changes[i].Violated = false
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestWithGracePeriods(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "grace_test")
+	initialRecord.Set("status", "draft")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	hour := map[string]time.Duration{"name": time.Hour}
+
+	tests := []struct {
+		name                string
+		args                []interface{}
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"field within its period", []interface{}{"name", "status", WithGracePeriods(hour, 0)}, map[string]interface{}{"name": "renamed"}, ""},
+		{"field without period is frozen immediately", []interface{}{"name", "status", WithGracePeriods(hour, 0)}, map[string]interface{}{"status": "done"}, "Attempt to modify immutable field 'status'."},
+		{"default period", []interface{}{"name", "status", WithGracePeriods(nil, time.Hour)}, map[string]interface{}{"status": "done"}, ""},
+		{"elapsed period", []interface{}{"name", WithGracePeriods(map[string]time.Duration{"name": 0}, time.Hour)}, map[string]interface{}{"name": "renamed"}, "Attempt to modify immutable field 'name'."},
+		{"all fields", []interface{}{WithGracePeriods(hour, 0)}, map[string]interface{}{"name": "renamed", "value": 200}, "Attempt to modify immutable field 'value'."},
+		{"field not frozen", []interface{}{"status", WithGracePeriods(hour, 0)}, map[string]interface{}{}, `grace period set for field "name", which is not frozen`},
+		{"unknown field with all fields", []interface{}{WithGracePeriods(map[string]time.Duration{"nmae": time.Hour}, 0)}, map[string]interface{}{}, `unknown fields "nmae"`},
+		{"negative period", []interface{}{"name", WithGracePeriods(map[string]time.Duration{"name": -time.Minute}, 0)}, map[string]interface{}{}, `the grace period of field "name" must not be negative, got -1m0s`},
+		{"negative default period", []interface{}{"name", WithGracePeriods(nil, -time.Minute)}, map[string]interface{}{}, "the default grace period must not be negative, got -1m0s"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable(tc.args...)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithAgeSourceField(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	contracts := &models.Collection{
+		Name: "test_contracts",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "effectiveDate", Type: schema.FieldTypeDate},
+			&schema.SchemaField{Name: "note", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(contracts); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newContract := func(effective time.Time) *models.Record {
+		record := models.NewRecord(contracts)
+		record.Set("title", "contract")
+		if !effective.IsZero() {
+			record.Set("effectiveDate", effective)
+		}
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save contract: %v", err)
+		}
+		return record
+	}
+
+	// the records are all created now, so only the effective date decides
+	future := newContract(time.Now().Add(24 * time.Hour))
+	past := newContract(time.Now().Add(-2 * time.Hour))
+	missing := newContract(time.Time{})
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		source              string
+		expectErrorContains string
+	}{
+		{"created within the period", past, "", ""},
+		{"effective date within the period", future, "effectiveDate", ""},
+		{"effective date period elapsed", past, "effectiveDate", "Attempt to modify immutable field 'title'."},
+		{"missing effective date fails closed", missing, "effectiveDate", "Attempt to modify immutable field 'title'."},
+		{"unknown source field", past, "effective_date", `unknown fields "effective_date" in collection test_contracts`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := []interface{}{"title", WithGracePeriods(nil, time.Hour)}
+			if tc.source != "" {
+				args = append(args, WithAgeSourceField(tc.source))
+			}
+			hookFunc := MakeImmutable(args...)
+
+			err := hookFunc(newUpdateEvent(app, tc.original, map[string]interface{}{"title": "renamed"}))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("source field without grace periods", func(t *testing.T) {
+		err := MakeImmutable("title", WithAgeSourceField("effectiveDate"))(newUpdateEvent(app, past, nil))
+		if err == nil || !strings.Contains(err.Error(), `the age source field "effectiveDate" is only used with grace periods or a time window`) {
+			t.Errorf("Expected a setup error, got: %v", err)
+		}
+	})
+
+	t.Run("empty source field", func(t *testing.T) {
+		err := MakeImmutable("title", WithAgeSourceField(""))(newUpdateEvent(app, past, nil))
+		if err == nil || !strings.Contains(err.Error(), "the age source field must not be empty") {
+			t.Errorf("Expected a setup error, got: %v", err)
+		}
+	})
+}
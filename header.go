@@ -0,0 +1,48 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// WithOverrideHeader allows changes to all frozen fields when the request carries the named
+// header with a true value ("true", "1", "t" and their upper case forms, as parsed by
+// strconv.ParseBool), e.g. WithOverrideHeader("X-Allow-Immutable-Override") for the
+// privileged operations of a trusted gateway. Other values, like "false" or "yes",
+// and a missing header don't unlock anything.
+//
+// Any client can send any header, so only use it behind a gateway that strips or validates
+// the header of incoming requests. Events without an HTTP request never match.
+func WithOverrideHeader(name string) Option {
+	return func(cfg *hookConfig) {
+		if strings.TrimSpace(name) == "" {
+			if cfg.setupErr == nil {
+				cfg.setupErr = fmt.Errorf("pbimmutable.%s: the override header name must not be empty", cfg.name)
+			}
+			return
+		}
+
+		cfg.refiners = append(cfg.refiners, func(e *core.RecordEvent, changes ChangeSet) {
+			if !headerIsTrue(e, name) {
+				return
+			}
+
+			for i := range changes {
+				changes[i].Violated = false
+			}
+		})
+	}
+}
+
+// headerIsTrue reports whether the event request carries the header with a true value.
+func headerIsTrue(e *core.RecordEvent, name string) bool {
+	if e.HttpContext == nil || e.HttpContext.Request() == nil {
+		return false
+	}
+
+	value, err := strconv.ParseBool(strings.TrimSpace(e.HttpContext.Request().Header.Get(name)))
+	return err == nil && value
+}
@@ -0,0 +1,84 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithOverrideHeader(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "header_test")
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	const header = "X-Allow-Immutable-Override"
+	updates := map[string]interface{}{"name": "changed", "status": "inactive"}
+
+	newEvent := func(value string) *core.RecordEvent {
+		event := newRequestUpdateEvent(app, initialRecord, updates, &models.RequestInfo{})
+		if value != "" {
+			event.HttpContext.Request().Header.Set(header, value)
+		}
+		return event
+	}
+
+	tests := []struct {
+		name                string
+		event               *core.RecordEvent
+		expectErrorContains string
+	}{
+		{"true", newEvent("true"), ""},
+		{"1", newEvent("1"), ""},
+		{"upper case", newEvent(" TRUE "), ""},
+		{"absent", newEvent(""), "Attempt to modify 2 immutable fields: name, status."},
+		{"false", newEvent("false"), "Attempt to modify 2 immutable fields: name, status."},
+		{"not a bool", newEvent("yes"), "Attempt to modify 2 immutable fields: name, status."},
+		{"non-HTTP event", newUpdateEvent(app, initialRecord, updates), "Attempt to modify 2 immutable fields: name, status."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableWith(ImmutableConfig{Fields: []string{"name", "status"}, OverrideHeader: header})
+
+			err := hookFunc(tc.event)
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		err := MakeImmutable("name", "status")(newEvent("true"))
+		if err == nil {
+			t.Errorf("Expected the header to be ignored without the option")
+		}
+	})
+
+	t.Run("empty header name", func(t *testing.T) {
+		err := MakeImmutable("name", WithOverrideHeader(" "))(newEvent("true"))
+		if err == nil || !strings.Contains(err.Error(), "pbimmutable.MakeImmutable: the override header name must not be empty") {
+			t.Errorf("Expected a setup error, got: %v", err)
+		}
+	})
+
+	t.Run("empty header name keeps an earlier setup error", func(t *testing.T) {
+		err := MakeImmutable("name", WithGracePeriods(nil, -time.Minute), WithOverrideHeader(""))(newEvent("true"))
+		if err == nil || !strings.Contains(err.Error(), "the default grace period must not be negative") {
+			t.Errorf("Expected the earlier setup error, got: %v", err)
+		}
+	})
+}
@@ -1,134 +1,625 @@
 package pbimmutable
 
 import (
-	"errors" // Added for errors.New
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"reflect"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 )
 
 // MakeImmutable returns a hook function that prevents changes to specified fields of a record.
 // It can also take an optional callback function of type `func(e *core.RecordEvent) error`.
-// This callback is executed if all immutability checks pass.
-// The overall database transaction for the update operation commits only if:
+// This callback is executed if all immutability checks pass, right before e.Next().
+// The update is saved only if:
 // 1. All immutability checks pass.
 // 2. The provided callback function (if any) also returns nil.
 // If any of these conditions fail (e.g., an immutable field is changed, or the callback returns an error),
-// the entire transaction is rolled back.
+// e.Next() isn't called and the surrounding transaction, if any, is rolled back.
+// Pass WithRunAfterCommit() to run the callback after e.Next() instead.
+//
+// The callback can also be of type `func(e *core.RecordEvent, original *models.Record) error`
+// to receive the original record the checks compared against (it is not fetched again).
+// The original record is nil for an upsert that creates the record with a client provided id:
+// such a record isn't stored yet, so there is nothing to compare and the update proceeds.
 //
 // Usage examples:
 // MakeImmutable("field1", "field2") // Only immutable fields
 // MakeImmutable("field1", myCallback) // Immutable field and a callback
 // MakeImmutable(myCallback)          // All user-defined fields immutable, and a callback
 // MakeImmutable()                    // All user-defined fields immutable, no callback
+//
+// Options (see Option) can be mixed with the field names and the callback.
+// See MakeImmutableWith for a typed alternative of the variadic arguments.
 func MakeImmutable(args ...interface{}) func(e *core.RecordEvent) error {
-	var immutableFieldNames []string
-	var userCallback func(e *core.RecordEvent) error
-	var parseError error
+	return newHook(parseArgs("MakeImmutable", args))
+}
+
+// hookConfig holds the parsed arguments shared by the hook constructors of this package.
+type hookConfig struct {
+	name     string // constructor name, used in setup error messages
+	fields   []string
+	callback func(e *core.RecordEvent, original *models.Record) error
+	setupErr error
+
+	// callbackTakesOriginal reports that the callback reads the original record
+	// (see ImmutableConfig.OriginalCallback).
+	callbackTakesOriginal bool
+
+	// skipOriginal skips the fetch of the original record and the checks,
+	// for hooks that only run their callback (see Passthrough).
+	skipOriginal bool
+
+	// runAfterCommit runs the callback after e.Next() instead of right before it
+	// (see ImmutableConfig.RunAfterCommit).
+	runAfterCommit bool
+
+	// propagatePanics disables the recovery of callback and e.Next() panics
+	// (see WithoutPanicRecovery).
+	propagatePanics bool
+
+	// condition, when set, is evaluated against the original record
+	// and the fields are only frozen if it returns true.
+	condition func(original *models.Record) bool
+
+	// conditionFields lists the fields read by the condition, which are validated
+	// like the frozen fields (see FreezeWhen).
+	conditionFields []string
+
+	// gracePeriods and defaultGracePeriod keep the fields editable for a while after
+	// the record was created (see WithGracePeriods).
+	gracePeriods       map[string]time.Duration
+	defaultGracePeriod time.Duration
+
+	// ageSourceField is the date field the grace periods are measured from
+	// (see WithAgeSourceField). Empty means "created".
+	ageSourceField string
+
+	// agesRecords is set when the hook measures the record age (see measuresAge).
+	agesRecords bool
+
+	// checkNew evaluates records that aren't stored yet (e.g. in create hooks) with a nil
+	// original record, instead of letting them pass (see MakePinned).
+	checkNew bool
+
+	// cmp holds the value comparison settings used by the default immutability check.
+	cmp comparer
+
+	// collectionFields overrides fields for the listed collection names
+	// (see ImmutableConfig.CollectionFields).
+	collectionFields map[string][]string
+
+	// resolveFields, when set, computes the fields checked by the default immutability
+	// check from the pending record. An empty result means that nothing is checked.
+	resolveFields func(pending *models.Record) []string
+
+	// evaluate, when set, replaces the default immutability check of the fields.
+	// The pending record is e.Record.
+	evaluate func(e *core.RecordEvent, original *models.Record) (ChangeSet, error)
+
+	// override, when set, may accept the detected violations instead of rejecting the update.
+	// The optional returned inTx function is executed in the same transaction as e.Next().
+	override func(e *core.RecordEvent, violations ChangeSet) (allowed bool, inTx func(txDao *daos.Dao) error)
+
+	// refiners are registered by options and may adjust the evaluated changes
+	// (e.g. lift or annotate violations) before they are checked.
+	refiners []func(e *core.RecordEvent, changes ChangeSet)
+
+	// beforeNext functions run after all checks passed, right before e.Next(),
+	// and may still modify the pending record.
+	beforeNext []func(e *core.RecordEvent, original *models.Record) error
+
+	// messages holds the custom violation messages per field (see WithMessages).
+	messages map[string]string
+
+	// messageResolver, when set, produces the violation messages (see WithMessageResolver).
+	messageResolver func(e *core.RecordEvent, field string) string
+
+	// responder, when set, produces the error returned for rejected updates.
+	responder func(violations []Violation) error
+
+	// tracer, when set, traces the hook runs (see WithTracer).
+	tracer Tracer
+
+	// metrics, when set, receives the outcome of the hook runs (see WithMetrics).
+	metrics Metrics
+
+	// onViolation lists the functions called for rejected updates (see WithOnViolation).
+	onViolation []func(e *core.RecordEvent, fields []string) error
+
+	// optimisticConcurrency verifies in the transaction of e.Next() that the original
+	// record wasn't changed since the check (see WithOptimisticConcurrency).
+	optimisticConcurrency bool
+
+	// rejectUnknownFields rejects updates carrying fields unknown to the collection
+	// schema (see WithRejectUnknownFields).
+	rejectUnknownFields bool
+
+	// requestKeys lists the request body keys read by the hook besides the record fields,
+	// which WithRejectUnknownFields accepts (e.g. the justification key).
+	requestKeys []string
+
+	// forbiddenStatus rejects violations with a 403 instead of a 400 error (see WithForbiddenStatus).
+	forbiddenStatus bool
+
+	// logViolations enables the violation log (see WithViolationLog)
+	// and secretFields lists the fields whose values are redacted in it.
+	logViolations bool
+	secretFields  []string
+
+	// validated caches the field names validation per collection schema (see validateFields).
+	validated *sync.Map
+
+	// cacheId identifies the hook in the field cache (see resolvedFields).
+	cacheId uint64
+
+	// inspector exposes the final config of the hook (see WithInspector).
+	inspector *Inspector
+
+	// describeFreeze describes why the fields are frozen while condition holds (see freezeOn),
+	// and frozenSinceField stores when they were frozen (see WithFrozenSinceField).
+	describeFreeze   func(record *models.Record) FreezeInfo
+	frozenSinceField string
+}
+
+// parseArgs parses the variadic field names and optional callback accepted by MakeImmutable
+// and the other hook constructors.
+func parseArgs(name string, args []interface{}) hookConfig {
+	var config ImmutableConfig
+	var setupErr error
 
 	for i, arg := range args {
 		switch v := arg.(type) {
 		case string:
-			immutableFieldNames = append(immutableFieldNames, v)
+			config.Fields = append(config.Fields, v)
+		case Option:
+			config.Options = append(config.Options, v)
 		case func(e *core.RecordEvent) error:
-			if userCallback != nil {
-				parseError = errors.New("pbimmutable.MakeImmutable: only one callback function can be provided")
+			if config.Callback != nil || config.OriginalCallback != nil {
+				setupErr = fmt.Errorf("pbimmutable.%s: only one callback function can be provided", name)
 				break
 			}
-			userCallback = v
+			config.Callback = v
+		case func(e *core.RecordEvent, original *models.Record) error:
+			if config.Callback != nil || config.OriginalCallback != nil {
+				setupErr = fmt.Errorf("pbimmutable.%s: only one callback function can be provided", name)
+				break
+			}
+			config.OriginalCallback = v
 		default:
-			parseError = fmt.Errorf("pbimmutable.MakeImmutable: invalid argument type %T at position %d", arg, i)
+			setupErr = fmt.Errorf("pbimmutable.%s: invalid argument type %T at position %d", name, arg, i)
 			break
 		}
-		if parseError != nil {
+		if setupErr != nil {
 			break
 		}
 	}
 
+	// no field names means that all user-defined fields are frozen
+	config.FreezeAll = len(config.Fields) == 0
+
+	cfg := config.build(name)
+	if setupErr != nil {
+		cfg.setupErr = setupErr
+	}
+
+	return cfg
+}
+
+// newHook builds the hook function for the provided config.
+func newHook(cfg hookConfig) func(e *core.RecordEvent) error {
+	if cfg.inspector != nil {
+		inspected := cfg
+		cfg.inspector.cfg = &inspected
+	}
+
 	// The actual hook function returned
-	return func(e *core.RecordEvent) error {
-		if parseError != nil { // Return parsing error immediately if MakeImmutable was called incorrectly
-			return apis.NewBadRequestError(fmt.Sprintf("MakeImmutable setup error: %v", parseError), nil)
+	return func(e *core.RecordEvent) (err error) {
+		ctx, span := cfg.startSpan(eventContext(e), SpanHook)
+		if e.Record != nil {
+			span.SetAttribute("pbimmutable.collection", e.Record.Collection().Name)
+			span.SetAttribute("pbimmutable.record_id", e.Record.Id)
 		}
+		outcome := OutcomeRejected
+		defer func() {
+			if err == nil {
+				outcome = OutcomeAllowed
+			} else {
+				span.RecordError(err)
+			}
+			span.SetAttribute("pbimmutable.outcome", outcome)
+			span.End()
+		}()
 
-		if e.Record == nil {
-			return apis.NewBadRequestError("Record data is missing in the event.", nil)
+		checked, err := cfg.check(ctx, e, nil)
+		if err != nil {
+			return err
 		}
-		if e.App == nil {
-			return apis.NewBadRequestError("App context is missing in the event.", nil)
+		outcome = OutcomeError // the checks passed, so any later error is a commit or callback failure
+
+		if chain, ok := chainOf(e); ok {
+			// the chain runs the callbacks and calls e.Next() once all its hooks passed
+			chain.steps = append(chain.steps, commitStep{cfg: &cfg, ctx: ctx, checkResult: checked})
+			return nil
 		}
 
-		originalRecord, err := e.App.Dao().FindRecordById(e.Record.Collection().Id, e.Record.Id)
-		if err != nil {
-			return apis.NewBadRequestError(fmt.Sprintf("Failed to fetch original record %s from collection %s for immutability check.", e.Record.Id, e.Record.Collection().Name), err)
+		return commit(e, []commitStep{{cfg: &cfg, ctx: ctx, checkResult: checked}})
+	}
+}
+
+// checkResult holds the outcome of checks that passed.
+type checkResult struct {
+	original *models.Record // the original record the checks compared against
+	created  bool           // the update creates the record (an upsert with a client provided id)
+	inTx     func(txDao *daos.Dao) error
+}
+
+// commitStep holds the state of a hook run whose checks passed.
+type commitStep struct {
+	cfg *hookConfig
+	ctx context.Context
+	checkResult
+}
+
+// commit calls e.Next() after the checks of all steps passed and runs their callbacks.
+func commit(e *core.RecordEvent, steps []commitStep) error {
+	var inTxs []func(txDao *daos.Dao) error
+	for _, step := range steps {
+		if step.inTx != nil {
+			inTxs = append(inTxs, step.inTx)
 		}
+	}
 
-		fieldsToCheck := immutableFieldNames
-		if len(immutableFieldNames) == 0 {
-			// If no specific fields are provided, all non-system fields are considered immutable.
-			schemaFields := e.Record.Schema().Fields()
-			fieldsToCheck = make([]string, 0, len(schemaFields))
-			for _, field := range schemaFields {
-				if !isSystemField(field.Name) {
-					fieldsToCheck = append(fieldsToCheck, field.Name)
-				}
+	// By default the callbacks run right before e.Next(), so that their error aborts the update.
+	// With runAfterCommit they run once e.Next() has committed the record instead.
+	var callbackErr error
+	next := func() error {
+		for _, step := range steps {
+			if step.cfg.callback == nil || step.cfg.runAfterCommit {
+				continue
+			}
+			if callbackErr = step.cfg.runCallback(step.ctx, e, step.original); callbackErr != nil {
+				return callbackErr
+			}
+		}
+		for _, step := range steps {
+			if step.cfg.propagatePanics {
+				return e.Next()
 			}
 		}
+		return callRecovered(e, "e.Next()", e.Next)
+	}
 
-		for _, fieldName := range fieldsToCheck {
-			originalValue := originalRecord.Get(fieldName)
-			pendingValue := e.Record.Get(fieldName)
+	// Attempt to proceed with the main operation (e.g., database commit)
+	var err error
+	if len(inTxs) > 0 {
+		app := e.App
+		err = app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+			// e.Next() saves the record with e.App.Dao(), so it is swapped for the
+			// transaction: the save and the inTx writes are committed or rolled back together
+			e.App = &txApp{App: app, dao: txDao}
+			defer func() { e.App = app }()
 
-			if !reflect.DeepEqual(originalValue, pendingValue) {
-				if isSystemField(fieldName) && fieldName == models.SystemFieldUpdated {
-					continue
+			for _, inTx := range inTxs {
+				if err := inTx(txDao); err != nil {
+					return err
 				}
+			}
+			return next()
+		})
+	} else {
+		err = next()
+	}
+	if callbackErr != nil {
+		// The callback failed before e.Next(), so the record changes were not saved.
+		return fmt.Errorf("user callback failed, record changes were not saved: %w", callbackErr)
+	}
+	var conflictErr *ConcurrentUpdateError
+	if errors.As(err, &conflictErr) {
+		// the concurrency check rejected the update before e.Next() ran
+		return conflictErr
+	}
+	var rejected *rejectedInTx
+	if errors.As(err, &rejected) {
+		return rejected.err
+	}
+	if err != nil {
+		// If e.Next() fails, it implies the underlying operation (eg. DB save) failed.
+		return fmt.Errorf("failed to commit record changes via e.Next() after immutability checks: %w", err)
+	}
+	// If e.Next() succeeded, the main operation is now considered committed.
 
-				return apis.NewBadRequestError(
-					fmt.Sprintf("Attempt to modify immutable field '%s'.", fieldName),
-					map[string]any{
-						"field":    fieldName,
-						"reason":   "immutable",
-						"recordId": e.Record.Id,
-					},
-				)
+	// With runAfterCommit, the callbacks run AFTER the main record update has been
+	// successfully committed via e.Next().
+	for _, step := range steps {
+		if step.cfg.callback == nil || !step.cfg.runAfterCommit {
+			continue
+		}
+		if callbackErr = step.cfg.runCallback(step.ctx, e, step.original); callbackErr != nil {
+			if errors.Is(callbackErr, ErrRollbackRequested) {
+				return compensate(e, step.checkResult, callbackErr)
 			}
+			// The main record operation was committed. This error is from the subsequent user-defined callback.
+			// The API will report this callback error, but the record data was already saved.
+			// Consider logging this error or handling it in a way that acknowledges the main commit succeeded.
+			return fmt.Errorf("user callback failed AFTER record commit: %w", callbackErr)
 		}
+	}
 
-		// If we've reached here, all immutability checks passed.
+	return nil // Signifies success of the hooks and their callbacks.
+}
+
+// rejectedInTx wraps the rejection of an update by a check that can only be
+// completed in the transaction of e.Next() (e.g. the consumption of an approval).
+// commit returns the wrapped error as it is.
+type rejectedInTx struct {
+	err error
+}
 
-		// Attempt to proceed with the main operation (e.g., database commit)
-		err = e.Next() // This line assumes 'e' has a Next() method.
+func (r *rejectedInTx) Error() string {
+	return r.err.Error()
+}
+
+func (r *rejectedInTx) Unwrap() error {
+	return r.err
+}
+
+// txApp is the app of an event while its save runs in a transaction:
+// Dao() returns the transaction dao instead of the app one.
+type txApp struct {
+	core.App
+	dao *daos.Dao
+}
+
+// Dao returns the transaction dao.
+func (app *txApp) Dao() *daos.Dao {
+	return app.dao
+}
+
+// runCallback runs the user callback in its own span.
+func (cfg *hookConfig) runCallback(ctx context.Context, e *core.RecordEvent, original *models.Record) error {
+	_, span := cfg.startSpan(ctx, SpanCallback)
+	defer span.End()
+
+	var err error
+	if cfg.propagatePanics {
+		err = cfg.callback(e, original)
+	} else {
+		err = callRecovered(e, "callback", func() error { return cfg.callback(e, original) })
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// check runs all checks of the hook for the event and applies the pre-commit record changes.
+// The original record is fetched with dao, which the evaluators also read with (through
+// e.App.Dao()). When dao is nil, the original state tracked by the record (see trackedOriginal)
+// is used, falling back to a fetch with e.App.Dao().
+// It returns the original record and the optional function that must be executed
+// in the same transaction as the record save.
+//
+// A record that isn't stored yet (e.g. an upsert creating the record with a client provided
+// id) is treated as a create: nothing is compared, the returned original record is nil
+// and the result is flagged as created.
+func (cfg *hookConfig) check(ctx context.Context, e *core.RecordEvent, dao *daos.Dao) (checkResult, error) {
+	if err := cfg.checkEvent(e); err != nil {
+		return checkResult{}, err
+	}
+
+	// while enforcement is turned off globally (see SetEnabled), nothing is rejected,
+	// but the record maintenance of the hooks (cfg.beforeNext) still runs
+	enforced := Enabled()
+
+	if cfg.rejectUnknownFields && enforced {
+		if err := cfg.unknownFieldsError(e); err != nil {
+			return checkResult{}, err
+		}
+	}
+
+	if cfg.skipOriginal {
+		cfg.recordAllowed(e.Record.Collection().Name)
+		return checkResult{}, nil
+	}
+
+	ctx, checkSpan := cfg.startSpan(ctx, SpanCheck)
+	defer checkSpan.End()
+
+	// prefer the original state tracked by the record itself and only hit the database without it
+	// (an explicit dao is only passed for transactions that must see their own earlier writes)
+	var originalRecord *models.Record
+	var err error
+	if dao == nil {
+		originalRecord = trackedOriginal(e.Record)
+		dao = e.App.Dao()
+	} else if dao != e.App.Dao() {
+		// the evaluators read with e.App.Dao(), so it is swapped for the explicit dao
+		// while the checks run: their lookups see the earlier writes of its transaction too
+		app := e.App
+		e.App = &txApp{App: app, dao: dao}
+		defer func() { e.App = app }()
+	}
+
+	// a new record has no original to fetch, its evaluation gets a nil original instead
+	isNew := cfg.checkNew && e.Record.IsNew()
+
+	if originalRecord == nil && !isNew {
+		fetchCtx, fetchSpan := cfg.startSpan(ctx, SpanFetch)
+		originalRecord, err = dao.FindRecordById(e.Record.Collection().Id, e.Record.Id, func(q *dbx.SelectQuery) error {
+			// abort the query when the request is cancelled or times out
+			q.WithContext(fetchCtx)
+			return nil
+		})
+		fetchSpan.End()
 		if err != nil {
-			// If e.Next() fails, it implies the underlying operation (eg. DB save) failed.
-			return fmt.Errorf("failed to commit record changes via e.Next() after immutability checks: %w", err)
-		}
-		// If e.Next() succeeded, the main operation is now considered committed.
-
-		// Now, if a user callback was provided, execute it.
-		// This callback runs AFTER the main record update has been successfully committed via e.Next().
-		if userCallback != nil {
-			if callbackErr := userCallback(e); callbackErr != nil {
-				// The main record operation was committed. This error is from the subsequent user-defined callback.
-				// The API will report this callback error, but the record data was already saved.
-				// Consider logging this error or handling it in a way that acknowledges the main commit succeeded.
-				return fmt.Errorf("user callback failed AFTER record commit: %w", callbackErr)
+			if ctxErr := fetchCtx.Err(); ctxErr != nil {
+				return checkResult{}, fetchCancelledError(e, ctxErr)
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				// an upsert with a client provided id creates the record, so there is nothing to protect yet
+				cfg.recordAllowed(e.Record.Collection().Name)
+				return checkResult{created: true}, nil
 			}
+			return checkResult{}, apis.NewBadRequestError(fmt.Sprintf("Failed to fetch original record %s from collection %s for immutability check.", e.Record.Id, e.Record.Collection().Name), err)
 		}
+	}
 
-		return nil // Signifies success of this hook and the post-commit callback.
+	var inTx func(txDao *daos.Dao) error
+	if enforced && (cfg.condition == nil || isNew || cfg.condition(originalRecord)) {
+		var changes ChangeSet
+		switch {
+		case cfg.evaluate != nil:
+			changes, err = cfg.evaluate(e, originalRecord)
+			if err != nil {
+				return checkResult{}, err
+			}
+		case cfg.resolveFields != nil:
+			if fields := cfg.resolveFields(e.Record); len(fields) > 0 {
+				changes = cfg.cmp.evaluate(originalRecord, e.Record, fields)
+			}
+		default:
+			if fields, ok := cfg.resolvedFields(e.Record); ok {
+				changes = cfg.cmp.compare(originalRecord, e.Record, fields)
+			}
+		}
+		if !isNew {
+			cfg.applyGracePeriods(originalRecord, changes, time.Now())
+		}
+		for _, refine := range cfg.refiners {
+			refine(e, changes)
+		}
+		if violations := changes.Violations(); len(violations) > 0 {
+			allowed := false
+			if cfg.override != nil {
+				allowed, inTx = cfg.override(e, violations)
+			}
+			if !allowed {
+				return checkResult{}, cfg.rejectError(e, violations)
+			}
+		}
 	}
+
+	// If we've reached here, all immutability checks passed.
+
+	for _, fn := range cfg.beforeNext {
+		if err := fn(e, originalRecord); err != nil {
+			return checkResult{}, err
+		}
+	}
+
+	if cfg.optimisticConcurrency && enforced && originalRecord != nil {
+		inTx = withVersionCheck(e, originalRecord, inTx)
+	}
+
+	cfg.recordAllowed(e.Record.Collection().Name)
+
+	return checkResult{original: originalRecord, inTx: inTx}, nil
 }
 
-// isSystemField checks if a field name is one of PocketBase's system fields.
-func isSystemField(fieldName string) bool {
-	switch fieldName {
-	case models.SystemFieldId, models.SystemFieldCreated, models.SystemFieldUpdated, models.SystemFieldCollectionId, models.SystemFieldCollectionName, models.SystemFieldExpand:
-		return true
-	default:
-		return false
+// statusClientClosedRequest is the non-standard status used for requests cancelled by the client.
+const statusClientClosedRequest = 499
+
+// checkEvent reports the setup errors of the hook and the events that can't be checked,
+// e.g. because the record or the app is missing.
+func (cfg *hookConfig) checkEvent(e *core.RecordEvent) error {
+	if cfg.setupErr != nil { // Return parsing error immediately if the hook was configured incorrectly
+		return apis.NewBadRequestError(fmt.Sprintf("%s setup error: %v", cfg.name, cfg.setupErr), nil)
+	}
+
+	if e.Record == nil {
+		return apis.NewBadRequestError("Record data is missing in the event.", nil)
 	}
+	if e.App == nil {
+		return apis.NewBadRequestError("App context is missing in the event.", nil)
+	}
+
+	if err := cfg.validateFields(e.Record); err != nil {
+		return apis.NewBadRequestError(fmt.Sprintf("%s setup error: pbimmutable.%s: %v", cfg.name, cfg.name, err), nil)
+	}
+
+	return nil
+}
+
+// fetchCancelledError returns the error for an original record fetch aborted by the
+// request context: 504 when the request timed out, 499 when the client cancelled it.
+func fetchCancelledError(e *core.RecordEvent, ctxErr error) error {
+	status := statusClientClosedRequest
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	}
+
+	return apis.NewApiError(status, fmt.Sprintf("Fetching original record %s from collection %s for immutability check was aborted.", e.Record.Id, e.Record.Collection().Name), ctxErr)
+}
+
+// ViolationCode is the validation error code of every violated field in the error data
+// returned by the hooks. It is stable, so clients can match on it.
+const ViolationCode = "immutable"
+
+// violationError converts the violated changes into the ImmutableFieldError returned by the hook.
+//
+// The error data is a validation.Errors with one entry per violated field, so that the admin UI
+// and the SDKs attach each message to its field, like for PocketBase's own validation errors.
+func violationError(e *core.RecordEvent, violations ChangeSet, forbidden bool) error {
+	fields := make([]string, len(violations))
+	data := make(validation.Errors, len(violations))
+	for i, change := range violations {
+		fields[i] = change.Field
+		data[change.Field] = validation.NewError(ViolationCode, changeMessage(change))
+	}
+
+	message := changeMessage(violations[0])
+	if len(violations) > 1 {
+		message = fmt.Sprintf("Attempt to modify %d immutable fields: %s.", len(violations), strings.Join(fields, ", "))
+	}
+
+	apiErr := apis.NewBadRequestError(message, data)
+	if forbidden {
+		apiErr = apis.NewForbiddenError(message, data)
+	}
+
+	return &ImmutableFieldError{
+		Collection: e.Record.Collection().Name,
+		RecordId:   e.Record.Id,
+		Fields:     fields,
+		Violations: violations,
+		apiErr:     apiErr,
+	}
+}
+
+// changeMessage returns the human readable message of a violated change.
+func changeMessage(change Change) string {
+	if change.Message != "" {
+		return change.Message
+	}
+	return fmt.Sprintf("Attempt to modify immutable field '%s'.", change.Field)
+}
+
+// IsSystemField checks if a field name is one of PocketBase's system fields
+// (id, created, updated, collectionId, collectionName and expand).
+//
+// The hooks never treat these fields as user-defined fields. Additional fields can be
+// treated the same way per hook with WithSystemFields.
+func IsSystemField(fieldName string) bool {
+	return containsString(systemFieldNames, fieldName)
+}
+
+// systemFieldNames lists the PocketBase system fields (see IsSystemField).
+var systemFieldNames = []string{
+	models.SystemFieldId,
+	models.SystemFieldCreated,
+	models.SystemFieldUpdated,
+	models.SystemFieldCollectionId,
+	models.SystemFieldCollectionName,
+	models.SystemFieldExpand,
 }
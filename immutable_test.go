@@ -1,18 +1,26 @@
 package pbimmutable
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tests"
 )
 
 // Helper to setup a test app and collection
-func setupTestAppWithCollection(t *testing.T) (core.App, *models.Collection, func()) {
+func setupTestAppWithCollection(t testing.TB) (core.App, *models.Collection, func()) {
 	testApp, err := tests.NewTestApp() // Assumes go.mod is in the current or parent directory
 	if err != nil {
 		t.Fatalf("Failed to init test app: %v", err)
@@ -38,6 +46,37 @@ func setupTestAppWithCollection(t *testing.T) (core.App, *models.Collection, fun
 	}
 }
 
+// Helper to build an update event for an already saved record with the given changes applied.
+func newUpdateEvent(app core.App, original *models.Record, updates map[string]interface{}) *core.RecordEvent {
+	eventRecord := original.CleanCopy()
+	for k, v := range updates {
+		eventRecord.Set(k, v)
+	}
+
+	return &core.RecordEvent{
+		App:    app,
+		Record: eventRecord,
+	}
+}
+
+// Helper to build an update event that was triggered by an HTTP request with the given request info.
+func newRequestUpdateEvent(app core.App, original *models.Record, updates map[string]interface{}, info *models.RequestInfo) *core.RecordEvent {
+	event := newUpdateEvent(app, original, updates)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/collections/"+original.Collection().Name+"/records/"+original.Id, nil)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.Set(apis.ContextRequestInfoKey, info)
+	if info.AuthRecord != nil {
+		c.Set(apis.ContextAuthRecordKey, info.AuthRecord)
+	}
+	if info.Admin != nil {
+		c.Set(apis.ContextAdminKey, info.Admin)
+	}
+	event.HttpContext = c
+
+	return event
+}
+
 // NOTE ON TESTING e.Next():
 // The MakeImmutable function's hook internally calls `e.Next()`.
 // Standard `*core.RecordEvent` does not have a `Next()` method.
@@ -47,7 +86,8 @@ func setupTestAppWithCollection(t *testing.T) (core.App, *models.Collection, fun
 // (argument parsing, immutability checks) and the intended logic for the callback
 // *assuming* `e.Next()` behaves as expected. Fully testing the `e.Next()`
 // interaction and post-commit callback behavior requires an event `e` that
-// matches the one in the user's specific runtime environment.
+// matches the one in the user's specific runtime environment, e.g. one run through
+// a hook chain (see app.OnRecordUpdate(...).Trigger and the pbimmutabletest package).
 
 func TestMakeImmutable_ArgumentParsing(t *testing.T) {
 	tests := []struct {
@@ -319,3 +359,368 @@ func TestMakeImmutable_CallbackExecutionLogic(t *testing.T) {
 		}
 	})
 }
+
+func TestMakeImmutable_CascadingRollback(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	var records []*models.Record
+	for _, name := range []string{"parent", "child_1", "child_2"} {
+		record := models.NewRecord(coll)
+		record.Set("name", name)
+		record.Set("status", "open")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	hookFunc := MakeImmutable("name")
+
+	// The parent and the first child only change their status,
+	// while the last cascaded update tries to rename the second child.
+	updates := []map[string]interface{}{
+		{"status": "closed"},
+		{"status": "closed"},
+		{"status": "closed", "name": "renamed"},
+	}
+
+	err := app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		for i, record := range records {
+			event := newUpdateEvent(app, record, updates[i])
+			if err := hookFunc(event); err != nil {
+				return err
+			}
+			if err := txDao.SaveRecord(event.Record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+		t.Fatalf("Expected the late violation to abort the whole operation, got: %v", err)
+	}
+
+	for _, record := range records {
+		stored, err := app.Dao().FindRecordById(coll.Id, record.Id)
+		if err != nil {
+			t.Fatalf("Failed to fetch record %s: %v", record.Id, err)
+		}
+		if stored.GetString("status") != "open" || stored.GetString("name") != record.GetString("name") {
+			t.Errorf("Expected record %s to be rolled back, got status=%q name=%q", record.Id, stored.GetString("status"), stored.GetString("name"))
+		}
+	}
+}
+
+func TestMakeImmutable_CallbackOrdering(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "callback_ordering_test")
+	initialRecord.Set("status", "open")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	failingCallback := func(e *core.RecordEvent) error {
+		return errors.New("callback failed")
+	}
+
+	tests := []struct {
+		name                string
+		args                []interface{}
+		expectErrorContains string
+	}{
+		{"before commit by default", []interface{}{"name", failingCallback}, "user callback failed, record changes were not saved: callback failed"},
+		{"after commit", []interface{}{"name", failingCallback, WithRunAfterCommit()}, "user callback failed AFTER record commit: callback failed"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable(tc.args...)
+
+			// the failing hook must roll back the transaction that saves the record
+			err := app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+				event := newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "closed"})
+				if err := hookFunc(event); err != nil {
+					return err
+				}
+				return txDao.SaveRecord(event.Record)
+			})
+			if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+				t.Fatalf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+			}
+
+			stored, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+			if err != nil {
+				t.Fatalf("Failed to fetch record: %v", err)
+			}
+			if stored.GetString("status") != "open" {
+				t.Errorf("Expected the update to be rolled back, got status=%q", stored.GetString("status"))
+			}
+		})
+	}
+}
+
+func TestMakeImmutable_OriginalCallback(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "original_callback_test")
+	initialRecord.Set("status", "open")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	for _, runAfterCommit := range []bool{false, true} {
+		var gotStatus, gotPendingStatus string
+		callback := func(e *core.RecordEvent, original *models.Record) error {
+			gotStatus = original.GetString("status")
+			gotPendingStatus = e.Record.GetString("status")
+			return nil
+		}
+
+		args := []interface{}{"name", callback}
+		if runAfterCommit {
+			args = append(args, WithRunAfterCommit())
+		}
+
+		if err := MakeImmutable(args...)(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "closed"})); err != nil {
+			t.Fatalf("Expected no error (runAfterCommit=%v), got: %v", runAfterCommit, err)
+		}
+		if gotStatus != "open" || gotPendingStatus != "closed" {
+			t.Errorf("Expected original status 'open' and pending status 'closed' (runAfterCommit=%v), got %q and %q", runAfterCommit, gotStatus, gotPendingStatus)
+		}
+	}
+
+	t.Run("mixed with a single-arg callback", func(t *testing.T) {
+		err := MakeImmutable("name",
+			func(e *core.RecordEvent) error { return nil },
+			func(e *core.RecordEvent, original *models.Record) error { return nil },
+		)(newUpdateEvent(app, initialRecord, map[string]interface{}{}))
+		if err == nil || !strings.Contains(err.Error(), "only one callback function can be provided") {
+			t.Errorf("Expected callback setup error, got: %v", err)
+		}
+	})
+}
+
+func TestMakeImmutable_ReportsAllViolations(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "all_violations_test")
+	initialRecord.Set("value", 100)
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	hookFunc := MakeImmutable("name", "value", "status")
+
+	err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed", "value": 200, "status": "inactive"}))
+
+	var apiErr *apis.ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *apis.ApiError, got %T (%v)", err, err)
+	}
+	if apiErr.Message != "Attempt to modify 3 immutable fields: name, value, status." {
+		t.Errorf("Unexpected error message: %s", apiErr.Message)
+	}
+
+	data, ok := apiErr.RawData().(validation.Errors)
+	if !ok || len(data) != 3 {
+		t.Fatalf("Expected validation errors for all violated fields, got: %#v", apiErr.RawData())
+	}
+	for _, field := range []string{"name", "value", "status"} {
+		fieldErr, _ := data[field].(validation.Error)
+		if fieldErr == nil || fieldErr.Code() != ViolationCode || fieldErr.Error() != "Attempt to modify immutable field '"+field+"'." {
+			t.Errorf("Unexpected error for field %s: %v", field, data[field])
+		}
+	}
+
+	var immutableErr *ImmutableFieldError
+	if !errors.As(err, &immutableErr) || strings.Join(immutableErr.Fields, ",") != "name,value,status" {
+		t.Errorf("Expected all violated fields in the ImmutableFieldError, got: %v", immutableErr)
+	}
+}
+
+func TestIsSystemField(t *testing.T) {
+	for _, name := range []string{"id", "created", "updated", "collectionId", "collectionName", "expand"} {
+		if !IsSystemField(name) {
+			t.Errorf("Expected %q to be a system field", name)
+		}
+	}
+	for _, name := range []string{"name", "_managed", "Id", ""} {
+		if IsSystemField(name) {
+			t.Errorf("Expected %q not to be a system field", name)
+		}
+	}
+}
+
+func TestWithSystemFields(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_managed_items",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "name", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "_syncedAt", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "system_fields_test")
+	initialRecord.Set("_syncedAt", "never")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	isManaged := func(name string) bool { return strings.HasPrefix(name, "_") }
+
+	tests := []struct {
+		name                string
+		hookFunc            func(e *core.RecordEvent) error
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"all fields without option", MakeImmutable(), map[string]interface{}{"_syncedAt": "now"}, "Attempt to modify immutable field '_syncedAt'"},
+		{"all fields skip managed field", MakeImmutable(WithSystemFields(isManaged)), map[string]interface{}{"_syncedAt": "now"}, ""},
+		{"listed managed field is skipped", MakeImmutable("name", "_syncedAt", WithSystemFields(isManaged)), map[string]interface{}{"_syncedAt": "now"}, ""},
+		{"other fields are still checked", MakeImmutable(WithSystemFields(isManaged)), map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"typed config", MakeImmutableWith(ImmutableConfig{FreezeAll: true, SystemFields: isManaged}), map[string]interface{}{"_syncedAt": "now"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// fetchContextTracer replaces the context of the original record fetch, e.g. to cancel
+// the request while the hook is running.
+type fetchContextTracer struct {
+	fetchContext func(ctx context.Context) context.Context
+}
+
+func (tr fetchContextTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	if name == SpanFetch {
+		ctx = tr.fetchContext(ctx)
+	}
+	return ctx, noopSpan{}
+}
+
+func TestMakeImmutable_UpsertWithProvidedId(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	t.Run("new record is treated as a create", func(t *testing.T) {
+		var callbackOriginal *models.Record
+		callbackCalled := false
+		hookFunc := MakeImmutable("name", func(e *core.RecordEvent, original *models.Record) error {
+			callbackCalled = true
+			callbackOriginal = original
+			return nil
+		})
+
+		// an upsert with a client provided id that isn't stored yet
+		eventRecord := models.NewRecord(coll)
+		eventRecord.Id = "upsert000000001"
+		eventRecord.Set("name", "upserted")
+
+		if err := hookFunc(&core.RecordEvent{App: app, Record: eventRecord}); err != nil {
+			t.Fatalf("Expected the upsert to proceed, got: %v", err)
+		}
+		if !callbackCalled || callbackOriginal != nil {
+			t.Errorf("Expected the callback to run without original record, got called=%v original=%v", callbackCalled, callbackOriginal)
+		}
+	})
+
+	t.Run("database errors still surface", func(t *testing.T) {
+		broken := &models.Collection{
+			Name:   "test_broken_items",
+			Type:   models.CollectionTypeBase,
+			Schema: schema.NewSchema(&schema.SchemaField{Name: "name", Type: schema.FieldTypeText}),
+		}
+		if err := app.Dao().SaveCollection(broken); err != nil {
+			t.Fatalf("Failed to save collection: %v", err)
+		}
+		if _, err := app.Dao().DB().NewQuery("DROP TABLE {{test_broken_items}}").Execute(); err != nil {
+			t.Fatalf("Failed to drop the collection table: %v", err)
+		}
+
+		eventRecord := models.NewRecord(broken)
+		eventRecord.Id = "upsert000000002"
+		eventRecord.Set("name", "upserted")
+
+		err := MakeImmutable("name")(&core.RecordEvent{App: app, Record: eventRecord})
+		if err == nil || !strings.Contains(err.Error(), "Failed to fetch original record") {
+			t.Errorf("Expected the fetch error, got: %v", err)
+		}
+	})
+}
+
+func TestMakeImmutable_FetchCancelled(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "cancel_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		fetchContext func(ctx context.Context) context.Context
+		expectStatus int
+	}{
+		{"cancelled", func(ctx context.Context) context.Context {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+			return ctx
+		}, statusClientClosedRequest},
+		{"timed out", func(ctx context.Context) context.Context {
+			ctx, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+			t.Cleanup(cancel)
+			return ctx
+		}, http.StatusGatewayTimeout},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable("name", WithTracer(fetchContextTracer{tc.fetchContext}))
+
+			// a record without tracked original state, so that the original is fetched
+			eventRecord := models.NewRecord(coll)
+			eventRecord.Id = initialRecord.Id
+			eventRecord.Set("name", "cancel_test")
+
+			err := hookFunc(&core.RecordEvent{App: app, Record: eventRecord})
+
+			var apiErr *apis.ApiError
+			if !errors.As(err, &apiErr) || apiErr.Code != tc.expectStatus {
+				t.Fatalf("Expected an error with status %d, got: %v", tc.expectStatus, err)
+			}
+			if !strings.Contains(apiErr.Message, "was aborted") {
+				t.Errorf("Unexpected error message: %s", apiErr.Message)
+			}
+		})
+	}
+}
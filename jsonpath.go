@@ -0,0 +1,117 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// WithStrictJSONNull makes JSON path comparisons distinguish an absent key from a key
+// explicitly set to null. By default both are treated as equal.
+func WithStrictJSONNull() Option {
+	return func(cfg *hookConfig) {
+		cfg.cmp.strictJSONNull = true
+	}
+}
+
+// WithStrictJSONCompare compares JSON field values as they are stored, so that the same
+// object with its keys in another order (or differently formatted) is reported as a change.
+// By default JSON field values are decoded and compared structurally.
+func WithStrictJSONCompare() Option {
+	return func(cfg *hookConfig) {
+		cfg.cmp.strictJSON = true
+	}
+}
+
+// splitJSONPath splits a dotted "field.key.subkey" name into the JSON schema field
+// and the nested key path. It returns false if the name doesn't address a JSON field subkey.
+func splitJSONPath(record *models.Record, name string) (string, []string, bool) {
+	parts := strings.Split(name, ".")
+	if len(parts) < 2 {
+		return "", nil, false
+	}
+
+	field := record.Schema().GetFieldByName(parts[0])
+	if field == nil || field.Type != schema.FieldTypeJson {
+		return "", nil, false
+	}
+
+	return parts[0], parts[1:], true
+}
+
+// validateJSONPaths returns an error listing the dotted field names
+// that don't address a subkey of a JSON field of the record schema.
+func validateJSONPaths(record *models.Record, names []string) error {
+	var invalid []string
+	for _, name := range names {
+		if !strings.Contains(name, ".") {
+			continue
+		}
+		if _, _, ok := splitJSONPath(record, name); !ok {
+			invalid = append(invalid, strconv.Quote(name))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("field paths %s don't address a key of a JSON field of collection %s", strings.Join(invalid, ", "), record.Collection().Name)
+	}
+
+	return nil
+}
+
+// resolveJSONPath returns the value at the nested key path of a JSON field
+// and whether the path is present. Numeric keys index into arrays.
+func resolveJSONPath(record *models.Record, field string, path []string) (any, bool) {
+	var current any
+	if err := record.UnmarshalJSONField(field, &current); err != nil {
+		return nil, false
+	}
+
+	for _, key := range path {
+		switch v := current.(type) {
+		case map[string]any:
+			value, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// evaluateJSONPath compares the value at a nested JSON path of the original and pending record.
+func (c comparer) evaluateJSONPath(original, pending *models.Record, name, field string, path []string) Change {
+	oldValue, oldPresent := resolveJSONPath(original, field, path)
+	newValue, newPresent := resolveJSONPath(pending, field, path)
+
+	change := Change{
+		Field:    name,
+		Type:     schema.FieldTypeJson,
+		Old:      oldValue,
+		New:      newValue,
+		RuleKind: RuleImmutable,
+	}
+
+	switch {
+	case oldPresent != newPresent:
+		// absent and null are only equal in non-strict mode
+		change.Violated = c.strictJSONNull || oldValue != nil || newValue != nil
+	default:
+		change.Violated = !c.equal(name, "", oldValue, newValue)
+	}
+
+	return change
+}
@@ -0,0 +1,155 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestJSONPath_AbsentVsNull(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_documents",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "metadata", Type: schema.FieldTypeJson},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newRecord := func(metadata string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("metadata", metadata)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	tests := []struct {
+		name        string
+		original    string
+		pending     string
+		strict      bool
+		expectError bool
+	}{
+		{"absent vs null (default)", `{"title":"a"}`, `{"title":"a","reviewer":null}`, false, false},
+		{"null vs absent (default)", `{"title":"a","reviewer":null}`, `{"title":"a"}`, false, false},
+		{"absent vs null (strict)", `{"title":"a"}`, `{"title":"a","reviewer":null}`, true, true},
+		{"null vs absent (strict)", `{"title":"a","reviewer":null}`, `{"title":"a"}`, true, true},
+		{"null vs null (strict)", `{"reviewer":null}`, `{"reviewer":null,"title":"b"}`, true, false},
+		{"absent vs value (default)", `{"title":"a"}`, `{"reviewer":"bob"}`, false, true},
+		{"value vs absent (default)", `{"reviewer":"bob"}`, `{"title":"a"}`, false, true},
+		{"value changed", `{"reviewer":"bob"}`, `{"reviewer":"alice"}`, false, true},
+		{"sibling key changed", `{"reviewer":"bob","title":"a"}`, `{"reviewer":"bob","title":"b"}`, true, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := []interface{}{"metadata.reviewer"}
+			if tc.strict {
+				args = append(args, WithStrictJSONNull())
+			}
+			hookFunc := MakeImmutable(args...)
+
+			record := newRecord(tc.original)
+			err := hookFunc(newUpdateEvent(app, record, map[string]interface{}{"metadata": tc.pending}))
+
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'metadata.reviewer'") {
+					t.Errorf("Expected immutable field error, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestMakeImmutable_JSONKeyOrder(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_settings",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "config", Type: schema.FieldTypeJson},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("config", `{"theme":{"dark":true,"accent":"red"},"size":3}`)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		pending     string
+		strict      bool
+		expectError bool
+	}{
+		{"reordered keys", `{"size":3,"theme":{"accent":"red","dark":true}}`, false, false},
+		{"changed nested value", `{"size":3,"theme":{"accent":"blue","dark":true}}`, false, true},
+		{"reordered keys (strict)", `{"size":3,"theme":{"accent":"red","dark":true}}`, true, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := []interface{}{"config"}
+			if tc.strict {
+				args = append(args, WithStrictJSONCompare())
+			}
+
+			err := MakeImmutable(args...)(newUpdateEvent(app, initialRecord, map[string]interface{}{"config": tc.pending}))
+
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'config'") {
+					t.Errorf("Expected immutable field error, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestJSONPath_Validation(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "json_path_validation")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		field string
+	}{
+		{"unknown field", "metadata.createdBy"},
+		{"non JSON field", "name.first"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable("value", tc.field)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, nil))
+			if err == nil || !strings.Contains(err.Error(), "MakeImmutable setup error") || !strings.Contains(err.Error(), `"`+tc.field+`" don't address a key of a JSON field`) {
+				t.Errorf("Expected setup error for %s, got: %v", tc.field, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// DefaultJustificationKey is the request body key read by MakeJustifiedImmutable
+// when JustificationRule.Key is not set.
+const DefaultJustificationKey = "justification"
+
+// JustificationRule configures the justified override of frozen fields.
+type JustificationRule struct {
+	// Key is the request body key carrying the justification text
+	// (defaults to DefaultJustificationKey).
+	Key string
+
+	// AuditCollection is the name of the collection where every override is recorded.
+	//
+	// It is expected to have the following fields:
+	//  - recordId      (text)  id of the updated record
+	//  - collection    (text)  name of the updated record collection
+	//  - field         (text)  name of the overridden field
+	//  - oldValue      (json)  value before the update
+	//  - newValue      (json)  value after the update
+	//  - justification (text)  the submitted justification
+	//  - actor         (text)  id of the authenticated record or admin (empty for guests)
+	AuditCollection string
+}
+
+// MakeJustifiedImmutable returns a hook that freezes the provided fields (or all user-defined
+// fields when none are given) unless the request carries a non-empty justification.
+//
+// Every justified override writes one audit record per changed frozen field into
+// rule.AuditCollection. The audit records are saved in the same transaction as the
+// record update (e.Next() sees the transaction dao as e.App.Dao()), so if an audit write
+// or the save fails, both are rolled back.
+//
+// It accepts the same arguments as MakeImmutable.
+func MakeJustifiedImmutable(rule JustificationRule, args ...interface{}) func(e *core.RecordEvent) error {
+	if rule.Key == "" {
+		rule.Key = DefaultJustificationKey
+	}
+
+	cfg := parseArgs("MakeJustifiedImmutable", args)
+	cfg.requestKeys = append(cfg.requestKeys, rule.Key) // the justification isn't a record field
+	if cfg.setupErr == nil && rule.AuditCollection == "" {
+		cfg.setupErr = errors.New("pbimmutable.MakeJustifiedImmutable: an audit collection must be provided")
+	}
+
+	cfg.override = func(e *core.RecordEvent, violations ChangeSet) (bool, func(txDao *daos.Dao) error) {
+		info := requestInfo(e)
+		if info == nil {
+			return false, nil
+		}
+
+		justification, _ := info.Data[rule.Key].(string)
+		justification = strings.TrimSpace(justification)
+		if justification == "" {
+			return false, nil
+		}
+
+		actor := requestActorId(info)
+
+		return true, func(txDao *daos.Dao) error {
+			auditCollection, err := txDao.FindCollectionByNameOrId(rule.AuditCollection)
+			if err != nil {
+				return fmt.Errorf("failed to find audit collection %q: %w", rule.AuditCollection, err)
+			}
+
+			for _, change := range violations {
+				audit := models.NewRecord(auditCollection)
+				audit.Set("recordId", e.Record.Id)
+				audit.Set("collection", e.Record.Collection().Name)
+				audit.Set("field", change.Field)
+				audit.Set("oldValue", change.Old)
+				audit.Set("newValue", change.New)
+				audit.Set("justification", justification)
+				audit.Set("actor", actor)
+
+				if err := txDao.SaveRecord(audit); err != nil {
+					return fmt.Errorf("failed to save audit record for field %q: %w", change.Field, err)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,168 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/USERNAME/pbimmutable/pbimmutabletest"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeJustifiedImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	auditColl := &models.Collection{
+		Name: "immutable_audit",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "recordId", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "collection", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "field", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "oldValue", Type: schema.FieldTypeJson},
+			&schema.SchemaField{Name: "newValue", Type: schema.FieldTypeJson},
+			&schema.SchemaField{Name: "justification", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "actor", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(auditColl); err != nil {
+		t.Fatalf("Failed to save audit collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "justified_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	rule := JustificationRule{AuditCollection: auditColl.Name}
+	updates := map[string]interface{}{"value": 150}
+
+	t.Run("no justification is rejected", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(rule, "value")
+
+		err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, &models.RequestInfo{Data: map[string]any{"value": 150}}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'value'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+
+	t.Run("blank justification is rejected", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(rule, "value")
+
+		err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, &models.RequestInfo{Data: map[string]any{"justification": "   "}}))
+		if err == nil {
+			t.Errorf("Expected immutable field error, got nil")
+		}
+	})
+
+	t.Run("programmatic update without request is rejected", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(rule, "value")
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, updates)); err == nil {
+			t.Errorf("Expected immutable field error, got nil")
+		}
+	})
+
+	t.Run("justified override is audited", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(rule, "value")
+
+		info := &models.RequestInfo{Data: map[string]any{"justification": "corrected a typo in the invoice"}}
+		if err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, info)); err != nil {
+			t.Fatalf("Expected justified update to pass, got: %v", err)
+		}
+
+		audits, err := app.Dao().FindRecordsByFilter(auditColl.Name, "recordId = {:id}", "", 0, 0, map[string]any{"id": initialRecord.Id})
+		if err != nil {
+			t.Fatalf("Failed to fetch audit records: %v", err)
+		}
+		if len(audits) != 1 {
+			t.Fatalf("Expected 1 audit record, got %d", len(audits))
+		}
+		if audits[0].GetString("field") != "value" || audits[0].GetString("justification") != "corrected a typo in the invoice" {
+			t.Errorf("Unexpected audit record: %v", audits[0].PublicExport())
+		}
+	})
+
+	// saveEvent returns a justified update event whose e.Next() saves the record like the
+	// record API does, with e.App.Dao(), and then fails with nextErr when set
+	saveEvent := func(justification string, nextErr error) *pbimmutabletest.Event {
+		info := &models.RequestInfo{Data: map[string]any{"justification": justification}}
+		return pbimmutabletest.NewEvent(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 300}, info), func(e *core.RecordEvent) error {
+			if err := e.App.Dao().SaveRecord(e.Record); err != nil {
+				return err
+			}
+			return nextErr
+		})
+	}
+
+	// assertValue fails the test if the stored value of the record isn't expected
+	assertValue := func(t *testing.T, expected int) {
+		t.Helper()
+		current, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to reload record: %v", err)
+		}
+		if current.GetInt("value") != expected {
+			t.Errorf("Expected the stored value %d, got %d", expected, current.GetInt("value"))
+		}
+	}
+
+	t.Run("missing audit collection rolls back", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(JustificationRule{AuditCollection: "missing_audit"}, "value")
+
+		err := saveEvent("fix", nil).Run(hookFunc)
+		if err == nil || !strings.Contains(err.Error(), "failed to find audit collection") {
+			t.Errorf("Expected audit failure error, got: %v", err)
+		}
+		assertValue(t, 100)
+	})
+
+	t.Run("failed save rolls back the audit records", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(rule, "value")
+
+		err := saveEvent("rolled back", errors.New("save failed")).Run(hookFunc)
+		if err == nil || !strings.Contains(err.Error(), "save failed") {
+			t.Errorf("Expected the save error, got: %v", err)
+		}
+		assertValue(t, 100)
+
+		audits, err := app.Dao().FindRecordsByFilter(auditColl.Name, "justification = 'rolled back'", "", 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to fetch audit records: %v", err)
+		}
+		if len(audits) != 0 {
+			t.Errorf("Expected no orphan audit records, got %d", len(audits))
+		}
+	})
+
+	t.Run("justified save is committed with its audit record", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(rule, "value")
+
+		if err := saveEvent("committed", nil).Run(hookFunc); err != nil {
+			t.Fatalf("Expected justified update to pass, got: %v", err)
+		}
+		assertValue(t, 300)
+
+		audits, err := app.Dao().FindRecordsByFilter(auditColl.Name, "justification = 'committed'", "", 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to fetch audit records: %v", err)
+		}
+		if len(audits) != 1 {
+			t.Errorf("Expected 1 audit record, got %d", len(audits))
+		}
+	})
+
+	t.Run("audit collection is required", func(t *testing.T) {
+		hookFunc := MakeJustifiedImmutable(JustificationRule{}, "value")
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeJustifiedImmutable setup error") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
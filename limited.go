@@ -0,0 +1,86 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// RuleLimitedChanges is the kind of the rule created by MakeLimitedChanges.
+const RuleLimitedChanges RuleKind = "limited_changes"
+
+// MakeLimitedChanges returns a hook that allows field to change at most maxChanges times,
+// e.g. MakeLimitedChanges("username", 3, "usernameChanges"). Once the limit is reached,
+// the field is frozen like with MakeImmutable.
+//
+// The changes are counted in counterField, which must be a number field of the collection
+// schema (usually hidden from the API rules). Every accepted change of field increments it
+// on the pending record right before e.Next(), so it is saved with the change. The counter
+// is maintained by the hook only: an update that changes it is rejected.
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeLimitedChanges(field string, maxChanges int, counterField string, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeLimitedChanges", args)
+	if cfg.setupErr == nil {
+		switch {
+		case field == "" || counterField == "":
+			cfg.setupErr = errors.New("pbimmutable.MakeLimitedChanges: the field and counter field names must be provided")
+		case field == counterField:
+			cfg.setupErr = errors.New("pbimmutable.MakeLimitedChanges: the counter field must differ from the limited field")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeLimitedChanges: only a callback can be passed as additional argument")
+		case maxChanges < 0:
+			cfg.setupErr = fmt.Errorf("pbimmutable.MakeLimitedChanges: invalid limit %d", maxChanges)
+		}
+	}
+
+	// changed reports whether the pending record changes field
+	changed := func(original, pending *models.Record) bool {
+		change := newChange(original, pending, field, RuleLimitedChanges)
+		return !cfg.cmp.equalValues(pending, field, change.Old, change.New)
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		pending := e.Record
+		if pending.Schema().GetFieldByName(field) == nil {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeLimitedChanges setup error: '%s' is not a field of collection %s.", field, pending.Collection().Name), nil)
+		}
+		if schemaField := pending.Schema().GetFieldByName(counterField); schemaField == nil || schemaField.Type != schema.FieldTypeNumber {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeLimitedChanges setup error: '%s' is not a number field of collection %s.", counterField, pending.Collection().Name), nil)
+		}
+
+		count := original.GetInt(counterField)
+
+		counter := newChange(original, pending, counterField, RuleLimitedChanges)
+		if !cfg.cmp.equalValues(pending, counterField, counter.Old, counter.New) {
+			counter.Violated = true
+			counter.Message = fmt.Sprintf("Field '%s' is maintained by the server and can't be changed.", counterField)
+		}
+
+		change := newChange(original, pending, field, RuleLimitedChanges)
+		if changed(original, pending) && count >= maxChanges {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' can't be changed more than %d times.", field, maxChanges)
+			change.Details = map[string]any{
+				"changes":    count,
+				"maxChanges": maxChanges,
+			}
+		}
+
+		return ChangeSet{change, counter}, nil
+	}
+
+	cfg.beforeNext = append(cfg.beforeNext, func(e *core.RecordEvent, original *models.Record) error {
+		if changed(original, e.Record) {
+			e.Record.Set(counterField, original.GetInt(counterField)+1)
+		}
+		return nil
+	})
+
+	return newHook(cfg)
+}
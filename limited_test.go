@@ -0,0 +1,105 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeLimitedChanges(t *testing.T) {
+	app, baseColl, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_profiles",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "handle", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "bio", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "handleChanges", Type: schema.FieldTypeNumber},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newRecord := func(changes int) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("handle", "alice")
+		record.Set("handleChanges", changes)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	fresh := newRecord(0)
+	exhausted := newRecord(3)
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		updates             map[string]interface{}
+		expectErrorContains string
+		expectCounter       int
+	}{
+		{"first change", fresh, map[string]interface{}{"handle": "alice2"}, "", 1},
+		{"unchanged field", fresh, map[string]interface{}{"bio": "hello"}, "", 0},
+		{"limit reached", exhausted, map[string]interface{}{"handle": "alice4"}, "Field 'handle' can't be changed more than 3 times.", 3},
+		{"other field after the limit", exhausted, map[string]interface{}{"bio": "hello"}, "", 3},
+		{"counter reset", exhausted, map[string]interface{}{"handleChanges": 0}, "Field 'handleChanges' is maintained by the server and can't be changed.", 0},
+		{"counter reset with change", exhausted, map[string]interface{}{"handle": "alice4", "handleChanges": 0}, "Attempt to modify 2 immutable fields: handle, handleChanges.", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeLimitedChanges("handle", 3, "handleChanges")
+
+			event := newUpdateEvent(app, tc.original, tc.updates)
+			err := hookFunc(event)
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if got := event.Record.GetInt("handleChanges"); got != tc.expectCounter {
+				t.Errorf("Expected counter %d, got %d", tc.expectCounter, got)
+			}
+		})
+	}
+
+	t.Run("setup errors", func(t *testing.T) {
+		baseRecord := models.NewRecord(baseColl)
+		baseRecord.Set("name", "limited_setup")
+		if err := app.Dao().SaveRecord(baseRecord); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+
+		setupTests := []struct {
+			field, counterField string
+			maxChanges          int
+			expectErrorContains string
+		}{
+			{"name", "", 3, "the field and counter field names must be provided"},
+			{"name", "name", 3, "the counter field must differ from the limited field"},
+			{"name", "value", -1, "invalid limit -1"},
+			{"nmae", "value", 3, "'nmae' is not a field of collection test_items"},
+			{"name", "status", 3, "'status' is not a number field of collection test_items"},
+		}
+
+		for _, tc := range setupTests {
+			err := MakeLimitedChanges(tc.field, tc.maxChanges, tc.counterField)(newUpdateEvent(app, baseRecord, map[string]interface{}{"name": "changed"}))
+			if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package pbimmutable
+
+import (
+	"errors"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// DefaultLockedByField is the default LockRule.LockedByField.
+	DefaultLockedByField = "lockedBy"
+
+	// DefaultLockedAtField is the default LockRule.LockedAtField.
+	DefaultLockedAtField = "lockedAt"
+
+	// SystemLockActor is stamped as LockedByField when the sealing update
+	// was not made by an authenticated record or admin (e.g. a programmatic save).
+	SystemLockActor = "system"
+)
+
+// LockRule configures when a record is sealed and where the lock provenance is stored.
+type LockRule struct {
+	// Seals reports whether the pending record state seals the record,
+	// e.g. func(r *models.Record) bool { return r.GetString("status") == "completed" }.
+	Seals func(pending *models.Record) bool
+
+	// LockedByField receives the id of the actor that sealed the record
+	// (defaults to DefaultLockedByField).
+	LockedByField string
+
+	// LockedAtField receives the time when the record was sealed
+	// (defaults to DefaultLockedAtField).
+	LockedAtField string
+}
+
+// MakeLockOnSeal returns a hook that stamps who and when sealed a record and freezes it afterwards.
+//
+// On the update that seals the record (rule.Seals returns true for the pending record and the
+// original record has no lock stamp yet), the LockedByField and LockedAtField are set within the
+// same update. Every subsequent update finds the stamp on the original record and is checked for
+// immutability of the provided fields (or all user-defined fields when none are given), always
+// including the lock fields themselves, so the stamp is written exactly once.
+//
+// The lock fields are managed by the hook: values submitted by clients before the record is
+// sealed are discarded.
+//
+// It accepts the same arguments as MakeImmutable.
+func MakeLockOnSeal(rule LockRule, args ...interface{}) func(e *core.RecordEvent) error {
+	if rule.LockedByField == "" {
+		rule.LockedByField = DefaultLockedByField
+	}
+	if rule.LockedAtField == "" {
+		rule.LockedAtField = DefaultLockedAtField
+	}
+
+	cfg := parseArgs("MakeLockOnSeal", args)
+	if cfg.setupErr == nil && rule.Seals == nil {
+		cfg.setupErr = errors.New("pbimmutable.MakeLockOnSeal: a Seals function must be provided")
+	}
+	cfg.addFields(rule.LockedByField, rule.LockedAtField)
+
+	isLocked := func(record *models.Record) bool {
+		return !isEmptyValue(record.Get(rule.LockedAtField))
+	}
+
+	cfg.freezeOn(isLocked, func(record *models.Record) FreezeInfo {
+		return FreezeInfo{
+			Reason: FreezeReasonLocked,
+			Since:  dateTimeOf(record, rule.LockedAtField),
+			Data:   map[string]any{"lockedBy": record.GetString(rule.LockedByField)},
+		}
+	})
+
+	cfg.beforeNext = append(cfg.beforeNext, func(e *core.RecordEvent, original *models.Record) error {
+		if isLocked(original) {
+			return nil // already sealed and passed the immutability check
+		}
+
+		if !rule.Seals(e.Record) {
+			// not sealing yet, keep the lock fields under the hook control
+			e.Record.Set(rule.LockedByField, original.Get(rule.LockedByField))
+			e.Record.Set(rule.LockedAtField, original.Get(rule.LockedAtField))
+			return nil
+		}
+
+		actor := requestActorId(requestInfo(e))
+		if actor == "" {
+			actor = SystemLockActor
+		}
+
+		e.Record.Set(rule.LockedByField, actor)
+		e.Record.Set(rule.LockedAtField, types.NowDateTime())
+
+		return nil
+	})
+
+	return newHook(cfg)
+}
@@ -0,0 +1,103 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeLockOnSeal(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_orders",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "status", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "amount", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "lockedBy", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "lockedAt", Type: schema.FieldTypeDate},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	order := models.NewRecord(coll)
+	order.Set("status", "open")
+	order.Set("amount", 10)
+	if err := app.Dao().SaveRecord(order); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	hookFunc := MakeLockOnSeal(LockRule{
+		Seals: func(r *models.Record) bool { return r.GetString("status") == "completed" },
+	})
+
+	t.Run("regular update is not stamped", func(t *testing.T) {
+		event := newUpdateEvent(app, order, map[string]interface{}{"amount": 20, "lockedBy": "spoofed"})
+
+		if err := hookFunc(event); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if event.Record.GetString("lockedBy") != "" || !event.Record.GetDateTime("lockedAt").IsZero() {
+			t.Errorf("Expected lock fields to stay empty, got %q / %v", event.Record.GetString("lockedBy"), event.Record.GetDateTime("lockedAt"))
+		}
+	})
+
+	var sealed *models.Record
+
+	t.Run("sealing update is stamped", func(t *testing.T) {
+		user := models.NewRecord(coll)
+		user.Id = "seal_user"
+
+		event := newRequestUpdateEvent(app, order, map[string]interface{}{"status": "completed"}, &models.RequestInfo{AuthRecord: user})
+
+		if err := hookFunc(event); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if event.Record.GetString("lockedBy") != "seal_user" {
+			t.Errorf("Expected lockedBy to be stamped with the actor, got %q", event.Record.GetString("lockedBy"))
+		}
+		if event.Record.GetDateTime("lockedAt").IsZero() {
+			t.Errorf("Expected lockedAt to be stamped")
+		}
+
+		if err := app.Dao().SaveRecord(event.Record); err != nil {
+			t.Fatalf("Failed to save sealed record: %v", err)
+		}
+		sealed = event.Record
+	})
+
+	t.Run("sealed record is frozen", func(t *testing.T) {
+		err := hookFunc(newUpdateEvent(app, sealed, map[string]interface{}{"amount": 30}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'amount'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+
+	t.Run("stamp is written only once", func(t *testing.T) {
+		err := hookFunc(newUpdateEvent(app, sealed, map[string]interface{}{"lockedBy": "someone_else"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'lockedBy'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+
+		event := newUpdateEvent(app, sealed, nil)
+		if err := hookFunc(event); err != nil {
+			t.Fatalf("Expected no error for an unchanged sealed record, got: %v", err)
+		}
+		if event.Record.GetString("lockedBy") != "seal_user" {
+			t.Errorf("Expected the original stamp to be kept, got %q", event.Record.GetString("lockedBy"))
+		}
+	})
+
+	t.Run("Seals is required", func(t *testing.T) {
+		err := MakeLockOnSeal(LockRule{})(newUpdateEvent(app, order, nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeLockOnSeal setup error") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package pbimmutable
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// WithMessages replaces the default violation message of the listed fields, e.g.
+//
+//	WithMessages(map[string]string{"ssn": "Social Security Number cannot be changed after enrollment."})
+//
+// A "{field}" placeholder in a message is replaced with the field name. Fields without
+// a message keep the message of their rule. The error data still has an entry for the
+// field with the ViolationCode code, so clients can match the violation without parsing it.
+func WithMessages(messages map[string]string) Option {
+	return func(cfg *hookConfig) {
+		if cfg.messages == nil {
+			cfg.messages = make(map[string]string, len(messages))
+		}
+		for field, message := range messages {
+			cfg.messages[field] = message
+		}
+	}
+}
+
+// WithMessageResolver lets resolver produce the message of each violated field, e.g. to
+// localize it based on the Accept-Language header of the request:
+//
+//	WithMessageResolver(func(e *core.RecordEvent, field string) string {
+//		return translate(e.HttpContext, "immutable_field", field)
+//	})
+//
+// An empty result keeps the message of WithMessages or of the rule. The resolved messages
+// replace the messages of the error data entries, whose ViolationCode code stays the same.
+// With several violations, the summary message of the error is not localized.
+func WithMessageResolver(resolver func(e *core.RecordEvent, field string) string) Option {
+	return func(cfg *hookConfig) {
+		cfg.messageResolver = resolver
+	}
+}
+
+// applyMessages replaces the messages of the violations that have a custom
+// or resolved message.
+func (cfg *hookConfig) applyMessages(e *core.RecordEvent, violations ChangeSet) {
+	for i, change := range violations {
+		if message, ok := cfg.messages[change.Field]; ok {
+			violations[i].Message = strings.ReplaceAll(message, "{field}", change.Field)
+		}
+		if cfg.messageResolver == nil {
+			continue
+		}
+		if message := cfg.messageResolver(e, change.Field); message != "" {
+			violations[i].Message = message
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithMessages(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "messages_test")
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	messages := map[string]string{
+		"name":   "The name cannot be changed after enrollment.",
+		"status": "The {field} is locked.",
+	}
+
+	tests := []struct {
+		name          string
+		hookFunc      func() error
+		expectMessage string
+		expectField   string
+	}{
+		{
+			"custom message",
+			func() error {
+				return MakeImmutable("name", "value", WithMessages(messages))(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+			},
+			"The name cannot be changed after enrollment.",
+			"name",
+		},
+		{
+			"field placeholder",
+			func() error {
+				return MakeImmutable("status", WithMessages(messages))(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "inactive"}))
+			},
+			"The status is locked.",
+			"status",
+		},
+		{
+			"default message without custom message",
+			func() error {
+				return MakeImmutable("name", "value", WithMessages(messages))(newUpdateEvent(app, initialRecord, map[string]interface{}{"value": 5}))
+			},
+			"Attempt to modify immutable field 'value'.",
+			"value",
+		},
+		{
+			"typed config",
+			func() error {
+				return MakeImmutableWith(ImmutableConfig{Fields: []string{"name"}, Messages: messages})(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+			},
+			"The name cannot be changed after enrollment.",
+			"name",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.hookFunc()
+
+			var apiErr *apis.ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an ApiError, got: %v", err)
+			}
+			if apiErr.Message != tc.expectMessage {
+				t.Errorf("Expected message %q, got %q", tc.expectMessage, apiErr.Message)
+			}
+
+			data, _ := apiErr.RawData().(validation.Errors)
+			fieldErr, ok := data[tc.expectField]
+			if !ok {
+				t.Fatalf("Expected field %q in the error data, got %v", tc.expectField, data)
+			}
+			if fieldErr.Error() != tc.expectMessage {
+				t.Errorf("Expected field message %q, got %v", tc.expectMessage, fieldErr)
+			}
+		})
+	}
+
+	t.Run("multiple violations keep the summary message", func(t *testing.T) {
+		hookFunc := MakeImmutable("name", "status", WithMessages(messages))
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed", "status": "inactive"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify 2 immutable fields: name, status.") {
+			t.Errorf("Expected the summary message, got: %v", err)
+		}
+	})
+}
+
+func TestWithMessageResolver(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "resolver_test")
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	resolver := func(e *core.RecordEvent, field string) string {
+		if e.HttpContext == nil || e.HttpContext.Request().Header.Get("Accept-Language") != "de" {
+			return ""
+		}
+		return "Das Feld '" + field + "' kann nicht geändert werden."
+	}
+
+	newEvent := func(language string) *core.RecordEvent {
+		event := newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}, &models.RequestInfo{})
+		event.HttpContext.Request().Header.Set("Accept-Language", language)
+		return event
+	}
+
+	tests := []struct {
+		name          string
+		event         *core.RecordEvent
+		args          []interface{}
+		expectMessage string
+	}{
+		{"resolved message", newEvent("de"), nil, "Das Feld 'name' kann nicht geändert werden."},
+		{"empty result keeps the default", newEvent("en"), nil, "Attempt to modify immutable field 'name'."},
+		{"empty result keeps a custom message", newEvent("en"), []interface{}{WithMessages(map[string]string{"name": "Locked."})}, "Locked."},
+		{"resolver wins over a custom message", newEvent("de"), []interface{}{WithMessages(map[string]string{"name": "Locked."})}, "Das Feld 'name' kann nicht geändert werden."},
+		{"non-HTTP event", newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}), nil, "Attempt to modify immutable field 'name'."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append([]interface{}{"name", WithMessageResolver(resolver)}, tc.args...)
+
+			err := MakeImmutable(args...)(tc.event)
+
+			var apiErr *apis.ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an ApiError, got: %v", err)
+			}
+			if apiErr.Message != tc.expectMessage {
+				t.Errorf("Expected message %q, got %q", tc.expectMessage, apiErr.Message)
+			}
+
+			data, _ := apiErr.RawData().(validation.Errors)
+			fieldErr, _ := data["name"].(validation.Error)
+			if fieldErr == nil || fieldErr.Code() != ViolationCode || fieldErr.Error() != tc.expectMessage {
+				t.Errorf("Expected the %q code with the message in the error data, got: %v", ViolationCode, data["name"])
+			}
+		})
+	}
+}
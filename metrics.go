@@ -0,0 +1,44 @@
+package pbimmutable
+
+// Metrics receives the outcome of the immutability checks, e.g. to maintain
+// Prometheus counters, without this package depending on a metrics library.
+//
+// The methods are called synchronously by the hooks and must be safe for concurrent use.
+type Metrics interface {
+	// RecordBlocked is called once for every violated field of a rejected update.
+	RecordBlocked(collection, field string)
+
+	// RecordAllowed is called once for every update that passed the checks.
+	RecordAllowed(collection string)
+}
+
+// NoopMetrics is the Metrics implementation used when no metrics are configured.
+type NoopMetrics struct{}
+
+func (NoopMetrics) RecordBlocked(collection, field string) {}
+func (NoopMetrics) RecordAllowed(collection string)        {}
+
+// WithMetrics reports the outcome of every hook run to metrics
+// (see Metrics). Without this option the hooks use NoopMetrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(cfg *hookConfig) {
+		cfg.metrics = metrics
+	}
+}
+
+// recordBlocked reports the violated fields of a rejected update to the configured metrics.
+func (cfg *hookConfig) recordBlocked(collection string, violations ChangeSet) {
+	if cfg.metrics == nil {
+		return
+	}
+	for _, change := range violations {
+		cfg.metrics.RecordBlocked(collection, change.Field)
+	}
+}
+
+// recordAllowed reports an update that passed the checks to the configured metrics.
+func (cfg *hookConfig) recordAllowed(collection string) {
+	if cfg.metrics != nil {
+		cfg.metrics.RecordAllowed(collection)
+	}
+}
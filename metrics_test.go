@@ -0,0 +1,73 @@
+package pbimmutable
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// countingMetrics is a Metrics implementation that counts the recorded outcomes.
+type countingMetrics struct {
+	mu      sync.Mutex
+	blocked map[string]int // keyed by "collection.field"
+	allowed map[string]int // keyed by collection
+}
+
+func newCountingMetrics() *countingMetrics {
+	return &countingMetrics{blocked: map[string]int{}, allowed: map[string]int{}}
+}
+
+func (m *countingMetrics) RecordBlocked(collection, field string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocked[collection+"."+field]++
+}
+
+func (m *countingMetrics) RecordAllowed(collection string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowed[collection]++
+}
+
+func TestWithMetrics(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "metrics_test")
+	initialRecord.Set("value", 1)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	metrics := newCountingMetrics()
+	hookFunc := MakeImmutableWith(ImmutableConfig{Fields: []string{"name", "value"}, Metrics: metrics})
+
+	updates := []map[string]interface{}{
+		{"status": "changed"},
+		{"name": "changed"},
+		{"name": "changed", "value": 2},
+		{"description": "changed"},
+	}
+	for _, update := range updates {
+		_ = hookFunc(newUpdateEvent(app, initialRecord, update))
+	}
+
+	expectedBlocked := map[string]int{"test_items.name": 2, "test_items.value": 1}
+	if !reflect.DeepEqual(metrics.blocked, expectedBlocked) {
+		t.Errorf("Expected blocked counts %v, got %v", expectedBlocked, metrics.blocked)
+	}
+
+	expectedAllowed := map[string]int{"test_items": 2}
+	if !reflect.DeepEqual(metrics.allowed, expectedAllowed) {
+		t.Errorf("Expected allowed counts %v, got %v", expectedAllowed, metrics.allowed)
+	}
+}
+
+func TestNoopMetrics(t *testing.T) {
+	var metrics Metrics = NoopMetrics{}
+	metrics.RecordBlocked("test_items", "name")
+	metrics.RecordAllowed("test_items")
+}
@@ -0,0 +1,60 @@
+package pbimmutable
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// RuleRequireMFA is the kind of the violations reported by WithRequireMFA.
+const RuleRequireMFA RuleKind = "mfa_required"
+
+// MFAContextKey is the request context key checked by WithRequireMFA.
+// An MFA middleware of the application can set it to true once the
+// current session has passed its second factor, e.g.
+// c.Set(pbimmutable.MFAContextKey, true).
+const MFAContextKey = "pbimmutable.mfaVerified"
+
+// WithRequireMFA allows changes to the listed fields only when the request session has
+// passed multi-factor authentication. Without it, the fields are treated as immutable and
+// the update is rejected with an "MFA required" error.
+//
+// PocketBase doesn't expose an MFA state on its own, so the status is read from the
+// MFAContextKey value of the request context, set by your MFA middleware for the current
+// request only. Fields of the auth record are not trusted: they outlive the session and
+// may be writable by the user.
+//
+// Guests and non-HTTP events never count as MFA verified.
+func WithRequireMFA(fields ...string) Option {
+	return func(cfg *hookConfig) {
+		cfg.addFields(fields...)
+
+		cfg.refiners = append(cfg.refiners, func(e *core.RecordEvent, changes ChangeSet) {
+			verified := hasMFA(e)
+
+			for i, change := range changes {
+				if !change.Violated || !containsString(fields, change.Field) {
+					continue
+				}
+
+				if verified {
+					changes[i].Violated = false
+					continue
+				}
+
+				changes[i].RuleKind = RuleRequireMFA
+				changes[i].Message = fmt.Sprintf("MFA required: field '%s' can only be modified by a multi-factor authenticated session.", change.Field)
+			}
+		})
+	}
+}
+
+// hasMFA reports whether the event request session has passed multi-factor authentication.
+func hasMFA(e *core.RecordEvent) bool {
+	if e.HttpContext == nil {
+		return false
+	}
+
+	verified, _ := e.HttpContext.Get(MFAContextKey).(bool)
+	return verified
+}
@@ -0,0 +1,78 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithRequireMFA(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "mfa_test")
+	initialRecord.Set("value", 100)
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	// a persisted flag of the auth record isn't session state and must not count
+	flaggedUser := models.NewRecord(coll)
+	flaggedUser.Id = "flagged_user"
+	flaggedUser.Set("mfaVerified", true)
+
+	plainUser := models.NewRecord(coll)
+	plainUser.Id = "plain_user"
+
+	hookFunc := MakeImmutable("name", "status", WithRequireMFA("value"))
+
+	t.Run("guest is rejected with MFA error", func(t *testing.T) {
+		err := hookFunc(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 1}, &models.RequestInfo{}))
+		if err == nil || !strings.Contains(err.Error(), "MFA required") {
+			t.Errorf("Expected MFA required error, got: %v", err)
+		}
+	})
+
+	t.Run("non-MFA session is rejected", func(t *testing.T) {
+		err := hookFunc(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 1}, &models.RequestInfo{AuthRecord: plainUser}))
+		if err == nil || !strings.Contains(err.Error(), "MFA required") {
+			t.Errorf("Expected MFA required error, got: %v", err)
+		}
+	})
+
+	t.Run("programmatic update is rejected", func(t *testing.T) {
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"value": 1}))
+		if err == nil || !strings.Contains(err.Error(), "MFA required") {
+			t.Errorf("Expected MFA required error, got: %v", err)
+		}
+	})
+
+	t.Run("auth record field is ignored", func(t *testing.T) {
+		err := hookFunc(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 1}, &models.RequestInfo{AuthRecord: flaggedUser}))
+		if err == nil || !strings.Contains(err.Error(), "MFA required") {
+			t.Errorf("Expected MFA required error, got: %v", err)
+		}
+	})
+
+	t.Run("MFA context flag may change the field", func(t *testing.T) {
+		event := newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 1}, &models.RequestInfo{AuthRecord: plainUser})
+		event.HttpContext.Set(MFAContextKey, true)
+
+		if err := hookFunc(event); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("MFA doesn't unlock other immutable fields", func(t *testing.T) {
+		event := newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}, &models.RequestInfo{AuthRecord: plainUser})
+		event.HttpContext.Set(MFAContextKey, true)
+
+		err := hookFunc(event)
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package pbimmutable
+
+import (
+	"errors"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// MakeModelImmutable returns the MakeImmutable checks for args as a handler of PocketBase's
+// OnModelBeforeUpdate hook, so that they also apply to updates saved by server code, e.g.
+// app.Dao().SaveRecord(record) in a background job, which never run the record request hooks:
+//
+//	app.OnModelBeforeUpdate("invoices").Add(pbimmutable.MakeModelImmutable(app, "number", "total"))
+//
+// The model hook fires for every save, API updates included, so it replaces MakeImmutable
+// rather than complementing it; binding both checks API updates twice.
+//
+// The model event carries no HTTP request, so the request aware options never match: the
+// WithAllowSuperusers, WithAllowedActors and WithQueryParamOverride bypasses don't apply and
+// WithRequireMFA always rejects. The original record is fetched with the Dao of the save, so
+// it reflects earlier writes of the same transaction, and the additional writes of the checks
+// use that Dao too. The callback runs after the checks, before the record is saved; its error
+// aborts the save. The model hook can't run code after the save, so WithRunAfterCommit is a
+// setup error. Models other than records are ignored.
+func MakeModelImmutable(app core.App, args ...interface{}) func(e *core.ModelEvent) error {
+	cfg := parseArgs("MakeModelImmutable", args)
+	if cfg.setupErr == nil && cfg.runAfterCommit {
+		cfg.setupErr = errors.New("pbimmutable.MakeModelImmutable: WithRunAfterCommit is not supported, the callback runs before the save")
+	}
+
+	return func(e *core.ModelEvent) error {
+		record, ok := e.Model.(*models.Record)
+		if !ok {
+			return nil
+		}
+
+		re := &core.RecordEvent{App: app, Record: record}
+		ctx := eventContext(re)
+
+		checked, err := cfg.check(ctx, re, e.Dao)
+		if err != nil {
+			return err
+		}
+
+		if checked.inTx != nil {
+			dao := e.Dao
+			if dao == nil {
+				dao = app.Dao()
+			}
+			if err := checked.inTx(dao); err != nil {
+				return err
+			}
+		}
+
+		if cfg.callback != nil {
+			return cfg.runCallback(ctx, re, checked.original)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeModelImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "model_test")
+	initialRecord.Set("value", 10)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	var callbackCalls int
+	app.OnModelBeforeUpdate(coll.Name).Add(MakeModelImmutable(app, "name", func(e *core.RecordEvent) error {
+		callbackCalls++
+		if e.Record.GetString("status") == "fail" {
+			return errors.New("callback failure")
+		}
+		return nil
+	}))
+
+	tests := []struct {
+		name                string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"server save changing a frozen field", map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"server save of mutable fields", map[string]interface{}{"value": 20}, ""},
+		{"callback error aborts the save", map[string]interface{}{"status": "fail"}, "callback failure"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			record, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+			if err != nil {
+				t.Fatalf("Failed to load record: %v", err)
+			}
+			for k, v := range tc.updates {
+				record.Set(k, v)
+			}
+
+			err = app.Dao().SaveRecord(record)
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	stored, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+	if err != nil {
+		t.Fatalf("Failed to load record: %v", err)
+	}
+	if stored.GetString("name") != "model_test" || stored.GetString("status") == "fail" {
+		t.Errorf("Expected the rejected saves not to be stored, got name %q and status %q", stored.GetString("name"), stored.GetString("status"))
+	}
+	if callbackCalls != 2 {
+		t.Errorf("Expected the callback to run for the 2 saves that passed the checks, got %d", callbackCalls)
+	}
+
+	t.Run("other models are ignored", func(t *testing.T) {
+		err := MakeModelImmutable(app, "name")(&core.ModelEvent{BaseModelEvent: core.BaseModelEvent{Model: coll}})
+		if err != nil {
+			t.Errorf("Expected no error for a collection model, got: %v", err)
+		}
+	})
+
+	t.Run("run after commit is a setup error", func(t *testing.T) {
+		record, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to load record: %v", err)
+		}
+
+		err = MakeModelImmutable(app, "name", WithRunAfterCommit())(&core.ModelEvent{BaseModelEvent: core.BaseModelEvent{Model: record}})
+		if err == nil || !strings.Contains(err.Error(), "MakeModelImmutable setup error: pbimmutable.MakeModelImmutable: WithRunAfterCommit is not supported") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
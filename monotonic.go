@@ -0,0 +1,103 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// RuleMonotonic is the kind of the rule created by MakeMonotonic.
+const RuleMonotonic RuleKind = "monotonic"
+
+// Direction is the direction in which a monotonic field may move (see MakeMonotonic).
+type Direction int
+
+const (
+	// Increasing allows a field to stay equal or to grow.
+	Increasing Direction = iota + 1
+	// Decreasing allows a field to stay equal or to shrink.
+	Decreasing
+)
+
+// String returns the lowercase name of the direction.
+func (d Direction) String() string {
+	switch d {
+	case Increasing:
+		return "increasing"
+	case Decreasing:
+		return "decreasing"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// MakeMonotonic returns a hook that allows a number field to move only in the given
+// direction, e.g. MakeMonotonic("version", Increasing) for an append-only counter.
+// Keeping the current value is always allowed.
+//
+// Both values are coerced to float64 before they are compared. The field must be
+// a number field of the collection schema, otherwise the first update fails with
+// a setup error.
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeMonotonic(field string, direction Direction, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeMonotonic", args)
+	if cfg.setupErr == nil {
+		switch {
+		case field == "":
+			cfg.setupErr = errors.New("pbimmutable.MakeMonotonic: a field name must be provided")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeMonotonic: only a callback can be passed as additional argument")
+		case direction != Increasing && direction != Decreasing:
+			cfg.setupErr = fmt.Errorf("pbimmutable.MakeMonotonic: invalid direction %v", direction)
+		}
+	}
+
+	cfg.evaluate = monotonicEvaluator(cfg.name, RuleMonotonic, field, direction, false)
+
+	return newHook(cfg)
+}
+
+// monotonicEvaluator returns the evaluation of a monotonic number field for the hook
+// constructor name. When strict is set, keeping the current value is a violation too.
+func monotonicEvaluator(name string, kind RuleKind, field string, direction Direction, strict bool) func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+	return func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		pending := e.Record
+		if schemaField := pending.Schema().GetFieldByName(field); schemaField == nil || schemaField.Type != schema.FieldTypeNumber {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("%s setup error: '%s' is not a number field of collection %s.", name, field, pending.Collection().Name), nil)
+		}
+
+		change := newChange(original, pending, field, kind)
+
+		from, _ := toFloat(change.Old)
+		to, _ := toFloat(change.New)
+		switch {
+		case direction == Increasing && to < from:
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' can't decrease (from %v to %v).", field, from, to)
+		case direction == Decreasing && to > from:
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' can't increase (from %v to %v).", field, from, to)
+		case strict && to == from && direction == Increasing:
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' must increase on every update (currently %v).", field, from)
+		case strict && to == from && direction == Decreasing:
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' must decrease on every update (currently %v).", field, from)
+		}
+		if change.Violated {
+			change.Details = map[string]any{
+				"from":      from,
+				"to":        to,
+				"direction": direction.String(),
+			}
+		}
+
+		return ChangeSet{change}, nil
+	}
+}
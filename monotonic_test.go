@@ -0,0 +1,55 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeMonotonic(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "monotonic_test")
+	initialRecord.Set("value", 10)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		field               string
+		direction           Direction
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"increasing grows", "value", Increasing, map[string]interface{}{"value": 11}, ""},
+		{"increasing equal", "value", Increasing, map[string]interface{}{"value": "10"}, ""},
+		{"increasing shrinks", "value", Increasing, map[string]interface{}{"value": 9.5}, "Field 'value' can't decrease (from 10 to 9.5)."},
+		{"decreasing shrinks", "value", Decreasing, map[string]interface{}{"value": 3}, ""},
+		{"decreasing grows", "value", Decreasing, map[string]interface{}{"value": 12}, "Field 'value' can't increase (from 10 to 12)."},
+		{"other field changed", "value", Increasing, map[string]interface{}{"status": "done"}, ""},
+		{"text field", "name", Increasing, map[string]interface{}{}, "MakeMonotonic setup error: 'name' is not a number field of collection test_items."},
+		{"unknown field", "missing", Increasing, map[string]interface{}{}, "'missing' is not a number field"},
+		{"invalid direction", "value", Direction(0), map[string]interface{}{}, "pbimmutable.MakeMonotonic: invalid direction Direction(0)"},
+		{"empty field", "", Increasing, map[string]interface{}{}, "a field name must be provided"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeMonotonic(tc.field, tc.direction)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// MakeMutable returns a hook with the inverse semantics of MakeImmutable:
+// every non-system field is treated as immutable, except the listed ones.
+//
+// It accepts the same arguments as MakeImmutable and reports the same error when
+// a frozen field is changed. Without field names nothing is frozen, so MakeMutable()
+// only runs the optional callback (the natural inverse of MakeImmutable() freezing everything).
+// Listed names that aren't fields of the collection are reported as a setup error, so a
+// misspelled name can't silently leave the intended field frozen.
+//
+// Usage examples:
+// MakeMutable("status", "note")             // Only status and note can change
+// MakeMutable("status", myCallback)         // Only status can change, and a callback
+// MakeMutable("status", myOriginalCallback) // The callback receives the original record
+func MakeMutable(args ...interface{}) func(e *core.RecordEvent) error {
+	var mutable []string
+	for _, arg := range args {
+		if name, ok := arg.(string); ok {
+			mutable = append(mutable, name)
+		}
+	}
+
+	cfg := parseArgs("MakeMutable", args)
+
+	cfg.resolveFields = func(pending *models.Record) []string {
+		if len(mutable) == 0 {
+			return nil
+		}
+
+		allowed := expandFieldPatterns(pending, mutable)
+
+		var fields []string
+		for _, name := range userFields(pending) {
+			if !containsString(allowed, name) {
+				fields = append(fields, name)
+			}
+		}
+		return fields
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,97 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeMutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "mutable_test")
+	initialRecord.Set("value", 100)
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		args                []interface{}
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"listed field can change", []interface{}{"status"}, map[string]interface{}{"status": "inactive"}, ""},
+		{"several listed fields can change", []interface{}{"status", "description"}, map[string]interface{}{"status": "inactive", "description": "new"}, ""},
+		{"unlisted field is frozen", []interface{}{"status"}, map[string]interface{}{"value": 200}, "Attempt to modify immutable field 'value'"},
+		{"no changes", []interface{}{"status"}, map[string]interface{}{}, ""},
+		{"no field names freezes nothing", []interface{}{}, map[string]interface{}{"name": "changed", "value": 1}, ""},
+		{"callback error", []interface{}{"status", func(e *core.RecordEvent) error { return errors.New("callback failed") }}, map[string]interface{}{"status": "inactive"}, "user callback failed, record changes were not saved: callback failed"},
+		{"unknown listed field", []interface{}{"stauts"}, map[string]interface{}{"status": "inactive"}, `MakeMutable setup error: pbimmutable.MakeMutable: unknown fields "stauts" in collection test_items`},
+		{"unknown listed field with callback", []interface{}{"stauts", func(e *core.RecordEvent) error { return nil }}, map[string]interface{}{}, `unknown fields "stauts" in collection test_items`},
+		{"invalid argument", []interface{}{"status", 123}, map[string]interface{}{}, "MakeMutable setup error: pbimmutable.MakeMutable: invalid argument type int at position 1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeMutable(tc.args...)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tc.expectErrorContains)
+				} else if !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("callback with listed fields", func(t *testing.T) {
+		var received *models.Record
+		hookFunc := MakeMutable("status", "description", func(e *core.RecordEvent, original *models.Record) error {
+			received = original
+			return nil
+		})
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "inactive"})); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if received == nil || received.GetString("status") != "active" {
+			t.Errorf("Expected the callback to receive the original record, got %v", received)
+		}
+
+		received = nil
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "inactive", "name": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected the unlisted field to be frozen, got: %v", err)
+		}
+		if received != nil {
+			t.Errorf("Expected the callback not to run for a rejected update")
+		}
+	})
+
+	t.Run("no field names still runs the callback", func(t *testing.T) {
+		called := false
+		hookFunc := MakeMutable(func(e *core.RecordEvent) error {
+			called = true
+			return nil
+		})
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"})); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !called {
+			t.Errorf("Expected the callback to be called")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// RuleNonDecreasing is the kind of the rule created by MakeNonDecreasing.
+const RuleNonDecreasing RuleKind = "non_decreasing"
+
+// Boundary defines whether MakeNonDecreasing accepts the original value itself.
+type Boundary int
+
+const (
+	// GreaterOrEqual accepts values at or above the original value (the default).
+	GreaterOrEqual Boundary = iota + 1
+	// StrictlyGreater only accepts values above the original value, so every update
+	// must raise the field.
+	StrictlyGreater
+)
+
+// String returns the comparison operator of the boundary.
+func (b Boundary) String() string {
+	switch b {
+	case GreaterOrEqual:
+		return ">="
+	case StrictlyGreater:
+		return ">"
+	default:
+		return fmt.Sprintf("Boundary(%d)", int(b))
+	}
+}
+
+// MakeNonDecreasing returns a hook that keeps a number field from dropping below its
+// original value, e.g. MakeNonDecreasing("creditLimit") for a limit that anyone can raise
+// but that can only be lowered through a separate flow (e.g. with the hooks disabled).
+//
+// It is MakeMonotonic(field, Increasing) with an explicit boundary. By default any value
+// at or above the original value is accepted (GreaterOrEqual), so updates that don't touch
+// the field pass. Pass StrictlyGreater to require every update to raise the field:
+//
+//	MakeNonDecreasing("revision", StrictlyGreater)
+//
+// The values are compared like in MakeMonotonic, and the field must be a number field of
+// the collection schema, otherwise the first update fails with a setup error.
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeNonDecreasing(field string, args ...interface{}) func(e *core.RecordEvent) error {
+	boundary := GreaterOrEqual
+	var boundaries int
+	rest := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if b, ok := arg.(Boundary); ok {
+			boundary = b
+			boundaries++
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	cfg := parseArgs("MakeNonDecreasing", rest)
+	if cfg.setupErr == nil {
+		switch {
+		case field == "":
+			cfg.setupErr = errors.New("pbimmutable.MakeNonDecreasing: a field name must be provided")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeNonDecreasing: only a boundary and a callback can be passed as additional arguments")
+		case boundaries > 1:
+			cfg.setupErr = errors.New("pbimmutable.MakeNonDecreasing: only one boundary can be provided")
+		case boundary != GreaterOrEqual && boundary != StrictlyGreater:
+			cfg.setupErr = fmt.Errorf("pbimmutable.MakeNonDecreasing: invalid boundary %v", boundary)
+		}
+	}
+
+	cfg.evaluate = monotonicEvaluator(cfg.name, RuleNonDecreasing, field, Increasing, boundary == StrictlyGreater)
+
+	return newHook(cfg)
+}
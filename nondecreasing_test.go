@@ -0,0 +1,59 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeNonDecreasing(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "non_decreasing_test")
+	initialRecord.Set("value", 1000)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		field               string
+		args                []interface{}
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"raised", "value", nil, map[string]interface{}{"value": 1500}, ""},
+		{"equal", "value", nil, map[string]interface{}{"value": "1000"}, ""},
+		{"other field changed", "value", nil, map[string]interface{}{"status": "done"}, ""},
+		{"lowered", "value", nil, map[string]interface{}{"value": 999.5}, "Field 'value' can't decrease (from 1000 to 999.5)."},
+		{"explicit greater or equal", "value", []interface{}{GreaterOrEqual}, map[string]interface{}{"value": 1000}, ""},
+		{"strictly greater raised", "value", []interface{}{StrictlyGreater}, map[string]interface{}{"value": 1001}, ""},
+		{"strictly greater equal", "value", []interface{}{StrictlyGreater}, map[string]interface{}{"value": 1000}, "Field 'value' must increase on every update (currently 1000)."},
+		{"strictly greater lowered", "value", []interface{}{StrictlyGreater}, map[string]interface{}{"value": 10}, "Field 'value' can't decrease (from 1000 to 10)."},
+		{"text field", "name", nil, map[string]interface{}{}, "MakeNonDecreasing setup error: 'name' is not a number field of collection test_items."},
+		{"unknown field", "missing", nil, map[string]interface{}{}, "'missing' is not a number field"},
+		{"invalid boundary", "value", []interface{}{Boundary(0)}, map[string]interface{}{}, "pbimmutable.MakeNonDecreasing: invalid boundary Boundary(0)"},
+		{"two boundaries", "value", []interface{}{GreaterOrEqual, StrictlyGreater}, map[string]interface{}{}, "only one boundary can be provided"},
+		{"field names", "value", []interface{}{"status"}, map[string]interface{}{}, "only a boundary and a callback can be passed"},
+		{"empty field", "", nil, map[string]interface{}{}, "a field name must be provided"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeNonDecreasing(tc.field, tc.args...)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
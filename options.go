@@ -0,0 +1,64 @@
+package pbimmutable
+
+// Option customizes the behavior of a hook.
+//
+// Options can be mixed with the field names and the optional callback
+// passed to MakeImmutable and the other hook constructors, e.g.
+// MakeImmutable("iban", WithRequireMFA("iban")).
+type Option func(cfg *hookConfig)
+
+// WithRunAfterCommit runs the callback after e.Next() committed the update,
+// instead of right before it (see ImmutableConfig.RunAfterCommit).
+func WithRunAfterCommit() Option {
+	return func(cfg *hookConfig) {
+		cfg.runAfterCommit = true
+	}
+}
+
+// WithSystemFields treats the fields for which isSystem returns true like the PocketBase
+// system fields (see IsSystemField): they are managed by the backend and never checked,
+// even when they are listed explicitly, e.g. for fields prefixed with "_":
+//
+//	WithSystemFields(func(name string) bool { return strings.HasPrefix(name, "_") })
+//
+// The default system fields are always kept. Multiple calls are combined.
+func WithSystemFields(isSystem func(name string) bool) Option {
+	return func(cfg *hookConfig) {
+		if isSystem == nil {
+			return
+		}
+
+		previous := cfg.cmp.systemFields
+		if previous == nil {
+			cfg.cmp.systemFields = isSystem
+			return
+		}
+		cfg.cmp.systemFields = func(name string) bool {
+			return previous(name) || isSystem(name)
+		}
+	}
+}
+
+// addFields appends the provided field names to the explicitly checked fields,
+// unless all user-defined fields are checked anyway (no explicit field names).
+func (cfg *hookConfig) addFields(names ...string) {
+	if len(cfg.fields) == 0 {
+		return
+	}
+
+	for _, name := range names {
+		if !containsString(cfg.fields, name) {
+			cfg.fields = append(cfg.fields, name)
+		}
+	}
+}
+
+// containsString reports whether list contains value.
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
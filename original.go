@@ -0,0 +1,25 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// trackedOriginal returns the original state tracked by the record (Record.OriginalCopy),
+// or nil if the record doesn't track a usable original state.
+//
+// A record tracks the data it was first loaded with. Only when that data is a persisted
+// row, recognizable by its id matching the record id, it can replace the database fetch.
+// Records that were built in memory (e.g. with NewRecord or CleanCopy) don't carry their
+// id in the loaded data and are always compared against a freshly fetched original.
+func trackedOriginal(record *models.Record) *models.Record {
+	if record.IsNew() || record.Id == "" {
+		return nil
+	}
+
+	original := record.OriginalCopy()
+	if original == nil || original.Id != record.Id {
+		return nil
+	}
+
+	return original
+}
@@ -0,0 +1,108 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestTrackedOriginal(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "original_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	t.Run("fetched record tracks its original", func(t *testing.T) {
+		record, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to fetch record: %v", err)
+		}
+		record.Set("name", "changed")
+		record.Set("value", 200)
+
+		original := trackedOriginal(record)
+		if original == nil {
+			t.Fatalf("Expected a tracked original")
+		}
+		if original.GetString("name") != "original_test" || original.GetFloat("value") != 100 {
+			t.Errorf("Expected the persisted values, got %v", original.PublicExport())
+		}
+	})
+
+	t.Run("in memory records fall back to the database", func(t *testing.T) {
+		if trackedOriginal(initialRecord.CleanCopy()) != nil {
+			t.Errorf("Expected no tracked original for a clean copy")
+		}
+		if trackedOriginal(models.NewRecord(coll)) != nil {
+			t.Errorf("Expected no tracked original for a new record")
+		}
+	})
+
+	t.Run("hook uses the tracked original", func(t *testing.T) {
+		record, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to fetch record: %v", err)
+		}
+
+		// change the stored row behind the loaded record, the hook must still compare
+		// against the state the record was loaded with
+		stored := initialRecord.CleanCopy()
+		stored.Set("value", 300)
+		if err := app.Dao().SaveRecord(stored); err != nil {
+			t.Fatalf("Failed to update record: %v", err)
+		}
+
+		record.Set("name", "changed")
+		err = MakeImmutable("name")(&core.RecordEvent{App: app, Record: record})
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+
+		record.Set("name", "original_test")
+		if err := MakeImmutable("name", "value")(&core.RecordEvent{App: app, Record: record}); err != nil {
+			t.Errorf("Expected no error against the tracked original, got: %v", err)
+		}
+	})
+}
+
+func BenchmarkOriginalRecord(b *testing.B) {
+	app, coll, cleanup := setupTestAppWithCollection(b)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "original_bench")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		b.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	record, err := app.Dao().FindRecordById(coll.Id, initialRecord.Id)
+	if err != nil {
+		b.Fatalf("Failed to fetch record: %v", err)
+	}
+
+	b.Run("database fetch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := app.Dao().FindRecordById(coll.Id, record.Id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("tracked original", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if trackedOriginal(record) == nil {
+				b.Fatal("missing tracked original")
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// RuleOwnerOnly is the kind of the rule created by MakeImmutableUnlessOwner.
+const RuleOwnerOnly RuleKind = "owner_only"
+
+// MakeImmutableUnlessOwner returns a hook that freezes the provided fields (or all
+// user-defined fields when none are given) for everyone except the owner of the record,
+// e.g. to let only the author edit the notes of a shared document:
+//
+//	MakeImmutableUnlessOwner("author", "notes")
+//
+// ownerField must be a relation field pointing to the auth collection of the editors.
+// Only auth records of that collection can be owners. The owner is read from the original
+// record, so an update can't claim ownership by changing ownerField. With a multiple
+// relation every listed record is an owner.
+//
+// Guests, superusers and non-HTTP events are treated as non-owners.
+func MakeImmutableUnlessOwner(ownerField string, fields ...string) func(e *core.RecordEvent) error {
+	args := make([]interface{}, len(fields))
+	for i, field := range fields {
+		args[i] = field
+	}
+
+	cfg := parseArgs("MakeImmutableUnlessOwner", args)
+	if cfg.setupErr == nil && ownerField == "" {
+		cfg.setupErr = errors.New("pbimmutable.MakeImmutableUnlessOwner: an owner field must be provided")
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		schemaField := original.Schema().GetFieldByName(ownerField)
+		if schemaField == nil || schemaField.Type != schema.FieldTypeRelation {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeImmutableUnlessOwner setup error: '%s' is not a relation field of collection %s.", ownerField, original.Collection().Name), nil)
+		}
+		options, _ := schemaField.Options.(*schema.RelationOptions)
+		if options == nil {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeImmutableUnlessOwner setup error: missing relation options for '%s'.", ownerField), nil)
+		}
+
+		owner := isRecordOwner(e, original, ownerField, options.CollectionId)
+
+		changes := cfg.cmp.evaluate(original, e.Record, cfg.fields)
+		for i, change := range changes {
+			changes[i].RuleKind = RuleOwnerOnly
+			if !change.Violated {
+				continue
+			}
+
+			if owner {
+				changes[i].Violated = false
+				continue
+			}
+
+			changes[i].Message = fmt.Sprintf("Field '%s' can only be changed by the owner of the record.", change.Field)
+		}
+		return changes, nil
+	}
+
+	return newHook(cfg)
+}
+
+// isRecordOwner reports whether the auth record of the event request is referenced
+// by the owner relation field of the record, whose related collection is ownerCollectionId.
+// An auth record of another collection is never an owner, even if its id matches.
+// Guests and non-HTTP events are never owners.
+func isRecordOwner(e *core.RecordEvent, record *models.Record, ownerField string, ownerCollectionId string) bool {
+	info := requestInfo(e)
+	if info == nil || info.AuthRecord == nil {
+		return false
+	}
+
+	if info.AuthRecord.Collection().Id != ownerCollectionId {
+		return false
+	}
+
+	owners, _ := toStrings(record.Get(ownerField))
+	return containsString(owners, info.AuthRecord.Id)
+}
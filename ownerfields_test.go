@@ -0,0 +1,137 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeImmutableUnlessOwner(t *testing.T) {
+	app, baseColl, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	members := &models.Collection{
+		Name: "test_members",
+		Type: models.CollectionTypeAuth,
+	}
+	if err := app.Dao().SaveCollection(members); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newMember := func(username string) *models.Record {
+		record := models.NewRecord(members)
+		record.SetUsername(username)
+		record.SetPassword("1234567890")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save member: %v", err)
+		}
+		return record
+	}
+
+	owner := newMember("owner")
+	collaborator := newMember("collaborator")
+
+	documents := &models.Collection{
+		Name: "test_documents",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "notes", Type: schema.FieldTypeText},
+			&schema.SchemaField{
+				Name:    "author",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{CollectionId: members.Id},
+			},
+		),
+	}
+	if err := app.Dao().SaveCollection(documents); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	document := models.NewRecord(documents)
+	document.Set("title", "Draft")
+	document.Set("notes", "first notes")
+	document.Set("author", owner.Id)
+	if err := app.Dao().SaveRecord(document); err != nil {
+		t.Fatalf("Failed to save document: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		info                *models.RequestInfo
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"owner changes notes", &models.RequestInfo{AuthRecord: owner}, map[string]interface{}{"notes": "changed"}, ""},
+		{"collaborator changes notes", &models.RequestInfo{AuthRecord: collaborator}, map[string]interface{}{"notes": "changed"}, "Field 'notes' can only be changed by the owner of the record."},
+		{"collaborator changes title", &models.RequestInfo{AuthRecord: collaborator}, map[string]interface{}{"title": "changed"}, ""},
+		{"guest changes notes", &models.RequestInfo{}, map[string]interface{}{"notes": "changed"}, "Field 'notes' can only be changed by the owner"},
+		{"claiming ownership", &models.RequestInfo{AuthRecord: collaborator}, map[string]interface{}{"author": collaborator.Id, "notes": "changed"}, "Field 'notes' can only be changed by the owner"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableUnlessOwner("author", "notes")
+
+			err := hookFunc(newRequestUpdateEvent(app, document, tc.updates, tc.info))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("auth record of another collection with the owner id", func(t *testing.T) {
+		guests := &models.Collection{
+			Name: "test_guests",
+			Type: models.CollectionTypeAuth,
+		}
+		if err := app.Dao().SaveCollection(guests); err != nil {
+			t.Fatalf("Failed to save collection: %v", err)
+		}
+
+		impostor := models.NewRecord(guests)
+		impostor.Id = owner.Id
+		impostor.SetUsername("impostor")
+		impostor.SetPassword("1234567890")
+		if err := app.Dao().SaveRecord(impostor); err != nil {
+			t.Fatalf("Failed to save impostor: %v", err)
+		}
+
+		err := MakeImmutableUnlessOwner("author", "notes")(newRequestUpdateEvent(app, document, map[string]interface{}{"notes": "changed"}, &models.RequestInfo{AuthRecord: impostor}))
+		if err == nil || !strings.Contains(err.Error(), "Field 'notes' can only be changed by the owner") {
+			t.Errorf("Expected owner error, got: %v", err)
+		}
+	})
+
+	t.Run("non-HTTP event", func(t *testing.T) {
+		err := MakeImmutableUnlessOwner("author", "notes")(newUpdateEvent(app, document, map[string]interface{}{"notes": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), "can only be changed by the owner") {
+			t.Errorf("Expected owner error, got: %v", err)
+		}
+	})
+
+	t.Run("setup errors", func(t *testing.T) {
+		baseRecord := models.NewRecord(baseColl)
+		baseRecord.Set("name", "owner_setup")
+		if err := app.Dao().SaveRecord(baseRecord); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+
+		err := MakeImmutableUnlessOwner("", "name")(newUpdateEvent(app, baseRecord, map[string]interface{}{"name": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), "an owner field must be provided") {
+			t.Errorf("Expected missing owner field error, got: %v", err)
+		}
+
+		err = MakeImmutableUnlessOwner("status", "name")(newUpdateEvent(app, baseRecord, map[string]interface{}{"name": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), "'status' is not a relation field of collection test_items") {
+			t.Errorf("Expected relation field error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// MakeImmutableUntilParent returns a hook that freezes the provided fields (or all
+// user-defined fields when none are given) of a child record once the parent record
+// referenced by relationField has parentField equal to parentValue, e.g. invoice line
+// items that can't change anymore once their invoice is finalized:
+//
+//	MakeImmutableUntilParent("invoice", "status", "finalized", "amount", "product")
+//
+// The parent is the record referenced by the original child, so moving the child to
+// another parent doesn't escape the freeze. With several parents (a multiple relation),
+// the fields are frozen when any of them matches; children without a parent stay editable.
+// parentValue is compared with the stored value like frozen fields, in the type of parentField.
+//
+// Every update reads the parents from the database, on top of the original record lookup.
+// The parent state is never cached (see SetFieldCache, which only caches field names), so
+// a parent finalized by another request is seen right away. A parent that can't be loaded
+// (e.g. it was deleted) fails closed: the update is rejected.
+//
+// relationField must be a relation field of the collection schema and parentField must
+// exist in the related collection, otherwise the first update fails with a setup error.
+func MakeImmutableUntilParent(relationField, parentField, parentValue string, fields ...string) func(e *core.RecordEvent) error {
+	args := make([]interface{}, len(fields))
+	for i, field := range fields {
+		args[i] = field
+	}
+
+	cfg := parseArgs("MakeImmutableUntilParent", args)
+	if cfg.setupErr == nil && (relationField == "" || parentField == "") {
+		cfg.setupErr = errors.New("pbimmutable.MakeImmutableUntilParent: the relation and parent field names must be provided")
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		field := original.Schema().GetFieldByName(relationField)
+		if field == nil || field.Type != schema.FieldTypeRelation {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeImmutableUntilParent setup error: '%s' is not a relation field of collection %s.", relationField, original.Collection().Name), nil)
+		}
+		options, _ := field.Options.(*schema.RelationOptions)
+		if options == nil {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeImmutableUntilParent setup error: missing relation options for '%s'.", relationField), nil)
+		}
+
+		frozen := false
+		parentIds, _ := toStrings(original.Get(relationField))
+		for _, parentId := range parentIds {
+			parent, err := e.App.Dao().FindRecordById(options.CollectionId, parentId)
+			if err != nil {
+				return nil, apis.NewBadRequestError(fmt.Sprintf("The '%s' parent record %s of record %s can't be loaded, so its fields are treated as immutable.", relationField, parentId, original.Id), err)
+			}
+			if parent.Schema().GetFieldByName(parentField) == nil {
+				return nil, apis.NewBadRequestError(fmt.Sprintf("MakeImmutableUntilParent setup error: '%s' is not a field of collection %s.", parentField, parent.Collection().Name), nil)
+			}
+
+			if cfg.cmp.equalValues(parent, parentField, parentValue, parent.Get(parentField)) {
+				frozen = true
+				break
+			}
+		}
+		if !frozen {
+			return nil, nil
+		}
+
+		return cfg.cmp.evaluate(original, e.Record, cfg.fields), nil
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,96 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestMakeImmutableUntilParent(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	lineItems := &models.Collection{
+		Name: "test_parent_line_items",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "invoice", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{CollectionId: coll.Id, MaxSelect: types.Pointer(1)}},
+			&schema.SchemaField{Name: "amount", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "note", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(lineItems); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newParent := func(status string) string {
+		parent := models.NewRecord(coll)
+		parent.Set("name", "parent_invoice")
+		parent.Set("status", status)
+		if err := app.Dao().SaveRecord(parent); err != nil {
+			t.Fatalf("Failed to save parent: %v", err)
+		}
+		return parent.Id
+	}
+
+	newLineItem := func(parentId string) *models.Record {
+		record := models.NewRecord(lineItems)
+		record.Set("invoice", parentId)
+		record.Set("amount", 10)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save line item: %v", err)
+		}
+		return record
+	}
+
+	finalized := newParent("finalized")
+	draft := newParent("draft")
+
+	tests := []struct {
+		name                string
+		parentId            string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"finalized parent freezes the fields", finalized, map[string]interface{}{"amount": 20}, "Attempt to modify immutable field 'amount'"},
+		{"finalized parent keeps other fields editable", finalized, map[string]interface{}{"note": "checked"}, ""},
+		{"draft parent", draft, map[string]interface{}{"amount": 20}, ""},
+		{"moving to a draft parent is frozen", finalized, map[string]interface{}{"invoice": draft}, "Attempt to modify immutable field 'invoice'"},
+		{"no parent", "", map[string]interface{}{"amount": 20}, ""},
+		{"missing parent fails closed", "missing00000001", map[string]interface{}{"note": "checked"}, "parent record missing00000001"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableUntilParent("invoice", "status", "finalized", "amount", "invoice")
+
+			err := hookFunc(newUpdateEvent(app, newLineItem(tc.parentId), tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("setup errors", func(t *testing.T) {
+		record := newLineItem(finalized)
+		for expected, hookFunc := range map[string]func(e *core.RecordEvent) error{
+			"'note' is not a relation field":      MakeImmutableUntilParent("note", "status", "finalized"),
+			"'stage' is not a field":              MakeImmutableUntilParent("invoice", "stage", "finalized"),
+			"the relation and parent field names": MakeImmutableUntilParent("", "status", "finalized"),
+		} {
+			err := hookFunc(newUpdateEvent(app, record, nil))
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error containing %q, got: %v", expected, err)
+			}
+		}
+	})
+}
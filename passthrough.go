@@ -0,0 +1,37 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// Passthrough returns a hook that freezes nothing: it only runs the optional callback
+// and e.Next(). It accepts the same arguments as MakeImmutable but ignores the field
+// names, so it can replace a hook when protection is turned off (e.g. by a feature flag
+// or in some environments) without changing how the hooks are registered:
+//
+//	hook := pbimmutable.MakeImmutable("amount", "iban", notify)
+//	if !protectOrders {
+//		hook = pbimmutable.Passthrough("amount", "iban", notify)
+//	}
+//	app.OnRecordUpdate("orders").Add(hook)
+//
+// Options that control the callback, like WithRunAfterCommit, keep working. The original
+// record is only fetched when something needs it: a callback that takes it, WithRunAfterCommit
+// (to roll back on ErrRollbackRequested) or WithOptimisticConcurrency.
+//
+// A custom hook that doesn't need a callback only has to continue the chain:
+//
+//	func(e *core.RecordEvent) error {
+//		return e.Next()
+//	}
+func Passthrough(args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("Passthrough", args)
+	cfg.fields = nil // the field names are not validated either
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		return nil, nil
+	}
+	cfg.skipOriginal = !cfg.callbackTakesOriginal && !cfg.runAfterCommit && !cfg.optimisticConcurrency
+
+	return newHook(cfg)
+}
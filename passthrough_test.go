@@ -0,0 +1,100 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestPassthrough(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "passthrough_test")
+	initialRecord.Set("value", 100)
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	var calls int
+	callback := func(e *core.RecordEvent, original *models.Record) error {
+		calls++
+		if original == nil || original.GetString("name") != "passthrough_test" {
+			t.Errorf("Expected the callback to receive the original record, got %v", original)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name                string
+		args                []interface{}
+		updates             map[string]interface{}
+		expectErrorContains string
+		expectCalls         int
+	}{
+		{"field names are ignored", []interface{}{"name", "value"}, map[string]interface{}{"name": "changed", "value": 1}, "", 0},
+		{"unknown field names are ignored", []interface{}{"nmae"}, map[string]interface{}{"name": "changed"}, "", 0},
+		{"callback runs", []interface{}{"name", callback}, map[string]interface{}{"name": "changed"}, "", 1},
+		{"callback error", []interface{}{func(e *core.RecordEvent) error { return errors.New("callback failed") }}, map[string]interface{}{"name": "changed"}, "user callback failed, record changes were not saved: callback failed", 0},
+		{"invalid argument", []interface{}{"name", 123}, map[string]interface{}{}, "Passthrough setup error: pbimmutable.Passthrough: invalid argument type int at position 1", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = 0
+			hookFunc := Passthrough(tc.args...)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if calls != tc.expectCalls {
+				t.Errorf("Expected %d callback calls, got %d", tc.expectCalls, calls)
+			}
+		})
+	}
+
+	t.Run("original record is only fetched when needed", func(t *testing.T) {
+		plain := func(e *core.RecordEvent) error { return nil }
+
+		tests := []struct {
+			name        string
+			args        []interface{}
+			expectFetch bool
+		}{
+			{"no callback", nil, false},
+			{"plain callback", []interface{}{plain}, false},
+			{"original callback", []interface{}{callback}, true},
+			{"run after commit", []interface{}{plain, WithRunAfterCommit()}, true},
+			{"optimistic concurrency", []interface{}{WithOptimisticConcurrency()}, true},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tracer := &testTracer{}
+				hookFunc := Passthrough(append(tc.args, WithTracer(tracer))...)
+
+				// a record without tracked original state, so that the original is fetched when needed
+				eventRecord := models.NewRecord(coll)
+				eventRecord.Id = initialRecord.Id
+				eventRecord.Set("name", "changed")
+
+				if err := hookFunc(&core.RecordEvent{App: app, Record: eventRecord}); err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				if fetched := tracer.find(SpanFetch) != nil; fetched != tc.expectFetch {
+					t.Errorf("Expected the original record to be fetched: %v, got %v", tc.expectFetch, fetched)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package pbimmutable
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// isFieldPattern reports whether name is a glob pattern (see path.Match), e.g. "audit_*",
+// rather than a field name. Dotted names are JSON paths and never patterns.
+func isFieldPattern(name string) bool {
+	return !strings.Contains(name, ".") && strings.ContainsAny(name, "*?[")
+}
+
+// matchFieldPattern returns the user-defined fields of the record matching the pattern.
+func matchFieldPattern(record *models.Record, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, name := range userFields(record) {
+		if ok, _ := path.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+	return matches, nil
+}
+
+// expandFieldPatterns replaces the patterns in names with the matching user-defined fields
+// of the record, keeping the order of the names and skipping duplicates.
+// Names without any pattern are returned unchanged.
+func expandFieldPatterns(record *models.Record, names []string) []string {
+	hasPattern := false
+	for _, name := range names {
+		if isFieldPattern(name) {
+			hasPattern = true
+			break
+		}
+	}
+	if !hasPattern {
+		return names
+	}
+
+	expanded := make([]string, 0, len(names))
+	for _, name := range names {
+		matches := []string{name}
+		if isFieldPattern(name) {
+			matches, _ = matchFieldPattern(record, name)
+		}
+		for _, match := range matches {
+			if !containsString(expanded, match) {
+				expanded = append(expanded, match)
+			}
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,85 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestFieldPatterns(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_audited_items",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "name", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "status", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "audit_createdBy", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "audit_source", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "pattern_test")
+	initialRecord.Set("audit_createdBy", "user1")
+	initialRecord.Set("audit_source", "web")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	t.Run("expand", func(t *testing.T) {
+		expanded := expandFieldPatterns(initialRecord, []string{"name", "audit_*", "audit_source"})
+		if strings.Join(expanded, ",") != "name,audit_createdBy,audit_source" {
+			t.Errorf("Unexpected expanded fields: %v", expanded)
+		}
+	})
+
+	tests := []struct {
+		name                string
+		args                []interface{}
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"pattern matches field", []interface{}{"audit_*"}, map[string]interface{}{"audit_source": "api"}, "Attempt to modify immutable field 'audit_source'"},
+		{"pattern ignores other fields", []interface{}{"audit_*"}, map[string]interface{}{"status": "done"}, ""},
+		{"mixed with literal names", []interface{}{"name", "audit_*"}, map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"single character pattern", []interface{}{"audit_sourc?"}, map[string]interface{}{"audit_source": "api"}, "Attempt to modify immutable field 'audit_source'"},
+		{"pattern without match", []interface{}{"name", "adit_*"}, map[string]interface{}{}, `field patterns "adit_*" match no field of collection test_audited_items`},
+		{"malformed pattern", []interface{}{"audit_["}, map[string]interface{}{}, `invalid field pattern "audit_["`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable(tc.args...)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("mutable pattern", func(t *testing.T) {
+		hookFunc := MakeMutable("audit_*")
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"audit_source": "api"})); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+}
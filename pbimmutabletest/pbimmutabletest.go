@@ -0,0 +1,61 @@
+// Package pbimmutabletest provides helpers to test record update hooks, such as the
+// hooks of the pbimmutable package, outside of a running PocketBase app.
+//
+// A hook only reaches e.Next() when the event is triggered through a hook chain.
+// Event runs a hook in such a chain and replaces the rest of it (the other hooks and
+// the save) with a function of the test, so that the callbacks and the commit path
+// of the hook can be tested:
+//
+//	event := pbimmutabletest.NewEvent(&core.RecordEvent{App: app, Record: record}, func(e *core.RecordEvent) error {
+//		order = append(order, "next")
+//		return nil
+//	})
+//	err := event.Run(pbimmutable.MakeImmutable("sku", callback))
+//	if !event.NextCalled() { ... }
+package pbimmutabletest
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// Event wraps a record event whose e.Next() calls a function of the test
+// and records the calls.
+type Event struct {
+	*core.RecordEvent
+
+	next  func(e *core.RecordEvent) error
+	calls int
+}
+
+// NewEvent returns an Event for e whose e.Next() calls next. A nil next makes
+// e.Next() succeed without doing anything.
+func NewEvent(e *core.RecordEvent, next func(e *core.RecordEvent) error) *Event {
+	return &Event{RecordEvent: e, next: next}
+}
+
+// Run triggers handler with the event. When handler calls e.Next(), the next function
+// of the event runs instead of the remaining hooks and the save. Run returns the error
+// of handler and can be called again, e.g. to run several hooks in turn.
+func (e *Event) Run(handler func(e *core.RecordEvent) error) error {
+	chain := &hook.Hook[*core.RecordEvent]{}
+	chain.Add(handler)
+
+	return chain.Trigger(e.RecordEvent, func(re *core.RecordEvent) error {
+		e.calls++
+		if e.next == nil {
+			return nil
+		}
+		return e.next(re)
+	})
+}
+
+// NextCalled reports whether e.Next() was called.
+func (e *Event) NextCalled() bool {
+	return e.calls > 0
+}
+
+// NextCalls returns how often e.Next() was called.
+func (e *Event) NextCalls() int {
+	return e.calls
+}
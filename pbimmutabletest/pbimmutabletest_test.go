@@ -0,0 +1,123 @@
+package pbimmutabletest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/USERNAME/pbimmutable"
+	"github.com/USERNAME/pbimmutable/pbimmutabletest"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestEvent(t *testing.T) {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatalf("Failed to init test app: %v", err)
+	}
+	defer app.Cleanup()
+
+	coll := &models.Collection{
+		Name: "test_products",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "sku", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	product := models.NewRecord(coll)
+	product.Set("sku", "SKU-1")
+	product.Set("title", "Product")
+	if err := app.Dao().SaveRecord(product); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	// newEvent returns an update event of the product whose e.Next() appends "next" to order
+	newEvent := func(updates map[string]any, order *[]string, nextErr error) *pbimmutabletest.Event {
+		record := product.CleanCopy()
+		for field, value := range updates {
+			record.Set(field, value)
+		}
+		return pbimmutabletest.NewEvent(&core.RecordEvent{App: app, Record: record}, func(e *core.RecordEvent) error {
+			*order = append(*order, "next")
+			return nextErr
+		})
+	}
+
+	callback := func(order *[]string) func(e *core.RecordEvent) error {
+		return func(e *core.RecordEvent) error {
+			*order = append(*order, "callback")
+			return nil
+		}
+	}
+
+	t.Run("callback runs before e.Next()", func(t *testing.T) {
+		var order []string
+		event := newEvent(map[string]any{"title": "Renamed"}, &order, nil)
+
+		if err := event.Run(pbimmutable.MakeImmutable("sku", callback(&order))); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !event.NextCalled() || event.NextCalls() != 1 {
+			t.Errorf("Expected e.Next() to be called once, got %d calls", event.NextCalls())
+		}
+		if strings.Join(order, ",") != "callback,next" {
+			t.Errorf("Expected the callback before e.Next(), got %v", order)
+		}
+	})
+
+	t.Run("callback runs after commit", func(t *testing.T) {
+		var order []string
+		event := newEvent(map[string]any{"title": "Renamed"}, &order, nil)
+
+		err := event.Run(pbimmutable.MakeImmutable("sku", callback(&order), pbimmutable.WithRunAfterCommit()))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if strings.Join(order, ",") != "next,callback" {
+			t.Errorf("Expected the callback after e.Next(), got %v", order)
+		}
+	})
+
+	t.Run("rejected update doesn't reach e.Next()", func(t *testing.T) {
+		var order []string
+		event := newEvent(map[string]any{"sku": "SKU-2"}, &order, nil)
+
+		err := event.Run(pbimmutable.MakeImmutable("sku", callback(&order)))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'sku'.") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+		if event.NextCalled() || len(order) > 0 {
+			t.Errorf("Expected neither the callback nor e.Next() to run, got %v", order)
+		}
+	})
+
+	t.Run("e.Next() error", func(t *testing.T) {
+		var order []string
+		errSave := errors.New("save failed")
+		event := newEvent(map[string]any{"title": "Renamed"}, &order, errSave)
+
+		err := event.Run(pbimmutable.MakeImmutable("sku", callback(&order), pbimmutable.WithRunAfterCommit()))
+		if !errors.Is(err, errSave) {
+			t.Errorf("Expected the e.Next() error, got: %v", err)
+		}
+		if strings.Join(order, ",") != "next" {
+			t.Errorf("Expected the after-commit callback not to run, got %v", order)
+		}
+	})
+
+	t.Run("nil next", func(t *testing.T) {
+		event := pbimmutabletest.NewEvent(&core.RecordEvent{App: app, Record: product.CleanCopy()}, nil)
+
+		if err := event.Run(pbimmutable.MakeImmutable("sku")); err != nil || !event.NextCalled() {
+			t.Errorf("Expected e.Next() to succeed, got called=%v err=%v", event.NextCalled(), err)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// fieldsFor returns the fields frozen by the default immutability check for the record
+// collection: the CollectionFields entry of the collection name, if any, or cfg.fields.
+//
+// ok is false when the collection entry is empty, i.e. nothing is frozen for it.
+func (cfg *hookConfig) fieldsFor(record *models.Record) (fields []string, ok bool) {
+	if cfg.collectionFields != nil {
+		if fields, listed := cfg.collectionFields[record.Collection().Name]; listed {
+			return fields, len(fields) > 0
+		}
+	}
+	return cfg.fields, true
+}
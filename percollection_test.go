@@ -0,0 +1,82 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestCollectionFields(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newCollection := func(name string) *models.Collection {
+		c := &models.Collection{
+			Name: name,
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{Name: "name", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "value", Type: schema.FieldTypeNumber},
+				&schema.SchemaField{Name: "status", Type: schema.FieldTypeText},
+			),
+		}
+		if err := app.Dao().SaveCollection(c); err != nil {
+			t.Fatalf("Failed to save collection: %v", err)
+		}
+		return c
+	}
+	contracts := newCollection("test_contracts")
+	drafts := newCollection("test_drafts")
+
+	newRecord := func(c *models.Collection) *models.Record {
+		record := models.NewRecord(c)
+		record.Set("name", "shared_hook_test")
+		record.Set("value", 10)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	sharedHook := MakeImmutableWith(ImmutableConfig{
+		Fields: []string{"name"},
+		CollectionFields: map[string][]string{
+			contracts.Name: {"name", "value"},
+			drafts.Name:    {},
+		},
+	})
+	builtHook := NewImmutable().Fields("name").CollectionFields(contracts.Name, "value").Build()
+
+	tests := []struct {
+		name                string
+		hookFunc            func(e *core.RecordEvent) error
+		collection          *models.Collection
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"listed collection uses its fields", sharedHook, contracts, map[string]interface{}{"value": 20}, "Attempt to modify immutable field 'value'"},
+		{"unlisted collection uses the default", sharedHook, coll, map[string]interface{}{"value": 20}, ""},
+		{"default fields of unlisted collection", sharedHook, coll, map[string]interface{}{"name": "changed"}, "Attempt to modify immutable field 'name'"},
+		{"empty entry freezes nothing", sharedHook, drafts, map[string]interface{}{"name": "changed", "value": 20}, ""},
+		{"builder entry replaces the default", builtHook, contracts, map[string]interface{}{"name": "changed"}, ""},
+		{"builder entry", builtHook, contracts, map[string]interface{}{"value": 20}, "Attempt to modify immutable field 'value'"},
+		{"unknown field of listed collection", MakeImmutableWith(ImmutableConfig{FreezeAll: true, CollectionFields: map[string][]string{contracts.Name: {"nmae"}}}), contracts, nil, `unknown fields "nmae"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.hookFunc(newUpdateEvent(app, newRecord(tc.collection), tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RulePinned is the kind of the rule created by MakePinned.
+const RulePinned RuleKind = "pinned"
+
+// MakePinned returns a hook that requires field to hold value after every save, e.g. a
+// "version" field pinned to the current schema version:
+//
+//	pin := pbimmutable.MakePinned("schemaVersion", 2)
+//	app.OnRecordCreate("documents").Add(pin)
+//	app.OnRecordUpdate("documents").Add(pin)
+//
+// Unlike MakeImmutable, which keeps whatever value is stored, the pending value is
+// compared against value and not against the original record: an update is rejected when
+// it leaves the field at any other value, even if the stored value already differed
+// (e.g. right after a schema migration). So a record with a "wrong" stored value can only
+// be updated together with the fix of the field, which MakeImmutable would reject instead.
+//
+// The same hook works for the create hook, where the new record must be created with value.
+// The values are compared in their stored form like frozen fields,
+// so 2 and "2" are equal for a number field. field must exist in the collection schema,
+// otherwise the first save fails with a setup error.
+//
+// An optional callback and options can be provided and behave the same as in MakeImmutable.
+func MakePinned(field string, value any, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakePinned", args)
+	if cfg.setupErr == nil {
+		switch {
+		case field == "":
+			cfg.setupErr = errors.New("pbimmutable.MakePinned: a field name must be provided")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakePinned: only a callback can be passed as additional argument")
+		}
+	}
+	cfg.fields = []string{field}
+	cfg.checkNew = true
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		// the stored value is only reported, the pending value is checked against value
+		if original == nil {
+			original = models.NewRecord(e.Record.Collection())
+		}
+
+		change := newChange(original, e.Record, field, RulePinned)
+		if !cfg.cmp.equalValues(e.Record, field, value, change.New) {
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' is pinned to '%v'.", field, value)
+			change.Details = map[string]any{"expected": value}
+		}
+		return ChangeSet{change}, nil
+	}
+
+	return newHook(cfg)
+}
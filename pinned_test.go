@@ -0,0 +1,110 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/USERNAME/pbimmutable/pbimmutabletest"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakePinned(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newRecord := func(value int) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("name", "pinned_test")
+		record.Set("value", value)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	tests := []struct {
+		name                string
+		stored              int
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"pinned value kept", 2, map[string]interface{}{"name": "changed"}, ""},
+		{"pinned value changed", 2, map[string]interface{}{"value": 3}, "Field 'value' is pinned to '2'."},
+		{"wrong stored value left as is", 5, map[string]interface{}{"name": "changed"}, "Field 'value' is pinned to '2'."},
+		{"wrong stored value fixed", 5, map[string]interface{}{"value": 2}, ""},
+		{"coerced by the field type", 2, map[string]interface{}{"value": "2"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := MakePinned("value", 2)(newUpdateEvent(app, newRecord(tc.stored), tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("create", func(t *testing.T) {
+		hookFunc := MakePinned("status", "active")
+
+		record := models.NewRecord(coll)
+		record.Set("status", "active")
+		if err := hookFunc(&core.RecordEvent{App: app, Record: record}); err != nil {
+			t.Errorf("Expected the pinned value to be accepted on create, got: %v", err)
+		}
+
+		record = models.NewRecord(coll)
+		err := hookFunc(&core.RecordEvent{App: app, Record: record})
+		if err == nil || !strings.Contains(err.Error(), "Field 'status' is pinned to 'active'.") {
+			t.Errorf("Expected a missing pinned value to be rejected on create, got: %v", err)
+		}
+	})
+
+	t.Run("chained with options", func(t *testing.T) {
+		var order []string
+		callback := func(e *core.RecordEvent) error {
+			order = append(order, "callback")
+			return nil
+		}
+		hookFunc := Chain(MakeImmutable("name"), MakePinned("value", 2, callback, WithMessages(map[string]string{"value": "Version is fixed."})))
+
+		event := pbimmutabletest.NewEvent(newUpdateEvent(app, newRecord(2), map[string]interface{}{"description": "changed"}), func(e *core.RecordEvent) error {
+			order = append(order, "next")
+			return nil
+		})
+		if err := event.Run(hookFunc); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if event.NextCalls() != 1 || strings.Join(order, ",") != "callback,next" {
+			t.Errorf("Expected the callback and a single e.Next() call, got %d calls and %v", event.NextCalls(), order)
+		}
+
+		event = pbimmutabletest.NewEvent(newUpdateEvent(app, newRecord(2), map[string]interface{}{"value": 3}), nil)
+		err := event.Run(hookFunc)
+		if err == nil || !strings.Contains(err.Error(), "Version is fixed.") {
+			t.Errorf("Expected the custom message, got: %v", err)
+		}
+		if event.NextCalled() {
+			t.Errorf("Expected the rejected update not to reach e.Next()")
+		}
+	})
+
+	t.Run("setup errors", func(t *testing.T) {
+		record := newRecord(2)
+		for field, expected := range map[string]string{
+			"":     "pbimmutable.MakePinned: a field name must be provided",
+			"nmae": `unknown fields "nmae"`,
+		} {
+			err := MakePinned(field, 2)(newUpdateEvent(app, record, nil))
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error containing %q, got: %v", expected, err)
+			}
+		}
+	})
+}
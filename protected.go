@@ -0,0 +1,45 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// Inspector exposes the configuration of a hook at runtime, e.g. to show the locked
+// fields in an admin UI or their freeze state (see FreezeStatus). Pass it to any hook
+// constructor with WithInspector:
+//
+//	var inspector pbimmutable.Inspector
+//	app.OnRecordUpdate("products").Add(pbimmutable.MakeImmutable("sku", "price_*", pbimmutable.WithInspector(&inspector)))
+//
+//	locked := inspector.ProtectedFields(productsCollection)
+//
+// An Inspector that was never passed to a hook reports no fields.
+type Inspector struct {
+	cfg *hookConfig
+}
+
+// WithInspector attaches inspector to the hook, so that the configuration the hook
+// was built with can be read through it. An inspector should be attached to one hook
+// only; attaching it again makes it report the last hook built with it.
+func WithInspector(inspector *Inspector) Option {
+	return func(cfg *hookConfig) {
+		cfg.inspector = inspector
+	}
+}
+
+// ProtectedFields returns the fields whose changes the hook rejects in records of the
+// collection, in evaluation order: all user-defined fields without field names, field
+// patterns expanded against the schema and the system fields overrides applied
+// (see WithMutableSystemFields and WithImmutableSystemFields).
+//
+// It lists what the hook checks for every update. Fields unlocked per request (e.g. by
+// WithAllowSuperusers) or per record (e.g. by a condition) are still listed. It returns
+// nil when the inspector isn't attached, the hook has a setup error or the collection is nil,
+// and for hooks with a custom check (e.g. MakeMonotonic or MakeTransitionGuard).
+func (i *Inspector) ProtectedFields(collection *models.Collection) []string {
+	if i == nil || i.cfg == nil || collection == nil || i.cfg.setupErr != nil {
+		return nil
+	}
+
+	return i.cfg.protectedFields(models.NewRecord(collection))
+}
@@ -0,0 +1,85 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestInspector_ProtectedFields(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	tests := []struct {
+		name     string
+		args     []interface{}
+		expected string
+	}{
+		{"listed fields", []interface{}{"name", "value"}, "name,value"},
+		{"all user-defined fields", []interface{}{}, "name,value,status,description"},
+		{"field pattern", []interface{}{"name", "desc*"}, "name,description"},
+		{"case-insensitive names", []interface{}{"Name", WithCaseInsensitiveFields()}, "name"},
+		{"updated is never protected by default", []interface{}{"name", "updated"}, "name"},
+		{"immutable system field", []interface{}{"name", WithImmutableSystemFields("updated")}, "name,updated"},
+		{"mutable system field", []interface{}{"name", "created", WithMutableSystemFields("created")}, "name"},
+		{"custom system fields", []interface{}{WithSystemFields(func(name string) bool { return name == "status" })}, "name,value,description"},
+		{"setup error", []interface{}{42}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var inspector Inspector
+			MakeImmutable(append([]interface{}{WithInspector(&inspector)}, tc.args...)...)
+
+			if got := strings.Join(inspector.ProtectedFields(coll), ","); got != tc.expected {
+				t.Errorf("Expected protected fields %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("custom checks", func(t *testing.T) {
+		hooks := map[string]func(o Option){
+			"MakeMonotonic":                 func(o Option) { MakeMonotonic("value", Increasing, o) },
+			"MakeNonDecreasing":             func(o Option) { MakeNonDecreasing("value", o) },
+			"MakeTransitionGuard":           func(o Option) { MakeTransitionGuard("status", map[string][]string{"active": {"done"}}, o) },
+			"MakeAppendOnly":                func(o Option) { MakeAppendOnly("description", o) },
+			"MakeImmutableFromFieldOptions": func(o Option) { MakeImmutableFromFieldOptions(o) },
+		}
+		for name, build := range hooks {
+			var inspector Inspector
+			build(WithInspector(&inspector))
+			if got := inspector.ProtectedFields(coll); got != nil {
+				t.Errorf("%s: expected no protected fields, got %v", name, got)
+			}
+		}
+	})
+
+	t.Run("detached inspector", func(t *testing.T) {
+		var inspector Inspector
+		if got := inspector.ProtectedFields(coll); got != nil {
+			t.Errorf("Expected no protected fields, got %v", got)
+		}
+	})
+
+	t.Run("hook still checks updates", func(t *testing.T) {
+		initialRecord := models.NewRecord(coll)
+		initialRecord.Set("name", "protected_fields_test")
+		if err := app.Dao().SaveRecord(initialRecord); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+
+		var inspector Inspector
+		hook := MakeImmutable("name", WithInspector(&inspector))
+		err := hook(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+		if err := hook(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "active"})); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+		if got := strings.Join(inspector.ProtectedFields(coll), ","); got != "name" {
+			t.Errorf("Expected protected fields %q, got %q", "name", got)
+		}
+	})
+}
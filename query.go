@@ -0,0 +1,44 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// WithQueryParamOverride allows changes to the listed frozen fields (or to all frozen fields
+// when none are listed) only when the request query parameter param equals expected,
+// e.g. WithQueryParamOverride("mode", "correction", "amount") unlocks "amount" for
+// requests to "...?mode=correction".
+//
+// Events without HTTP request info never match.
+func WithQueryParamOverride(param, expected string, fields ...string) Option {
+	return func(cfg *hookConfig) {
+		cfg.refiners = append(cfg.refiners, func(e *core.RecordEvent, changes ChangeSet) {
+			if !queryParamEquals(e, param, expected) {
+				return
+			}
+
+			for i, change := range changes {
+				if len(fields) == 0 || containsString(fields, change.Field) {
+					changes[i].Violated = false
+				}
+			}
+		})
+	}
+}
+
+// queryParamEquals reports whether the event request query parameter param has the expected value.
+func queryParamEquals(e *core.RecordEvent, param, expected string) bool {
+	info := requestInfo(e)
+	if info == nil {
+		return false
+	}
+
+	switch v := info.Query[param].(type) {
+	case string:
+		return v == expected
+	case []string:
+		return len(v) > 0 && v[0] == expected
+	default:
+		return false
+	}
+}
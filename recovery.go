@@ -0,0 +1,61 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// WithoutPanicRecovery lets panics of the callback and of e.Next() propagate.
+//
+// By default the hook recovers them and returns a PanicError instead, after logging
+// the panic value and the stack trace via e.App.Logger(), so that a misbehaving
+// callback or save hook can't take down the request goroutine. When hooks are combined
+// with Chain, a single hook without recovery lets a panic of e.Next() propagate.
+func WithoutPanicRecovery() Option {
+	return func(cfg *hookConfig) {
+		cfg.propagatePanics = true
+	}
+}
+
+// PanicError is returned for a recovered panic of the callback or of e.Next().
+type PanicError struct {
+	Source string // "callback" or "e.Next()"
+	Value  any    // The value passed to panic.
+	Stack  []byte // The stack trace of the panicking goroutine.
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s: %v", e.Source, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// callRecovered calls fn and converts its panic into a PanicError, logged via e.App.Logger().
+func callRecovered(e *core.RecordEvent, source string, fn func() error) (err error) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		panicErr := &PanicError{Source: source, Value: value, Stack: debug.Stack()}
+		e.App.Logger().Error(
+			"pbimmutable: recovered panic",
+			"source", source,
+			"collection", e.Record.Collection().Name,
+			"recordId", e.Record.Id,
+			"panic", fmt.Sprint(value),
+			"stack", string(panicErr.Stack),
+		)
+		err = panicErr
+	}()
+
+	return fn()
+}
@@ -0,0 +1,76 @@
+package pbimmutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "panic_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	updates := map[string]interface{}{"status": "changed"}
+	errBoom := errors.New("boom")
+
+	t.Run("callback panic", func(t *testing.T) {
+		hookFunc := MakeImmutable("name", func(e *core.RecordEvent) error {
+			panic("callback exploded")
+		})
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, updates))
+
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("Expected a PanicError, got: %v", err)
+		}
+		if panicErr.Source != "callback" || panicErr.Value != "callback exploded" || len(panicErr.Stack) == 0 {
+			t.Errorf("Unexpected PanicError: source=%q value=%v stack=%d bytes", panicErr.Source, panicErr.Value, len(panicErr.Stack))
+		}
+		if !strings.Contains(err.Error(), "record changes were not saved: panic in callback: callback exploded") {
+			t.Errorf("Unexpected error message: %v", err)
+		}
+	})
+
+	t.Run("e.Next() panic", func(t *testing.T) {
+		collection := "test_panics"
+		app.OnRecordUpdate(collection).Add(MakeImmutable("name"))
+		app.OnRecordUpdate(collection).Add(func(e *core.RecordEvent) error {
+			panic(errBoom)
+		})
+
+		err := app.OnRecordUpdate(collection).Trigger(newUpdateEvent(app, initialRecord, updates))
+
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) || panicErr.Source != "e.Next()" {
+			t.Fatalf("Expected a PanicError of e.Next(), got: %v", err)
+		}
+		if !errors.Is(err, errBoom) {
+			t.Errorf("Expected the error panic value to be unwrapped, got: %v", err)
+		}
+	})
+
+	t.Run("opt-out", func(t *testing.T) {
+		hookFunc := MakeImmutable("name", WithoutPanicRecovery(), func(e *core.RecordEvent) error {
+			panic("callback exploded")
+		})
+
+		defer func() {
+			if recovered := recover(); recovered != "callback exploded" {
+				t.Errorf("Expected the panic to propagate, got: %v", recovered)
+			}
+		}()
+
+		_ = hookFunc(newUpdateEvent(app, initialRecord, updates))
+		t.Error("Expected the hook to panic")
+	})
+}
@@ -0,0 +1,99 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RegisterImmutable builds the MakeImmutable hook with args and binds it to the
+// OnRecordUpdate hook of the collection, e.g.
+//
+//	if err := pbimmutable.RegisterImmutable(app, "invoices", "number", "total"); err != nil {
+//		return err
+//	}
+//
+// The collection is looked up by name or id and must exist, so it is usually
+// called from app.OnBeforeServe() once the migrations ran. Invalid arguments and
+// field names that don't exist in the collection are reported right away
+// instead of on the first update.
+//
+// The hook isn't bound to the create hook: a new record has no original state,
+// so the values a record is created with (including defaults) are never frozen.
+func RegisterImmutable(app core.App, collection string, args ...interface{}) error {
+	name, hook, err := prepareImmutable(app, "RegisterImmutable", collection, args)
+	if err != nil {
+		return err
+	}
+
+	app.OnRecordUpdate(name).Add(hook)
+
+	return nil
+}
+
+// RegisterImmutableMany is like RegisterImmutable for several collections at once,
+// with the MakeImmutable args of every collection keyed by its name or id, e.g.
+//
+//	err := pbimmutable.RegisterImmutableMany(app, map[string][]interface{}{
+//		"invoices": {"number", "total"},
+//		"orders":   {"number"},
+//	})
+//
+// All entries are validated before any hook is bound: if any collection is missing or
+// any args are invalid, nothing is registered and the returned error joins the errors
+// of every failed entry (in collection name order).
+func RegisterImmutableMany(app core.App, rules map[string][]interface{}) error {
+	collections := make([]string, 0, len(rules))
+	for collection := range rules {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+
+	names := make([]string, 0, len(collections))
+	hooks := make([]func(e *core.RecordEvent) error, 0, len(collections))
+	var errs []error
+	for _, collection := range collections {
+		name, hook, err := prepareImmutable(app, "RegisterImmutableMany", collection, rules[collection])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, name)
+		hooks = append(hooks, hook)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for i, name := range names {
+		app.OnRecordUpdate(name).Add(hooks[i])
+	}
+
+	return nil
+}
+
+// prepareImmutable resolves the collection and validates args against it, returning
+// the collection name and the hook to bind to its update hook.
+func prepareImmutable(app core.App, caller string, collection string, args []interface{}) (string, func(e *core.RecordEvent) error, error) {
+	if collection == "" {
+		return "", nil, fmt.Errorf("pbimmutable.%s: a collection name must be provided", caller)
+	}
+
+	found, err := app.Dao().FindCollectionByNameOrId(collection)
+	if err != nil || found == nil {
+		return "", nil, fmt.Errorf("pbimmutable.%s: collection %q doesn't exist", caller, collection)
+	}
+
+	cfg := parseArgs(caller, args)
+	if cfg.setupErr != nil {
+		return "", nil, fmt.Errorf("%w (collection %s)", cfg.setupErr, found.Name)
+	}
+	if err := cfg.validateFields(models.NewRecord(found)); err != nil {
+		return "", nil, fmt.Errorf("pbimmutable.%s: %w", caller, err)
+	}
+
+	return found.Name, newHook(cfg), nil
+}
@@ -0,0 +1,113 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestRegisterImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "register_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	t.Run("registration errors", func(t *testing.T) {
+		tests := []struct {
+			name                string
+			collection          string
+			args                []interface{}
+			expectErrorContains string
+		}{
+			{"empty collection name", "", []interface{}{"name"}, "a collection name must be provided"},
+			{"unknown collection", "missing_items", []interface{}{"name"}, `collection "missing_items" doesn't exist`},
+			{"unknown field", coll.Name, []interface{}{"nmae"}, `unknown fields "nmae" in collection test_items`},
+			{"invalid argument", coll.Name, []interface{}{"name", 123}, "invalid argument type int at position 1"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				err := RegisterImmutable(app, tc.collection, tc.args...)
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			})
+		}
+	})
+
+	t.Run("registered hook fires on update", func(t *testing.T) {
+		if err := RegisterImmutable(app, coll.Id, "name"); err != nil {
+			t.Fatalf("Failed to register hook: %v", err)
+		}
+
+		event := newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"})
+		err := app.OnRecordUpdate(coll.Name).Trigger(event)
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+
+		event = newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "changed"})
+		if err := app.OnRecordUpdate(coll.Name).Trigger(event); err != nil {
+			t.Errorf("Expected no error for a mutable field, got: %v", err)
+		}
+	})
+}
+
+func TestRegisterImmutableMany(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "register_many_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	t.Run("invalid entries register nothing", func(t *testing.T) {
+		err := RegisterImmutableMany(app, map[string][]interface{}{
+			coll.Name:       {"name"},
+			"missing_items": {"name"},
+			"zz_missing":    {"name"},
+		})
+		if err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+		for _, expected := range []string{
+			`pbimmutable.RegisterImmutableMany: collection "missing_items" doesn't exist`,
+			`collection "zz_missing" doesn't exist`,
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error containing %q, got: %v", expected, err)
+			}
+		}
+
+		event := newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"})
+		if err := app.OnRecordUpdate(coll.Name).Trigger(event); err != nil {
+			t.Errorf("Expected no hook to be registered, got: %v", err)
+		}
+	})
+
+	t.Run("invalid args", func(t *testing.T) {
+		err := RegisterImmutableMany(app, map[string][]interface{}{coll.Name: {"name", 123}})
+		if err == nil || !strings.Contains(err.Error(), "invalid argument type int at position 1 (collection test_items)") {
+			t.Errorf("Expected invalid argument error, got: %v", err)
+		}
+	})
+
+	t.Run("registered hooks fire on update", func(t *testing.T) {
+		if err := RegisterImmutableMany(app, map[string][]interface{}{coll.Id: {"name"}}); err != nil {
+			t.Fatalf("Failed to register hooks: %v", err)
+		}
+
+		event := newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"})
+		err := app.OnRecordUpdate(coll.Name).Trigger(event)
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package pbimmutable
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RuleReleaseOnly is the kind of the rule created by MakeReleaseOnly.
+const RuleReleaseOnly RuleKind = "release_only"
+
+// MakeReleaseOnly returns a hook that only allows the provided fields (or all user-defined
+// fields when none are given) to be cleared, e.g. a reservation that can be released but
+// never reassigned: an update from a non-empty to an empty value passes, while changing
+// the value to another non-empty value or filling an empty value is rejected. Once
+// released, a field stays empty.
+//
+// "Empty" follows the stored value of the field type, like in MakeWriteOnce: the submitted
+// value is converted to its stored form first, so "" and 0 are both empty for a number field.
+//
+// It accepts the same arguments as MakeImmutable.
+func MakeReleaseOnly(args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeReleaseOnly", args)
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		changes := cfg.cmp.evaluate(original, e.Record, cfg.fields)
+		for i, change := range changes {
+			changes[i].RuleKind = RuleReleaseOnly
+			if !change.Violated {
+				continue
+			}
+
+			newValue := change.New
+			if field := e.Record.Schema().GetFieldByName(change.Field); field != nil {
+				newValue = field.PrepareValue(newValue)
+			}
+
+			switch {
+			case isEmptyValue(change.Old):
+				changes[i].Message = fmt.Sprintf("Field '%s' was released and can't be set again.", change.Field)
+			case isEmptyValue(newValue):
+				changes[i].Violated = false // release
+			default:
+				changes[i].Message = fmt.Sprintf("Field '%s' can only be cleared, not changed.", change.Field)
+			}
+		}
+		return changes, nil
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,63 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeReleaseOnly(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	emptyRecord := models.NewRecord(coll)
+	emptyRecord.Set("name", "release_empty")
+	if err := app.Dao().SaveRecord(emptyRecord); err != nil {
+		t.Fatalf("Failed to save empty record: %v", err)
+	}
+
+	reservedRecord := models.NewRecord(coll)
+	reservedRecord.Set("name", "release_reserved")
+	reservedRecord.Set("status", "room-12")
+	reservedRecord.Set("value", 5)
+	if err := app.Dao().SaveRecord(reservedRecord); err != nil {
+		t.Fatalf("Failed to save reserved record: %v", err)
+	}
+
+	released := []interface{}{"status", "value"}
+
+	tests := []struct {
+		name                string
+		fields              []interface{}
+		original            *models.Record
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"release text", released, reservedRecord, map[string]interface{}{"status": ""}, ""},
+		{"release number", released, reservedRecord, map[string]interface{}{"value": 0}, ""},
+		{"release number with empty string", released, reservedRecord, map[string]interface{}{"value": ""}, ""},
+		{"unchanged value", released, reservedRecord, map[string]interface{}{"status": "room-12"}, ""},
+		{"empty stays empty", released, emptyRecord, map[string]interface{}{"description": "not checked"}, ""},
+		{"reassign text", released, reservedRecord, map[string]interface{}{"status": "room-14"}, "Field 'status' can only be cleared, not changed."},
+		{"reassign number", released, reservedRecord, map[string]interface{}{"value": 6}, "Field 'value' can only be cleared, not changed."},
+		{"set a released value", released, emptyRecord, map[string]interface{}{"status": "room-12"}, "Field 'status' was released and can't be set again."},
+		{"unknown field", []interface{}{"nmae"}, reservedRecord, map[string]interface{}{}, `unknown fields "nmae"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeReleaseOnly(tc.fields...)
+
+			err := hookFunc(newUpdateEvent(app, tc.original, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// requestInfo returns the HTTP request info of the event
+// or nil if the event was not triggered by an HTTP request (e.g. a programmatic save).
+func requestInfo(e *core.RecordEvent) *models.RequestInfo {
+	if e.HttpContext == nil {
+		return nil
+	}
+	return apis.RequestInfo(e.HttpContext)
+}
+
+// requestActorId returns the id of the authenticated record or admin of the request
+// or an empty string for guests and non-HTTP events.
+func requestActorId(info *models.RequestInfo) string {
+	switch {
+	case info == nil:
+		return ""
+	case info.AuthRecord != nil:
+		return info.AuthRecord.Id
+	case info.Admin != nil:
+		return info.Admin.Id
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,99 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Violation describes a rejected field change passed to a violation responder.
+type Violation struct {
+	Change
+
+	RecordId   string // The id of the updated record.
+	Collection string // The name of the record collection.
+}
+
+// WithViolationResponder replaces the built-in BadRequestError returned on violations.
+//
+// The responder receives every violation of the rejected update (in evaluation order)
+// and its returned error is returned unchanged by the hook, so PocketBase renders it as
+// the API response: an *apis.ApiError (e.g. apis.NewApiError(http.StatusConflict, ...))
+// controls the status and body, other errors go through the app error handler.
+// If the responder returns nil, the default error is returned instead.
+//
+// The responder is only called for rejected updates. It must not be used to allow
+// the change: the update is rejected in any case.
+func WithViolationResponder(responder func(violations []Violation) error) Option {
+	return func(cfg *hookConfig) {
+		cfg.responder = responder
+	}
+}
+
+// WithForbiddenStatus rejects violations with a 403 Forbidden error (apis.NewForbiddenError)
+// instead of the default 400 Bad Request, e.g. for clients that treat changes of frozen
+// fields as an authorization failure. The message and data of the response stay the same
+// and the error is still an *ImmutableFieldError.
+func WithForbiddenStatus() Option {
+	return func(cfg *hookConfig) {
+		cfg.forbiddenStatus = true
+	}
+}
+
+// WithOnViolation calls fn with the violated fields (in evaluation order) whenever the hook
+// rejects an update, right before the violation error is returned, e.g. to send an alert
+// or record a security event. Multiple calls are combined and run in order.
+//
+// fn can't allow the change or replace the error: when it fails, its error is logged via
+// e.App.Logger() and the hook still returns the violation error.
+func WithOnViolation(fn func(e *core.RecordEvent, fields []string) error) Option {
+	return func(cfg *hookConfig) {
+		if fn != nil {
+			cfg.onViolation = append(cfg.onViolation, fn)
+		}
+	}
+}
+
+// rejectError returns the error for an update rejected because of the provided violations.
+func (cfg *hookConfig) rejectError(e *core.RecordEvent, violations ChangeSet) error {
+	cfg.applyMessages(e, violations)
+	cfg.recordBlocked(e.Record.Collection().Name, violations)
+
+	if cfg.logViolations {
+		cfg.writeViolationLog(e, violations)
+	}
+
+	if len(cfg.onViolation) > 0 {
+		fields := make([]string, len(violations))
+		for i, change := range violations {
+			fields[i] = change.Field
+		}
+
+		for _, fn := range cfg.onViolation {
+			if err := fn(e, fields); err != nil {
+				e.App.Logger().Error(
+					"pbimmutable: violation callback failed",
+					"collection", e.Record.Collection().Name,
+					"recordId", e.Record.Id,
+					"fields", fields,
+					"error", err.Error(),
+				)
+			}
+		}
+	}
+
+	if cfg.responder != nil {
+		list := make([]Violation, len(violations))
+		for i, change := range violations {
+			list[i] = Violation{
+				Change:     change,
+				RecordId:   e.Record.Id,
+				Collection: e.Record.Collection().Name,
+			}
+		}
+
+		if err := cfg.responder(list); err != nil {
+			return err
+		}
+	}
+
+	return violationError(e, violations, cfg.forbiddenStatus)
+}
@@ -0,0 +1,142 @@
+package pbimmutable
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithViolationResponder(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "responder_test")
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	t.Run("custom error", func(t *testing.T) {
+		var received []Violation
+		hookFunc := MakeImmutable("name", "status", WithViolationResponder(func(violations []Violation) error {
+			received = violations
+			return apis.NewApiError(http.StatusConflict, "frozen", map[string]any{"code": "E_FROZEN"})
+		}))
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed", "status": "inactive"}))
+
+		apiErr, ok := err.(*apis.ApiError)
+		if !ok || apiErr.Code != http.StatusConflict {
+			t.Fatalf("Expected the responder error, got: %v", err)
+		}
+		if len(received) != 2 || received[0].Field != "name" || received[1].Field != "status" {
+			t.Fatalf("Expected both violations to be passed, got: %+v", received)
+		}
+		if received[0].RecordId != initialRecord.Id || received[0].Collection != coll.Name || received[0].Old != "responder_test" {
+			t.Errorf("Unexpected violation details: %+v", received[0])
+		}
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		hookFunc := MakeImmutable("name", WithViolationResponder(func(violations []Violation) error {
+			return errors.New("custom envelope")
+		}))
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+		if err == nil || err.Error() != "custom envelope" {
+			t.Errorf("Expected the responder error, got: %v", err)
+		}
+	})
+
+	t.Run("nil falls back to default error", func(t *testing.T) {
+		hookFunc := MakeImmutable("name", WithViolationResponder(func(violations []Violation) error {
+			return nil
+		}))
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected default error, got: %v", err)
+		}
+	})
+
+	t.Run("not called without violations", func(t *testing.T) {
+		called := false
+		hookFunc := MakeImmutable("name", WithViolationResponder(func(violations []Violation) error {
+			called = true
+			return errors.New("unexpected")
+		}))
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "inactive"})); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+		if called {
+			t.Errorf("Expected responder not to be called")
+		}
+	})
+}
+
+func TestWithOnViolation(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "on_violation_test")
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	var alerts [][]string
+	alert := func(e *core.RecordEvent, fields []string) error {
+		alerts = append(alerts, fields)
+		return nil
+	}
+
+	t.Run("called for rejected updates", func(t *testing.T) {
+		alerts = nil
+		hookFunc := MakeImmutable("name", "status", WithOnViolation(alert))
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed", "status": "inactive"}))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify 2 immutable fields") {
+			t.Fatalf("Expected the violation error, got: %v", err)
+		}
+		if len(alerts) != 1 || strings.Join(alerts[0], ",") != "name,status" {
+			t.Errorf("Expected one call with the violated fields, got: %v", alerts)
+		}
+	})
+
+	t.Run("not called for accepted updates", func(t *testing.T) {
+		alerts = nil
+		hookFunc := MakeImmutableWith(ImmutableConfig{Fields: []string{"name"}, OnViolation: alert})
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "inactive"})); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(alerts) != 0 {
+			t.Errorf("Expected no call, got: %v", alerts)
+		}
+	})
+
+	t.Run("callback error keeps the violation error", func(t *testing.T) {
+		alerts = nil
+		hookFunc := MakeImmutable("name", WithOnViolation(func(e *core.RecordEvent, fields []string) error {
+			return errors.New("alerting unavailable")
+		}), WithOnViolation(alert))
+
+		err := hookFunc(newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"}))
+
+		var immutableErr *ImmutableFieldError
+		if !errors.As(err, &immutableErr) {
+			t.Fatalf("Expected the violation error, got: %v", err)
+		}
+		if len(alerts) != 1 {
+			t.Errorf("Expected the next callback to run after the failed one, got: %v", alerts)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ErrRollbackRequested can be returned (or wrapped) by a callback that runs after the commit
+// (see WithRunAfterCommit) to undo the committed update, e.g. when the side effect the update
+// depends on failed:
+//
+//	func(e *core.RecordEvent) error {
+//		if err := notifyWarehouse(e.Record); err != nil {
+//			return fmt.Errorf("%w: %v", pbimmutable.ErrRollbackRequested, err)
+//		}
+//		return nil
+//	}
+//
+// The hook then saves the original record the checks compared against, restoring the
+// values from before the update, or deletes the record when it was created by the update
+// (an upsert with a client provided id). Without an original record, e.g. while enforcement
+// is turned off (see SetEnabled), the update can't be reverted and only the error is
+// returned. The compensation is best effort, not a transaction:
+//   - it is saved without hooks, so no hook (e.g. RegisterCommitSync) sees the restore
+//   - an update committed by another request between the commit and the restore is
+//     overwritten, and readers may see the updated record in the meantime
+//
+// The hook returns the callback error either way, joined with the error of a failed
+// compensation. Returned by a callback that runs before e.Next(), it is a plain error:
+// the update wasn't saved, so there is nothing to undo.
+var ErrRollbackRequested = errors.New("pbimmutable: rollback requested")
+
+// compensate undoes a committed update after a callback returned ErrRollbackRequested.
+func compensate(e *core.RecordEvent, checked checkResult, callbackErr error) error {
+	dao := e.App.Dao().WithoutHooks()
+
+	var err error
+	switch {
+	case checked.created:
+		err = dao.DeleteRecord(e.Record)
+	case checked.original != nil:
+		err = dao.SaveRecord(checked.original)
+	default:
+		return fmt.Errorf("user callback requested a rollback AFTER record commit, but the original record is unknown, so the update was kept: %w", callbackErr)
+	}
+	if err != nil {
+		return fmt.Errorf("user callback requested a rollback AFTER record commit, but the compensation failed: %w", errors.Join(callbackErr, err))
+	}
+
+	return fmt.Errorf("user callback requested a rollback AFTER record commit, the update was reverted: %w", callbackErr)
+}
@@ -0,0 +1,109 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestErrRollbackRequested(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newRecord := func(name string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("name", name)
+		record.Set("status", "active")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	storedStatus := func(id string) string {
+		stored, err := app.Dao().FindRecordById(coll.Id, id)
+		if err != nil {
+			t.Fatalf("Failed to load record: %v", err)
+		}
+		return stored.GetString("status")
+	}
+
+	tests := []struct {
+		name                string
+		callbackErr         error
+		expectErrorContains string
+		expectStatus        string
+	}{
+		{"rollback requested", fmt.Errorf("%w: warehouse offline", ErrRollbackRequested), "the update was reverted", "active"},
+		{"plain error keeps the commit", errors.New("warehouse offline"), "user callback failed AFTER record commit", "inactive"},
+		{"no error", nil, "", "inactive"},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			record := newRecord(fmt.Sprintf("rollback_test_%d", i))
+			collection := fmt.Sprintf("%s_%d", coll.Name, i)
+
+			// the second handler plays e.Next() and commits the update
+			app.OnRecordUpdate(collection).Add(MakeImmutable("name", func(e *core.RecordEvent) error {
+				return tc.callbackErr
+			}, WithRunAfterCommit()))
+			app.OnRecordUpdate(collection).Add(func(e *core.RecordEvent) error {
+				return app.Dao().WithoutHooks().SaveRecord(e.Record)
+			})
+
+			err := app.OnRecordUpdate(collection).Trigger(newUpdateEvent(app, record, map[string]interface{}{"status": "inactive"}))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if tc.callbackErr != nil && !errors.Is(err, tc.callbackErr) {
+				t.Errorf("Expected the callback error to be wrapped, got: %v", err)
+			}
+			if got := storedStatus(record.Id); got != tc.expectStatus {
+				t.Errorf("Expected stored status %q, got %q", tc.expectStatus, got)
+			}
+		})
+	}
+}
+
+func TestErrRollbackRequested_Disabled(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	record := models.NewRecord(coll)
+	record.Set("name", "rollback_disabled_test")
+	record.Set("status", "active")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	SetEnabled(false)
+	defer SetEnabled(true)
+
+	collection := coll.Name + "_disabled"
+	app.OnRecordUpdate(collection).Add(MakeImmutable("name", func(e *core.RecordEvent) error {
+		return fmt.Errorf("%w: warehouse offline", ErrRollbackRequested)
+	}, WithRunAfterCommit()))
+	app.OnRecordUpdate(collection).Add(func(e *core.RecordEvent) error {
+		return app.Dao().WithoutHooks().SaveRecord(e.Record)
+	})
+
+	err := app.OnRecordUpdate(collection).Trigger(newUpdateEvent(app, record, map[string]interface{}{"status": "inactive"}))
+	if err == nil || !errors.Is(err, ErrRollbackRequested) {
+		t.Errorf("Expected the rollback error, got: %v", err)
+	}
+
+	// the record existed before the update, so it must never be deleted
+	if _, err := app.Dao().FindRecordById(coll.Id, record.Id); err != nil {
+		t.Errorf("Expected the record to still exist, got: %v", err)
+	}
+}
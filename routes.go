@@ -0,0 +1,57 @@
+package pbimmutable
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// WithSkipPaths lets requests whose URL path is one of the prefixes (or below it) change
+// the frozen fields, e.g. a privileged internal route that serves the same collection
+// as the public API:
+//
+//	MakeImmutable("amount", WithSkipPaths("/api/internal/fix"))
+//
+// The prefixes match whole path segments: "/api/internal" matches "/api/internal" and
+// "/api/internal/fix", but not "/api/internals". Events without an HTTP request
+// (e.g. programmatic Dao saves) have no path and are always checked. The path only
+// selects the route; protect the route itself with its own auth middleware.
+func WithSkipPaths(prefixes ...string) Option {
+	return func(cfg *hookConfig) {
+		if len(prefixes) == 0 {
+			return
+		}
+
+		skipped := append([]string(nil), prefixes...)
+		cfg.refiners = append(cfg.refiners, func(e *core.RecordEvent, changes ChangeSet) {
+			if !hasSkippedPath(e, skipped) {
+				return
+			}
+
+			for i := range changes {
+				changes[i].Violated = false
+			}
+		})
+	}
+}
+
+// hasSkippedPath reports whether the URL path of the event request is one of the
+// prefixes or below one of them. It returns false for non-HTTP events.
+func hasSkippedPath(e *core.RecordEvent, prefixes []string) bool {
+	if e.HttpContext == nil || e.HttpContext.Request() == nil {
+		return false
+	}
+
+	path := e.HttpContext.Request().URL.Path
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix == "" {
+			continue // an empty prefix would match every route
+		}
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
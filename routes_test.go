@@ -0,0 +1,66 @@
+package pbimmutable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithSkipPaths(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "skip_paths_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	updates := map[string]interface{}{"name": "fixed"}
+
+	tests := []struct {
+		name                string
+		prefixes            []string
+		path                string
+		expectErrorContains string
+	}{
+		{"exact prefix", []string{"/api/internal/fix"}, "/api/internal/fix", ""},
+		{"below the prefix", []string{"/api/internal/"}, "/api/internal/fix/" + initialRecord.Id, ""},
+		{"one of several prefixes", []string{"/api/other", "/api/internal"}, "/api/internal/fix", ""},
+		{"public route", []string{"/api/internal/fix"}, "/api/collections/test_items/records/" + initialRecord.Id, "Attempt to modify immutable field 'name'"},
+		{"partial segment", []string{"/api/internal"}, "/api/internals/fix", "Attempt to modify immutable field 'name'"},
+		{"empty prefix matches nothing", []string{""}, "/api/internal/fix", "Attempt to modify immutable field 'name'"},
+		{"no prefixes", nil, "/api/internal/fix", "Attempt to modify immutable field 'name'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableWith(ImmutableConfig{Fields: []string{"name"}, SkipPaths: tc.prefixes})
+
+			event := newUpdateEvent(app, initialRecord, updates)
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			event.HttpContext = echo.New().NewContext(req, httptest.NewRecorder())
+
+			err := hookFunc(event)
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("non-HTTP event is checked", func(t *testing.T) {
+		err := MakeImmutable("name", WithSkipPaths("/api/internal"))(newUpdateEvent(app, initialRecord, updates))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+}
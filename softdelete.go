@@ -0,0 +1,107 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// DefaultSoftDeleteField is the field checked by MakeSoftDeleteImmutable
+// when SoftDeleteRule.Field is not set.
+const DefaultSoftDeleteField = "deletedAt"
+
+// SoftDeleteRule configures how soft-deleted records are detected and frozen.
+type SoftDeleteRule struct {
+	// Field is the field that marks a record as soft-deleted when it is non-empty
+	// (defaults to DefaultSoftDeleteField).
+	//
+	// The field itself always stays mutable so that deleted records can be restored.
+	Field string
+
+	// MutableFields lists additional fields that stay editable after soft deletion
+	// (e.g. a "deletedBy" field cleared on restore).
+	MutableFields []string
+}
+
+// MakeSoftDeleteImmutable returns a hook that freezes all user-defined fields of
+// soft-deleted records, except the soft delete field itself and rule.MutableFields.
+// Records that are not soft-deleted in their original state remain editable.
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeSoftDeleteImmutable(rule SoftDeleteRule, args ...interface{}) func(e *core.RecordEvent) error {
+	if rule.Field == "" {
+		rule.Field = DefaultSoftDeleteField
+	}
+
+	cfg := parseArgs("MakeSoftDeleteImmutable", args)
+	if cfg.setupErr == nil && len(cfg.fields) > 0 {
+		cfg.setupErr = errors.New("pbimmutable.MakeSoftDeleteImmutable: use SoftDeleteRule.MutableFields instead of field name arguments")
+	}
+
+	cfg.freezeOn(func(original *models.Record) bool {
+		return !isEmptyValue(original.Get(rule.Field))
+	}, func(record *models.Record) FreezeInfo {
+		return FreezeInfo{Reason: FreezeReasonSoftDeleted, Since: dateTimeOf(record, rule.Field)}
+	})
+
+	cfg.resolveFields = func(pending *models.Record) []string {
+		mutable := make(map[string]struct{}, len(rule.MutableFields)+1)
+		mutable[rule.Field] = struct{}{}
+		for _, name := range rule.MutableFields {
+			mutable[name] = struct{}{}
+		}
+
+		var fields []string
+		for _, name := range userFields(pending) {
+			if _, ok := mutable[name]; !ok {
+				fields = append(fields, name)
+			}
+		}
+		return fields
+	}
+
+	return newHook(cfg)
+}
+
+// MakeImmutableWhenFlagged returns a hook that freezes the provided fields (or all
+// user-defined fields when none are given) once the boolean flagField of the original
+// record is true, e.g. for archived records:
+//
+//	MakeImmutableWhenFlagged("archived")
+//
+// While the flag is false, updates pass straight through to e.Next(). Without field names
+// the flag itself is frozen too, so a flagged record stays flagged for good; list the
+// fields explicitly (without the flag) to allow clearing it again.
+//
+// flagField must be a bool field of the collection schema, otherwise the first update
+// fails with a setup error.
+func MakeImmutableWhenFlagged(flagField string, fields ...string) func(e *core.RecordEvent) error {
+	args := make([]interface{}, len(fields))
+	for i, field := range fields {
+		args[i] = field
+	}
+
+	cfg := parseArgs("MakeImmutableWhenFlagged", args)
+	if cfg.setupErr == nil && flagField == "" {
+		cfg.setupErr = errors.New("pbimmutable.MakeImmutableWhenFlagged: a flag field must be provided")
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		if schemaField := original.Schema().GetFieldByName(flagField); schemaField == nil || schemaField.Type != schema.FieldTypeBool {
+			return nil, apis.NewBadRequestError(fmt.Sprintf("MakeImmutableWhenFlagged setup error: '%s' is not a bool field of collection %s.", flagField, original.Collection().Name), nil)
+		}
+
+		if !original.GetBool(flagField) {
+			return nil, nil
+		}
+
+		return cfg.cmp.evaluate(original, e.Record, cfg.fields), nil
+	}
+
+	return newHook(cfg)
+}
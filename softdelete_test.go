@@ -0,0 +1,173 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestMakeSoftDeleteImmutable(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newRecord := func(deletedMarker string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("name", "soft_delete_test")
+		record.Set("description", "original description")
+		record.Set("status", deletedMarker)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	// "status" plays the role of the deletedAt marker in the test collection.
+	rule := SoftDeleteRule{Field: "status", MutableFields: []string{"description"}}
+
+	tests := []struct {
+		name                string
+		deletedMarker       string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{
+			name:          "active record stays editable",
+			deletedMarker: "",
+			updates:       map[string]interface{}{"name": "changed", "value": 5},
+		},
+		{
+			name:                "deleted record is frozen",
+			deletedMarker:       "2024-01-01 00:00:00.000Z",
+			updates:             map[string]interface{}{"name": "changed"},
+			expectErrorContains: "Attempt to modify immutable field 'name'",
+		},
+		{
+			name:          "deleted record can be restored",
+			deletedMarker: "2024-01-01 00:00:00.000Z",
+			updates:       map[string]interface{}{"status": ""},
+		},
+		{
+			name:          "configured mutable field stays editable",
+			deletedMarker: "2024-01-01 00:00:00.000Z",
+			updates:       map[string]interface{}{"description": "restored by admin"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			record := newRecord(tc.deletedMarker)
+			hookFunc := MakeSoftDeleteImmutable(rule)
+
+			err := hookFunc(newUpdateEvent(app, record, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tc.expectErrorContains)
+				} else if !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("field names are not accepted", func(t *testing.T) {
+		hookFunc := MakeSoftDeleteImmutable(rule, "name")
+
+		err := hookFunc(newUpdateEvent(app, newRecord(""), nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeSoftDeleteImmutable setup error") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
+
+func TestMakeImmutableWhenFlagged(t *testing.T) {
+	app, baseColl, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_projects",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "budget", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "archived", Type: schema.FieldTypeBool},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	newRecord := func(archived bool) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("title", "flag_test")
+		record.Set("budget", 100)
+		record.Set("archived", archived)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	active := newRecord(false)
+	archived := newRecord(true)
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		fields              []string
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"active record is editable", active, nil, map[string]interface{}{"title": "changed", "budget": 200}, ""},
+		{"archiving with changes", active, nil, map[string]interface{}{"title": "changed", "archived": true}, ""},
+		{"archived record is frozen", archived, nil, map[string]interface{}{"title": "changed"}, "Attempt to modify immutable field 'title'"},
+		{"unarchiving is frozen without fields", archived, nil, map[string]interface{}{"archived": false}, "Attempt to modify immutable field 'archived'"},
+		{"unarchiving with listed fields", archived, []string{"title", "budget"}, map[string]interface{}{"archived": false}, ""},
+		{"listed field of archived record", archived, []string{"title", "budget"}, map[string]interface{}{"budget": 200}, "Attempt to modify immutable field 'budget'"},
+		{"unknown field", archived, []string{"titel"}, map[string]interface{}{}, `unknown fields "titel"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableWhenFlagged("archived", tc.fields...)
+
+			err := hookFunc(newUpdateEvent(app, tc.original, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("setup errors", func(t *testing.T) {
+		baseRecord := models.NewRecord(baseColl)
+		baseRecord.Set("name", "flag_setup")
+		if err := app.Dao().SaveRecord(baseRecord); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+
+		setupTests := []struct {
+			flagField           string
+			expectErrorContains string
+		}{
+			{"", "a flag field must be provided"},
+			{"archived", "'archived' is not a bool field of collection test_items"},
+			{"status", "'status' is not a bool field of collection test_items"},
+		}
+
+		for _, tc := range setupTests {
+			err := MakeImmutableWhenFlagged(tc.flagField)(newUpdateEvent(app, baseRecord, map[string]interface{}{"name": "changed"}))
+			if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package pbimmutable
+
+import (
+	"errors"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// SourceRule selects the records to freeze by the value of a source field
+// (e.g. the client that created them) on the original record.
+type SourceRule struct {
+	// Field is the name of the field holding the record source, e.g. "source".
+	Field string
+
+	// Sources lists the source values whose records are frozen.
+	Sources []string
+
+	// FreezeMissing decides whether records with a missing or empty source are frozen.
+	FreezeMissing bool
+}
+
+// MakeImmutableForSource returns a hook that freezes the provided fields only for records
+// whose original source value is listed in rule.Sources. Records from any other source
+// remain editable.
+//
+// It accepts the same arguments as MakeImmutable, so calling it without field names
+// freezes all user-defined fields of the matching records.
+//
+// Usage example:
+// MakeImmutableForSource(SourceRule{Field: "source", Sources: []string{"mobile"}}, "amount")
+func MakeImmutableForSource(rule SourceRule, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeImmutableForSource", args)
+	if cfg.setupErr == nil && rule.Field == "" {
+		cfg.setupErr = errors.New("pbimmutable.MakeImmutableForSource: a source field name must be provided")
+	}
+	cfg.freezeOn(rule.matches, nil)
+	return newHook(cfg)
+}
+
+// matches reports whether the original record was created by one of the rule sources.
+func (r SourceRule) matches(original *models.Record) bool {
+	source := original.GetString(r.Field)
+	if source == "" {
+		return r.FreezeMissing
+	}
+
+	for _, s := range r.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeImmutableForSource(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newRecord := func(source string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("name", "source_test")
+		record.Set("value", 10)
+		record.Set("status", source)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	mobileRule := SourceRule{Field: "status", Sources: []string{"mobile", "tablet"}}
+
+	tests := []struct {
+		name        string
+		rule        SourceRule
+		source      string
+		expectError bool
+	}{
+		{
+			name:        "matching source is frozen",
+			rule:        mobileRule,
+			source:      "mobile",
+			expectError: true,
+		},
+		{
+			name:        "another matching source is frozen",
+			rule:        mobileRule,
+			source:      "tablet",
+			expectError: true,
+		},
+		{
+			name:        "non-matching source stays editable",
+			rule:        mobileRule,
+			source:      "web",
+			expectError: false,
+		},
+		{
+			name:        "empty source uses default (editable)",
+			rule:        mobileRule,
+			source:      "",
+			expectError: false,
+		},
+		{
+			name:        "empty source uses default (frozen)",
+			rule:        SourceRule{Field: "status", Sources: []string{"mobile"}, FreezeMissing: true},
+			source:      "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			record := newRecord(tc.source)
+			hookFunc := MakeImmutableForSource(tc.rule, "value")
+
+			err := hookFunc(newUpdateEvent(app, record, map[string]interface{}{"value": 20}))
+
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				} else if !strings.Contains(err.Error(), "Attempt to modify immutable field 'value'") {
+					t.Errorf("Expected immutable field error, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("missing source field name", func(t *testing.T) {
+		record := newRecord("mobile")
+		hookFunc := MakeImmutableForSource(SourceRule{Sources: []string{"mobile"}}, "value")
+
+		err := hookFunc(newUpdateEvent(app, record, nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeImmutableForSource setup error") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package pbimmutable
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// WithSubmittedFieldsOnly only reports violations of the fields the client submitted in
+// the request body of an HTTP event, so that a partial (PATCH style) update omitting a frozen
+// field can never be rejected because of it. Keys with a "+" or "-" modifier and uploaded
+// files count as submitting their field, and a JSON path counts as submitted with its field.
+//
+// Fields changed by other hooks during the request aren't reported either, because they
+// weren't submitted. Non-HTTP events are checked as usual.
+func WithSubmittedFieldsOnly() Option {
+	return func(cfg *hookConfig) {
+		cfg.refiners = append(cfg.refiners, func(e *core.RecordEvent, changes ChangeSet) {
+			submitted, ok := submittedFields(e)
+			if !ok {
+				return
+			}
+
+			for i, change := range changes {
+				field, _, _ := strings.Cut(change.Field, ".")
+				if change.Violated && !submitted[field] {
+					changes[i].Violated = false
+				}
+			}
+		})
+	}
+}
+
+// submittedFields returns the names of the fields submitted in the request body of the event.
+// It returns false for non-HTTP events.
+func submittedFields(e *core.RecordEvent) (map[string]bool, bool) {
+	info := requestInfo(e)
+	if info == nil {
+		return nil, false
+	}
+
+	submitted := make(map[string]bool, len(info.Data))
+	for key := range info.Data {
+		submitted[trimModifiers(key)] = true
+	}
+
+	// uploaded files are not part of the request info data
+	if req := e.HttpContext.Request(); req != nil && req.MultipartForm != nil {
+		for key := range req.MultipartForm.File {
+			submitted[trimModifiers(key)] = true
+		}
+	}
+
+	return submitted, true
+}
@@ -0,0 +1,68 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithSubmittedFieldsOnly(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "submitted_test")
+	initialRecord.Set("value", 100)
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	// the pending record of a partial update that carries zero values for the omitted fields
+	omitted := map[string]interface{}{"name": "", "value": 0, "status": "inactive"}
+
+	tests := []struct {
+		name                string
+		event               func() *core.RecordEvent
+		option              bool
+		expectErrorContains string
+	}{
+		{"omitted frozen fields", func() *core.RecordEvent {
+			return newRequestUpdateEvent(app, initialRecord, omitted, &models.RequestInfo{Data: map[string]any{"status": "inactive"}})
+		}, true, ""},
+		{"submitted frozen field", func() *core.RecordEvent {
+			return newRequestUpdateEvent(app, initialRecord, omitted, &models.RequestInfo{Data: map[string]any{"status": "inactive", "name": ""}})
+		}, true, "Attempt to modify immutable field 'name'."},
+		{"submitted with modifier", func() *core.RecordEvent {
+			return newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"value": 101}, &models.RequestInfo{Data: map[string]any{"value+": 1}})
+		}, true, "Attempt to modify immutable field 'value'."},
+		{"non-HTTP event is fully checked", func() *core.RecordEvent {
+			return newUpdateEvent(app, initialRecord, omitted)
+		}, true, "Attempt to modify 2 immutable fields: name, value."},
+		{"off by default", func() *core.RecordEvent {
+			return newRequestUpdateEvent(app, initialRecord, omitted, &models.RequestInfo{Data: map[string]any{"status": "inactive"}})
+		}, false, "Attempt to modify 2 immutable fields: name, value."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := []interface{}{"name", "value"}
+			if tc.option {
+				args = append(args, WithSubmittedFieldsOnly())
+			}
+			hookFunc := MakeImmutable(args...)
+
+			err := hookFunc(tc.event())
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
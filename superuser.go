@@ -0,0 +1,59 @@
+package pbimmutable
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// WithAllowSuperusers lets requests authenticated as a superuser (a PocketBase admin)
+// change the frozen fields, e.g. to correct data through the admin UI.
+//
+// For those requests no violation is reported, so the callback and e.Next() run as
+// for any accepted update. Guests, auth records and non-HTTP events are unaffected.
+func WithAllowSuperusers() Option {
+	return func(cfg *hookConfig) {
+		cfg.refiners = append(cfg.refiners, func(e *core.RecordEvent, changes ChangeSet) {
+			if !isSuperuser(e) {
+				return
+			}
+
+			for i := range changes {
+				changes[i].Violated = false
+			}
+		})
+	}
+}
+
+// WithAllowedActors lets requests authenticated as one of the listed auth records
+// change the frozen fields, e.g. the record of a migration service account. It is more
+// granular than WithAllowSuperusers: only the auth record of the request is matched,
+// so superusers, guests and non-HTTP events are unaffected. An empty list allows nobody.
+func WithAllowedActors(authRecordIds ...string) Option {
+	return func(cfg *hookConfig) {
+		if len(authRecordIds) == 0 {
+			return
+		}
+
+		allowed := append([]string(nil), authRecordIds...)
+		cfg.refiners = append(cfg.refiners, func(e *core.RecordEvent, changes ChangeSet) {
+			if !isAllowedActor(e, allowed) {
+				return
+			}
+
+			for i := range changes {
+				changes[i].Violated = false
+			}
+		})
+	}
+}
+
+// isSuperuser reports whether the event request is authenticated as an admin.
+func isSuperuser(e *core.RecordEvent) bool {
+	info := requestInfo(e)
+	return info != nil && info.Admin != nil
+}
+
+// isAllowedActor reports whether the event request is authenticated as one of the auth records.
+func isAllowedActor(e *core.RecordEvent, authRecordIds []string) bool {
+	info := requestInfo(e)
+	return info != nil && info.AuthRecord != nil && containsString(authRecordIds, info.AuthRecord.Id)
+}
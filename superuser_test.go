@@ -0,0 +1,138 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWithAllowSuperusers(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "superuser_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	admin := &models.Admin{}
+	admin.Id = "admin_id"
+
+	user := models.NewRecord(coll)
+	user.Id = "user_id"
+
+	updates := map[string]interface{}{"name": "corrected"}
+
+	tests := []struct {
+		name                string
+		info                *models.RequestInfo
+		expectErrorContains string
+	}{
+		{"superuser bypasses the check", &models.RequestInfo{Admin: admin}, ""},
+		{"auth record is rejected", &models.RequestInfo{AuthRecord: user}, "Attempt to modify immutable field 'name'"},
+		{"guest is rejected", &models.RequestInfo{}, "Attempt to modify immutable field 'name'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			hookFunc := MakeImmutable("name", WithAllowSuperusers(), func(e *core.RecordEvent) error {
+				called = true
+				return nil
+			})
+
+			err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, tc.info))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if !called {
+				t.Errorf("Expected the callback to run for the bypassed update")
+			}
+		})
+	}
+
+	t.Run("programmatic update is rejected", func(t *testing.T) {
+		hookFunc := MakeImmutable("name", WithAllowSuperusers())
+
+		if err := hookFunc(newUpdateEvent(app, initialRecord, updates)); err == nil {
+			t.Errorf("Expected immutable field error, got nil")
+		}
+	})
+
+	t.Run("config flag", func(t *testing.T) {
+		hookFunc := MakeImmutableWith(ImmutableConfig{Fields: []string{"name"}, AllowSuperusers: true})
+
+		if err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, &models.RequestInfo{Admin: admin})); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+}
+
+func TestWithAllowedActors(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "allowed_actors_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	admin := &models.Admin{}
+	admin.Id = "migration_id"
+
+	service := models.NewRecord(coll)
+	service.Id = "migration_id"
+
+	user := models.NewRecord(coll)
+	user.Id = "user_id"
+
+	updates := map[string]interface{}{"name": "migrated"}
+
+	tests := []struct {
+		name                string
+		actors              []string
+		info                *models.RequestInfo
+		expectErrorContains string
+	}{
+		{"listed auth record bypasses the check", []string{"other_id", "migration_id"}, &models.RequestInfo{AuthRecord: service}, ""},
+		{"unlisted auth record is rejected", []string{"migration_id"}, &models.RequestInfo{AuthRecord: user}, "Attempt to modify immutable field 'name'"},
+		{"superuser with a listed id is rejected", []string{"migration_id"}, &models.RequestInfo{Admin: admin}, "Attempt to modify immutable field 'name'"},
+		{"guest is rejected", []string{"migration_id"}, &models.RequestInfo{}, "Attempt to modify immutable field 'name'"},
+		{"empty list allows nobody", nil, &models.RequestInfo{AuthRecord: service}, "Attempt to modify immutable field 'name'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutableWith(ImmutableConfig{Fields: []string{"name"}, AllowedActors: tc.actors})
+
+			err := hookFunc(newRequestUpdateEvent(app, initialRecord, updates, tc.info))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("non-HTTP event is rejected", func(t *testing.T) {
+		err := MakeImmutable("name", WithAllowedActors("migration_id"))(newUpdateEvent(app, initialRecord, updates))
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+}
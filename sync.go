@@ -0,0 +1,123 @@
+package pbimmutable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// CommitSyncConfig configures RegisterCommitSync.
+type CommitSyncConfig struct {
+	// Attempts is the maximum number of times the sync function is called
+	// for a single committed update (defaults to 3).
+	Attempts int
+
+	// Backoff is the delay between two attempts (defaults to 200ms).
+	Backoff time.Duration
+
+	// Timeout bounds all attempts for a single committed update, including the
+	// backoff delays (defaults to 30s). The context passed to the sync function
+	// is cancelled once it is exceeded.
+	Timeout time.Duration
+
+	// Context is the parent of the contexts passed to the sync function, e.g. a context
+	// cancelled when the app shuts down (defaults to context.Background()).
+	Context context.Context
+}
+
+// RegisterCommitSync registers sync to be called with the committed record after every
+// successful update of the collection, e.g. to push the change to an external system.
+//
+// Unlike the MakeImmutable callback, sync is bound to PocketBase's OnModelAfterUpdate hook,
+// so it only fires once the update was actually persisted (for updates inside a transaction,
+// after the transaction commits). Updates rejected by an immutability hook never reach it.
+//
+// sync runs in its own goroutine with a copy of the record, so neither the request nor the
+// caller of the save waits for it. Failed calls are retried up to cfg.Attempts times within
+// cfg.Timeout and the final failure is logged via app.Logger(). Delivery is best effort:
+// an update whose attempts all failed is not synced again, and the syncs still pending when
+// the process exits are lost, so reconcile the external system periodically where every
+// update must arrive. An attempt may also fail after the external system already applied
+// the change, so sync must be idempotent, e.g. by keying the external write on the record
+// id and its "updated" timestamp. sync should return once its context is done.
+func RegisterCommitSync(app core.App, collection string, sync func(ctx context.Context, record *models.Record) error, cfg ...CommitSyncConfig) error {
+	if collection == "" {
+		return errors.New("pbimmutable.RegisterCommitSync: a collection name must be provided")
+	}
+	if sync == nil {
+		return errors.New("pbimmutable.RegisterCommitSync: a sync function must be provided")
+	}
+
+	config := CommitSyncConfig{Attempts: 3, Backoff: 200 * time.Millisecond, Timeout: 30 * time.Second, Context: context.Background()}
+	if len(cfg) > 0 {
+		if cfg[0].Attempts > 0 {
+			config.Attempts = cfg[0].Attempts
+		}
+		if cfg[0].Backoff > 0 {
+			config.Backoff = cfg[0].Backoff
+		}
+		if cfg[0].Timeout > 0 {
+			config.Timeout = cfg[0].Timeout
+		}
+		if cfg[0].Context != nil {
+			config.Context = cfg[0].Context
+		}
+	}
+
+	app.OnModelAfterUpdate(collection).Add(func(e *core.ModelEvent) error {
+		record, ok := e.Model.(*models.Record)
+		if !ok {
+			return nil
+		}
+
+		// the caller may keep using its record, so the sync gets its own copy
+		go runCommitSync(app, config, sync, record.CleanCopy())
+
+		return nil
+	})
+
+	return nil
+}
+
+// runCommitSync calls sync for the committed record with the retries of config
+// and logs the final failure.
+func runCommitSync(app core.App, config CommitSyncConfig, sync func(ctx context.Context, record *models.Record) error, record *models.Record) {
+	ctx, cancel := context.WithTimeout(config.Context, config.Timeout)
+	defer cancel()
+
+	var err error
+	attempts := 0
+	for attempts < config.Attempts {
+		attempts++
+		if err = sync(ctx, record); err == nil {
+			return
+		}
+		if attempts == config.Attempts {
+			break
+		}
+
+		timer := time.NewTimer(config.Backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	// the record is already committed, so the failure can only be logged
+	app.Logger().Error(
+		"pbimmutable: commit sync failed",
+		"collection", record.Collection().Name,
+		"recordId", record.Id,
+		"attempts", attempts,
+		"error", err.Error(),
+	)
+}
@@ -0,0 +1,132 @@
+package pbimmutable
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestRegisterCommitSync(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "sync_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	// the sync runs in the background, so every call is reported on calls
+	calls := make(chan string, 10)
+	var attempt int32
+	err := RegisterCommitSync(app, coll.Name, func(ctx context.Context, record *models.Record) error {
+		if atomic.AddInt32(&attempt, 1) == 1 {
+			calls <- "failed"
+			return errors.New("temporary outage")
+		}
+		calls <- record.GetString("status")
+		return nil
+	}, CommitSyncConfig{Attempts: 2, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to register commit sync: %v", err)
+	}
+
+	// next returns the next sync call, or "timeout" if there is none
+	next := func() string {
+		select {
+		case call := <-calls:
+			return call
+		case <-time.After(time.Second):
+			return "timeout"
+		}
+	}
+
+	t.Run("rejected update is not synced", func(t *testing.T) {
+		hookFunc := MakeImmutable("name")
+		event := newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"})
+		if err := hookFunc(event); err == nil {
+			t.Fatalf("Expected immutable field error, got nil")
+		}
+		select {
+		case call := <-calls:
+			t.Errorf("Expected no sync call for a rejected update, got %q", call)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("committed update is synced with retry", func(t *testing.T) {
+		record := initialRecord.CleanCopy()
+		record.Set("status", "committed")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+
+		if first, second := next(), next(); first != "failed" || second != "committed" {
+			t.Errorf("Expected a failed attempt and the synced committed record, got %q and %q", first, second)
+		}
+	})
+
+	t.Run("save doesn't wait for the sync", func(t *testing.T) {
+		blockedColl := &models.Collection{
+			Name:   "test_sync_blocked",
+			Type:   models.CollectionTypeBase,
+			Schema: schema.NewSchema(&schema.SchemaField{Name: "status", Type: schema.FieldTypeText}),
+		}
+		if err := app.Dao().SaveCollection(blockedColl); err != nil {
+			t.Fatalf("Failed to save collection: %v", err)
+		}
+
+		release := make(chan struct{})
+		done := make(chan error, 1)
+		err := RegisterCommitSync(app, blockedColl.Name, func(ctx context.Context, record *models.Record) error {
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				done <- ctx.Err()
+				return ctx.Err()
+			}
+		}, CommitSyncConfig{Attempts: 1, Timeout: 100 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("Failed to register commit sync: %v", err)
+		}
+		defer close(release)
+
+		record := models.NewRecord(blockedColl)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+
+		start := time.Now()
+		record.Set("status", "updated")
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save record: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("Expected the save not to wait for the sync, took %v", elapsed)
+		}
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected the sync context to time out, got: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("Expected the sync context to be cancelled after the timeout")
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		if err := RegisterCommitSync(app, "", func(context.Context, *models.Record) error { return nil }); err == nil {
+			t.Errorf("Expected error for missing collection")
+		}
+		if err := RegisterCommitSync(app, coll.Name, nil); err == nil {
+			t.Errorf("Expected error for missing sync function")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package pbimmutable
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// WithMutableSystemFields lets the listed PocketBase system fields change even when they
+// are frozen explicitly, e.g. to backfill "created" on purpose:
+//
+//	MakeImmutable("created", "number", WithMutableSystemFields("created"))
+//
+// Every name must be a system field (see IsSystemField), otherwise the first update fails
+// with a setup error.
+func WithMutableSystemFields(names ...string) Option {
+	return func(cfg *hookConfig) {
+		if err := checkSystemFieldNames(cfg, "WithMutableSystemFields", names); err != nil {
+			cfg.setupErr = err
+			return
+		}
+		cfg.cmp.mutableSystemFields = append(cfg.cmp.mutableSystemFields, names...)
+	}
+}
+
+// WithImmutableSystemFields always checks the listed PocketBase system fields, also when
+// all user-defined fields are frozen, and rejects their changes. Unlike the explicitly
+// listed fields, this includes "updated", which is otherwise always allowed to change:
+//
+//	MakeImmutable(WithImmutableSystemFields("updated"))
+//
+// Every name must be a system field (see IsSystemField) that isn't passed to
+// WithMutableSystemFields as well, otherwise the first update fails with a setup error.
+func WithImmutableSystemFields(names ...string) Option {
+	return func(cfg *hookConfig) {
+		if err := checkSystemFieldNames(cfg, "WithImmutableSystemFields", names); err != nil {
+			cfg.setupErr = err
+			return
+		}
+		cfg.cmp.immutableSystemFields = append(cfg.cmp.immutableSystemFields, names...)
+	}
+}
+
+// checkSystemFieldNames validates the names passed to the system fields options.
+func checkSystemFieldNames(cfg *hookConfig, option string, names []string) error {
+	for _, name := range names {
+		if !IsSystemField(name) {
+			return fmt.Errorf("pbimmutable.%s: %s: %q is not a system field", cfg.name, option, name)
+		}
+		if containsString(cfg.cmp.mutableSystemFields, name) || containsString(cfg.cmp.immutableSystemFields, name) {
+			return fmt.Errorf("pbimmutable.%s: %s: system field %q is already configured", cfg.name, option, name)
+		}
+	}
+	return nil
+}
+
+// changeViolates reports whether a change of the named field violates the rule, applying
+// the system fields overrides (see WithMutableSystemFields and
+// WithImmutableSystemFields). By default only changes of "updated" are allowed.
+func (c comparer) changeViolates(name string) bool {
+	switch {
+	case containsString(c.mutableSystemFields, name):
+		return false
+	case name == models.SystemFieldUpdated:
+		return containsString(c.immutableSystemFields, name)
+	default:
+		return true
+	}
+}
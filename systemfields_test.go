@@ -0,0 +1,53 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestSystemFieldsOverrides(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "system_fields_override_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	backfilled := map[string]interface{}{models.SystemFieldCreated: "2020-01-01 00:00:00.000Z"}
+	touched := map[string]interface{}{models.SystemFieldUpdated: "2030-01-01 00:00:00.000Z"}
+
+	tests := []struct {
+		name                string
+		hookFunc            func(e *core.RecordEvent) error
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"listed created is frozen by default", MakeImmutable("created"), backfilled, "Attempt to modify immutable field 'created'"},
+		{"mutable created can be backfilled", MakeImmutable("created", "name", WithMutableSystemFields("created")), backfilled, ""},
+		{"updated changes by default", MakeImmutable("updated"), touched, ""},
+		{"immutable updated is frozen", MakeImmutable("name", WithImmutableSystemFields("updated")), touched, "Attempt to modify immutable field 'updated'"},
+		{"immutable system field with all fields", MakeImmutable(WithImmutableSystemFields("created")), backfilled, "Attempt to modify immutable field 'created'"},
+		{"typed config", MakeImmutableWith(ImmutableConfig{FreezeAll: true, ImmutableSystemFields: []string{"updated"}}), touched, "Attempt to modify immutable field 'updated'"},
+		{"not a system field", MakeImmutable("name", WithMutableSystemFields("name")), nil, `WithMutableSystemFields: "name" is not a system field`},
+		{"configured twice", MakeImmutable("name", WithMutableSystemFields("created"), WithImmutableSystemFields("created")), nil, `system field "created" is already configured`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
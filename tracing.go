@@ -0,0 +1,77 @@
+package pbimmutable
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Span names created by the hooks when a tracer is configured (see WithTracer).
+const (
+	SpanHook     = "pbimmutable.hook"
+	SpanFetch    = "pbimmutable.fetch_original"
+	SpanCheck    = "pbimmutable.check"
+	SpanCallback = "pbimmutable.callback"
+)
+
+// Outcomes set as the "pbimmutable.outcome" attribute of the SpanHook span:
+// OutcomeRejected when the checks blocked the update, OutcomeError when the
+// commit or the callback failed after the checks passed.
+const (
+	OutcomeAllowed  = "allowed"
+	OutcomeRejected = "rejected"
+	OutcomeError    = "error"
+)
+
+// Tracer creates spans. It mirrors the subset of the OpenTelemetry trace API used
+// by the hooks, so an OpenTelemetry tracer can be plugged in with a thin adapter.
+type Tracer interface {
+	// Start creates a span as child of the span stored in ctx (if any)
+	// and returns a context that carries the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation created by a Tracer.
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// WithTracer traces the hook with the provided tracer.
+//
+// Every hook run creates a SpanHook span (a child of the span of the HTTP request
+// context, if any) tagged with the collection name, the record id and the outcome.
+// The original record fetch, the checks and the callback create the child spans
+// SpanFetch, SpanCheck and SpanCallback.
+//
+// Without this option the hooks use a no-op tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(cfg *hookConfig) {
+		cfg.tracer = tracer
+	}
+}
+
+// startSpan starts a span with the configured tracer, or a no-op span when no tracer is set.
+func (cfg *hookConfig) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if cfg.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return cfg.tracer.Start(ctx, name)
+}
+
+// eventContext returns the context of the event HTTP request
+// or context.Background() for non-HTTP events.
+func eventContext(e *core.RecordEvent) context.Context {
+	if e.HttpContext != nil && e.HttpContext.Request() != nil {
+		return e.HttpContext.Request().Context()
+	}
+	return context.Background()
+}
+
+// noopSpan is the Span used when no tracer is configured.
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value any) {}
+func (noopSpan) RecordError(err error)              {}
+func (noopSpan) End()                               {}
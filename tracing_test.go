@@ -0,0 +1,111 @@
+package pbimmutable
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+type testSpan struct {
+	name   string
+	parent *testSpan
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *testSpan) RecordError(err error)              { s.err = err }
+func (s *testSpan) End()                               { s.ended = true }
+
+type testSpanKey struct{}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (tr *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	span := &testSpan{name: name, parent: parent, attrs: map[string]any{}}
+	tr.spans = append(tr.spans, span)
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func (tr *testTracer) find(name string) *testSpan {
+	for _, span := range tr.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestWithTracer(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "tracing_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		updates         map[string]interface{}
+		callbackErr     error
+		expectOutcome   string
+		expectCallback  bool
+		expectSpanCount int
+	}{
+		{"allowed update", map[string]interface{}{"status": "active"}, nil, OutcomeAllowed, true, 4},
+		{"rejected update", map[string]interface{}{"name": "changed"}, nil, OutcomeRejected, false, 3},
+		{"callback failure", map[string]interface{}{"status": "active"}, errors.New("callback failed"), OutcomeError, true, 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tracer := &testTracer{}
+			hookFunc := MakeImmutable("name", WithTracer(tracer), func(e *core.RecordEvent) error {
+				return tc.callbackErr
+			})
+
+			hookFunc(newUpdateEvent(app, initialRecord, tc.updates))
+
+			if len(tracer.spans) != tc.expectSpanCount {
+				t.Fatalf("Expected %d spans, got %d", tc.expectSpanCount, len(tracer.spans))
+			}
+			for _, span := range tracer.spans {
+				if !span.ended {
+					t.Errorf("Expected span %s to be ended", span.name)
+				}
+			}
+
+			root := tracer.find(SpanHook)
+			if root == nil || root.parent != nil {
+				t.Fatalf("Expected a root %s span", SpanHook)
+			}
+			if root.attrs["pbimmutable.outcome"] != tc.expectOutcome {
+				t.Errorf("Expected outcome %s, got %v", tc.expectOutcome, root.attrs["pbimmutable.outcome"])
+			}
+			if root.attrs["pbimmutable.collection"] != coll.Name || root.attrs["pbimmutable.record_id"] != initialRecord.Id {
+				t.Errorf("Unexpected root span attributes: %v", root.attrs)
+			}
+
+			if check := tracer.find(SpanCheck); check == nil || check.parent != root {
+				t.Errorf("Expected %s to be a child of %s", SpanCheck, SpanHook)
+			}
+			if fetch := tracer.find(SpanFetch); fetch == nil || fetch.parent == nil || fetch.parent.name != SpanCheck {
+				t.Errorf("Expected %s to be a child of %s", SpanFetch, SpanCheck)
+			}
+			if callback := tracer.find(SpanCallback); (callback != nil) != tc.expectCallback {
+				t.Errorf("Expected callback span %v, got %v", tc.expectCallback, callback != nil)
+			} else if callback != nil && callback.err != tc.callbackErr {
+				t.Errorf("Expected callback span error %v, got %v", tc.callbackErr, callback.err)
+			}
+		})
+	}
+}
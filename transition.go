@@ -0,0 +1,70 @@
+package pbimmutable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RuleTransition is the kind of the rule created by MakeTransitionGuard.
+const RuleTransition RuleKind = "transition"
+
+// MakeTransitionGuard returns a hook that only allows the listed transitions of a field value,
+// e.g. a draft → review → published workflow:
+//
+//	MakeTransitionGuard("status", map[string][]string{
+//		"draft":  {"review"},
+//		"review": {"draft", "published"},
+//	})
+//
+// Keeping the current value is always allowed. Any other change is rejected, including
+// changes from a state that is not a key of allowed (e.g. "published" above is final).
+//
+// An optional callback of type `func(e *core.RecordEvent) error` can be provided
+// and behaves the same as in MakeImmutable.
+func MakeTransitionGuard(field string, allowed map[string][]string, args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeTransitionGuard", args)
+	if cfg.setupErr == nil {
+		switch {
+		case field == "":
+			cfg.setupErr = errors.New("pbimmutable.MakeTransitionGuard: a field name must be provided")
+		case len(cfg.fields) > 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeTransitionGuard: only a callback can be passed as additional argument")
+		case len(allowed) == 0:
+			cfg.setupErr = errors.New("pbimmutable.MakeTransitionGuard: at least one allowed transition must be provided")
+		}
+	}
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		change := newChange(original, e.Record, field, RuleTransition)
+
+		from := original.GetString(field)
+		to := e.Record.GetString(field)
+		if from == to {
+			return ChangeSet{change}, nil
+		}
+
+		targets, known := allowed[from]
+		switch {
+		case !known:
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' can't change from the state '%s' (no transitions are defined for it).", field, from)
+		case !containsString(targets, to):
+			change.Violated = true
+			change.Message = fmt.Sprintf("Field '%s' can't change from '%s' to '%s'.", field, from, to)
+		}
+		if change.Violated {
+			change.Details = map[string]any{
+				"from":    from,
+				"to":      to,
+				"allowed": targets,
+			}
+		}
+
+		return ChangeSet{change}, nil
+	}
+
+	return newHook(cfg)
+}
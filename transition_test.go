@@ -0,0 +1,68 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeTransitionGuard(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	newRecord := func(status string) *models.Record {
+		record := models.NewRecord(coll)
+		record.Set("name", "transition_test")
+		record.Set("status", status)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("Failed to save initial record: %v", err)
+		}
+		return record
+	}
+
+	allowed := map[string][]string{
+		"draft":  {"review"},
+		"review": {"draft", "published"},
+	}
+
+	tests := []struct {
+		name                string
+		from                string
+		to                  string
+		expectErrorContains string
+	}{
+		{"draft to review", "draft", "review", ""},
+		{"review to published", "review", "published", ""},
+		{"review back to draft", "review", "draft", ""},
+		{"unchanged", "published", "published", ""},
+		{"skipping a state", "draft", "published", "Field 'status' can't change from 'draft' to 'published'."},
+		{"backward from final state", "published", "review", "Field 'status' can't change from the state 'published'"},
+		{"unknown current state", "archived", "draft", "Field 'status' can't change from the state 'archived'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeTransitionGuard("status", allowed)
+
+			err := hookFunc(newUpdateEvent(app, newRecord(tc.from), map[string]interface{}{"status": tc.to}))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("empty transitions", func(t *testing.T) {
+		hookFunc := MakeTransitionGuard("status", nil)
+
+		err := hookFunc(newUpdateEvent(app, newRecord("draft"), nil))
+		if err == nil || !strings.Contains(err.Error(), "MakeTransitionGuard setup error") {
+			t.Errorf("Expected setup error, got: %v", err)
+		}
+	})
+}
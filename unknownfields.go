@@ -0,0 +1,104 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// WithRejectUnknownFields rejects updates that carry fields the collection schema doesn't
+// have, e.g. a misspelled field name or an injected key, with a 400 error listing them.
+// The check runs before the immutability check, so it also catches payloads that don't
+// touch any frozen field.
+//
+// PocketBase itself silently drops unknown keys of a request body and never saves the
+// unknown data of a record, so the check doesn't conflict with its schema validation,
+// which still runs for the known fields in e.Next(). Both the keys of the request body
+// (for HTTP events) and the unknown data set on the record are checked. System fields,
+// the auth fields of auth collections and the "+"/"-" field modifiers are known, and so
+// are the request body keys read by the hook itself (e.g. the JustificationRule.Key of
+// MakeJustifiedImmutable or the ApprovalRule.Key of MakeApprovedImmutable).
+func WithRejectUnknownFields() Option {
+	return func(cfg *hookConfig) {
+		cfg.rejectUnknownFields = true
+	}
+}
+
+// unknownFieldCode is the validation error code of the unknown fields in the error data.
+const unknownFieldCode = "validation_unknown_field"
+
+// unknownFieldsError returns the error for an update that carries unknown fields
+// or nil if all fields are known.
+func (cfg *hookConfig) unknownFieldsError(e *core.RecordEvent) error {
+	unknown := cfg.unknownFields(e)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	data := make(validation.Errors, len(unknown))
+	for _, field := range unknown {
+		data[field] = validation.NewError(unknownFieldCode, "Unknown field.")
+	}
+
+	return apis.NewBadRequestError(
+		fmt.Sprintf("Unknown fields submitted for collection %s: %s.", e.Record.Collection().Name, strings.Join(unknown, ", ")),
+		data,
+	)
+}
+
+// unknownFields returns the sorted names of the fields of the event record and request body
+// that aren't known to the record collection or read by the hook (see hookConfig.requestKeys).
+func (cfg *hookConfig) unknownFields(e *core.RecordEvent) []string {
+	seen := map[string]bool{}
+	for key := range e.Record.UnknownData() {
+		seen[key] = true
+	}
+	if info := requestInfo(e); info != nil {
+		for key := range info.Data {
+			if !isKnownRequestKey(e.Record.Collection(), key) && !containsString(cfg.requestKeys, key) {
+				seen[key] = true
+			}
+		}
+	}
+
+	unknown := make([]string, 0, len(seen))
+	for key := range seen {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// authRequestKeys lists the request body keys accepted by auth collections
+// besides their auth fields.
+var authRequestKeys = []string{"password", "passwordConfirm", "oldPassword"}
+
+// isKnownRequestKey reports whether the request body key is accepted for the collection.
+func isKnownRequestKey(collection *models.Collection, key string) bool {
+	name := trimModifiers(key)
+
+	switch {
+	case collection.Schema.GetFieldByName(name) != nil:
+		return true
+	case containsString(schema.SystemFieldNames(), name), containsString(schema.BaseModelFieldNames(), name):
+		return true
+	case collection.IsAuth():
+		return containsString(schema.AuthFieldNames(), name) || containsString(authRequestKeys, name)
+	default:
+		return false
+	}
+}
+
+// trimModifiers returns the field name of a request body key with a "+" or "-" field modifier,
+// e.g. "tags" for "tags+", "+tags" and "tags-".
+func trimModifiers(key string) string {
+	name := strings.TrimPrefix(key, schema.FieldValueModifierAdd)
+	name = strings.TrimSuffix(name, schema.FieldValueModifierAdd)
+	return strings.TrimSuffix(name, schema.FieldValueModifierSubtract)
+}
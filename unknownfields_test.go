@@ -0,0 +1,106 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestWithRejectUnknownFields(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "unknown_fields")
+	initialRecord.Set("status", "active")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		event               func() *core.RecordEvent
+		expectErrorContains string
+	}{
+		{"known fields", func() *core.RecordEvent {
+			return newUpdateEvent(app, initialRecord, map[string]interface{}{"status": "inactive"})
+		}, ""},
+		{"unknown record data", func() *core.RecordEvent {
+			return newUpdateEvent(app, initialRecord, map[string]interface{}{"stauts": "inactive"})
+		}, "Unknown fields submitted for collection test_items: stauts."},
+		{"unknown request keys", func() *core.RecordEvent {
+			return newRequestUpdateEvent(app, initialRecord, nil, &models.RequestInfo{
+				Data: map[string]any{"status": "inactive", "isAdmin": true, "role": "owner"},
+			})
+		}, "Unknown fields submitted for collection test_items: isAdmin, role."},
+		{"system fields and modifiers", func() *core.RecordEvent {
+			return newRequestUpdateEvent(app, initialRecord, nil, &models.RequestInfo{
+				Data: map[string]any{"id": initialRecord.Id, "value+": 1, "+description": "x"},
+			})
+		}, ""},
+		{"frozen field is still checked", func() *core.RecordEvent {
+			return newUpdateEvent(app, initialRecord, map[string]interface{}{"name": "changed"})
+		}, "Attempt to modify immutable field 'name'."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable("name", WithRejectUnknownFields())
+
+			err := hookFunc(tc.event())
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		err := MakeImmutable("name")(newUpdateEvent(app, initialRecord, map[string]interface{}{"stauts": "inactive"}))
+		if err != nil {
+			t.Errorf("Expected unknown fields to be ignored without the option, got: %v", err)
+		}
+	})
+
+	t.Run("request keys read by the hook are known", func(t *testing.T) {
+		auditColl := &models.Collection{
+			Name: "test_unknown_fields_audit",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{Name: "recordId", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "collection", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "field", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "oldValue", Type: schema.FieldTypeJson},
+				&schema.SchemaField{Name: "newValue", Type: schema.FieldTypeJson},
+				&schema.SchemaField{Name: "justification", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "actor", Type: schema.FieldTypeText},
+			),
+		}
+		if err := app.Dao().SaveCollection(auditColl); err != nil {
+			t.Fatalf("Failed to save audit collection: %v", err)
+		}
+
+		hookFunc := MakeJustifiedImmutable(JustificationRule{AuditCollection: auditColl.Name}, "name", WithRejectUnknownFields())
+
+		err := hookFunc(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"name": "renamed"}, &models.RequestInfo{
+			Data: map[string]any{"name": "renamed", "justification": "typo in the name"},
+		}))
+		if err != nil {
+			t.Errorf("Expected the justified update to pass, got: %v", err)
+		}
+
+		err = hookFunc(newRequestUpdateEvent(app, initialRecord, map[string]interface{}{"name": "renamed"}, &models.RequestInfo{
+			Data: map[string]any{"name": "renamed", "justification": "typo in the name", "approvalToken": "x"},
+		}))
+		if err == nil || !strings.Contains(err.Error(), "Unknown fields submitted for collection test_items: approvalToken.") {
+			t.Errorf("Expected the keys of other hooks to stay unknown, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// fieldValidation is the cached result of validateFieldNames.
+type fieldValidation struct {
+	err error
+}
+
+// validateFields validates the explicit field names of the hook (including the condition
+// fields) against the record collection.
+//
+// The result is cached per collection and schema fingerprint (see schemaFingerprint),
+// so the schema is only scanned again after it changed.
+func (cfg *hookConfig) validateFields(record *models.Record) error {
+	fields, _ := cfg.fieldsFor(record)
+	if len(fields) == 0 && len(cfg.conditionFields) == 0 {
+		return nil
+	}
+
+	names := fields
+	if len(cfg.conditionFields) > 0 {
+		names = append(append([]string(nil), fields...), cfg.conditionFields...)
+	}
+	if cfg.cmp.caseInsensitive {
+		names = canonicalFieldNames(record, names)
+	}
+	if cfg.validated == nil {
+		return validateFieldNames(record, names)
+	}
+
+	key := record.Collection().Id + "|" + record.Collection().Name + "|" + strconv.FormatUint(schemaFingerprint(record), 16)
+	if cached, ok := cfg.validated.Load(key); ok {
+		return cached.(fieldValidation).err
+	}
+
+	err := validateFieldNames(record, names)
+	cfg.validated.Store(key, fieldValidation{err: err})
+
+	return err
+}
+
+// validateFieldNames returns an error listing the names that are neither a schema field,
+// a system field nor (for auth collections) an auth field of the record collection.
+// Dotted names must address a subkey of a JSON field (see validateJSONPaths)
+// and patterns (see isFieldPattern) must match at least one user-defined field.
+func validateFieldNames(record *models.Record, names []string) error {
+	if err := validateJSONPaths(record, names); err != nil {
+		return err
+	}
+
+	var unknown, unmatched []string
+	for _, name := range names {
+		switch {
+		case strings.Contains(name, "."):
+			// already validated as JSON path
+		case isFieldPattern(name):
+			matches, err := matchFieldPattern(record, name)
+			if err != nil {
+				return fmt.Errorf("invalid field pattern %q: %v", name, err)
+			}
+			if len(matches) == 0 {
+				unmatched = append(unmatched, strconv.Quote(name))
+			}
+		case IsSystemField(name), record.Schema().GetFieldByName(name) != nil:
+		case record.Collection().IsAuth() && containsString(schema.AuthFieldNames(), name):
+		default:
+			unknown = append(unknown, strconv.Quote(name))
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown fields %s in collection %s", strings.Join(unknown, ", "), record.Collection().Name)
+	}
+	if len(unmatched) > 0 {
+		return fmt.Errorf("field patterns %s match no field of collection %s", strings.Join(unmatched, ", "), record.Collection().Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestValidateFields(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "validate_test")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		args                []interface{}
+		expectErrorContains string
+	}{
+		{"schema fields", []interface{}{"name", "value"}, ""},
+		{"system fields", []interface{}{"id", "created", "updated", "collectionId"}, ""},
+		{"typo", []interface{}{"nmae", "value"}, `MakeImmutable setup error: pbimmutable.MakeImmutable: unknown fields "nmae" in collection test_items`},
+		{"several unknown fields", []interface{}{"nmae", "valeu"}, `unknown fields "nmae", "valeu"`},
+		{"auth field in base collection", []interface{}{"email"}, `unknown fields "email"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeImmutable(tc.args...)
+
+			err := hookFunc(newUpdateEvent(app, initialRecord, nil))
+
+			if tc.expectErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("result is cached per schema", func(t *testing.T) {
+		cfg := parseArgs("MakeImmutable", []interface{}{"name"})
+
+		if err := cfg.validateFields(initialRecord); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		entries := 0
+		cfg.validated.Range(func(key, value any) bool {
+			entries++
+			return true
+		})
+		if entries != 1 {
+			t.Fatalf("Expected 1 cached validation, got %d", entries)
+		}
+
+		// a schema change invalidates the cached result
+		renamed := &models.Collection{
+			Name:   coll.Name,
+			Type:   models.CollectionTypeBase,
+			Schema: schema.NewSchema(&schema.SchemaField{Name: "title", Type: schema.FieldTypeText}),
+		}
+		renamed.Id = coll.Id
+		if err := cfg.validateFields(models.NewRecord(renamed)); err == nil || !strings.Contains(err.Error(), `unknown fields "name"`) {
+			t.Errorf("Expected unknown field error after the schema change, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package pbimmutable
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// isEmptyValue reports whether a normalized record value is considered "not set",
+// e.g. nil, an empty string, a zero number or date, false or an empty list.
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case types.DateTime:
+		return v.IsZero()
+	case types.JsonRaw:
+		s := v.String()
+		return s == "" || s == "null" || s == `""` || s == "[]" || s == "{}"
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return rv.IsZero()
+}
+
+// toFloat coerces a numeric value (any int, uint or float type, or a numeric string)
+// to float64. nil and empty strings are treated as 0, like PocketBase number fields do.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case nil:
+		return 0, true
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return 0, true
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+
+	return 0, false
+}
+
+// toBool coerces a bool field value to bool with the same rules as record.GetBool
+// (e.g. "true", 1 and true are all true). nil is false, like for an unset bool field.
+func toBool(value any) (bool, bool) {
+	b, err := cast.ToBoolE(value)
+	return b, err == nil
+}
+
+// toText coerces a text-like field value to string with the same rules as
+// record.GetString (e.g. 100 is "100"). nil isn't converted, so that it is only
+// equal to "" with WithEmptyAsEqual.
+func toText(value any) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	s, err := cast.ToStringE(value)
+	return s, err == nil
+}
+
+// isList reports whether value is a slice, e.g. the value of a multiple select field.
+func isList(value any) bool {
+	return value != nil && reflect.ValueOf(value).Kind() == reflect.Slice
+}
+
+// toStrings converts a single or multiple value of a relation or file field (a string,
+// a list of strings or nil) to a list of strings. Files are converted to their filename,
+// ignoring the other upload metadata. Empty strings are skipped, so that an unset single
+// value equals an empty list.
+func toStrings(value any) ([]string, bool) {
+	var items []any
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case string, *filesystem.File:
+		items = []any{v}
+	case []string:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	case []*filesystem.File:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	case []any:
+		items = v
+	default:
+		return nil, false
+	}
+
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		var s string
+		switch v := item.(type) {
+		case string:
+			s = v
+		case *filesystem.File:
+			if v == nil {
+				continue
+			}
+			s = v.Name
+		default:
+			return nil, false
+		}
+		if s != "" {
+			list = append(list, s)
+		}
+	}
+
+	return list, true
+}
+
+// toJSONValue decodes the value of a JSON field (raw JSON bytes or text, or any
+// JSON serializable Go value) into its generic form of maps, slices, float64, string,
+// bool and nil, so that two values can be compared independently of the key order.
+// An empty raw value is decoded as nil.
+func toJSONValue(value any) (any, bool) {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case types.JsonRaw:
+		raw = v
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		raw = encoded
+	}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, true
+	}
+
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// toTime converts the value of a date field (a types.DateTime, a time.Time or a date
+// string in any format accepted by types.ParseDateTime) to a UTC time.Time.
+// nil and empty values are converted to the zero time.
+func toTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, true
+	case types.DateTime:
+		return v.Time().UTC(), true
+	case time.Time:
+		return v.UTC(), true
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return time.Time{}, true
+		}
+		dt, err := types.ParseDateTime(v)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return dt.Time().UTC(), true
+	}
+
+	return time.Time{}, false
+}
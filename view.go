@@ -0,0 +1,62 @@
+package pbimmutable
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// BaseRecordForView maps a view collection record to the record of its base collection.
+//
+// PocketBase view collections are read-only: their records can't be saved and their
+// update hooks never fire. Custom routes that accept edits of view records must apply
+// them to the base record instead. BaseRecordForView loads the base record with the
+// same id as the view record and overlays the view columns listed in mapping, keyed by
+// view column with the base field they select as value:
+//
+//	BaseRecordForView(dao, viewRecord, "orders", map[string]string{"name": "name", "state": "status"})
+//
+// Only list columns that select a base field as is. Computed or aggregated columns
+// (e.g. "SELECT id, price * qty AS price") must be left out, even when their name matches
+// a base field, otherwise their value would be written to it. Saving the returned record
+// triggers the hooks of the base collection, so the immutability rules registered there
+// also cover updates routed via the view.
+//
+// The view query must select the base record id as its id (e.g. "SELECT id, name FROM orders").
+func BaseRecordForView(dao *daos.Dao, viewRecord *models.Record, baseCollection string, mapping map[string]string) (*models.Record, error) {
+	if viewRecord == nil {
+		return nil, fmt.Errorf("pbimmutable.BaseRecordForView: missing view record")
+	}
+	if !viewRecord.Collection().IsView() {
+		return nil, fmt.Errorf("pbimmutable.BaseRecordForView: collection %q is not a view collection", viewRecord.Collection().Name)
+	}
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("pbimmutable.BaseRecordForView: a mapping of the view columns to base fields must be provided")
+	}
+
+	baseRecord, err := dao.FindRecordById(baseCollection, viewRecord.Id)
+	if err != nil {
+		return nil, fmt.Errorf("pbimmutable.BaseRecordForView: failed to fetch base record %s from collection %s (the view must select the base id as its id): %w", viewRecord.Id, baseCollection, err)
+	}
+
+	columns := make([]string, 0, len(mapping))
+	for column := range mapping {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		field := mapping[column]
+		switch {
+		case viewRecord.Schema().GetFieldByName(column) == nil:
+			return nil, fmt.Errorf("pbimmutable.BaseRecordForView: unknown column %q of view %s", column, viewRecord.Collection().Name)
+		case IsSystemField(field) || baseRecord.Schema().GetFieldByName(field) == nil:
+			return nil, fmt.Errorf("pbimmutable.BaseRecordForView: column %q is mapped to %q, which is not a user-defined field of collection %s", column, field, baseCollection)
+		}
+		baseRecord.Set(field, viewRecord.Get(column))
+	}
+
+	return baseRecord, nil
+}
@@ -0,0 +1,102 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestBaseRecordForView(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	view := &models.Collection{
+		Name: "test_items_view",
+		Type: models.CollectionTypeView,
+		Options: map[string]any{
+			"query": "SELECT id, name, status, upper(description) AS description FROM test_items",
+		},
+	}
+	if err := app.Dao().SaveCollection(view); err != nil {
+		t.Fatalf("Failed to save view collection: %v", err)
+	}
+
+	initialRecord := models.NewRecord(coll)
+	initialRecord.Set("name", "view_test")
+	initialRecord.Set("status", "active")
+	initialRecord.Set("description", "kept")
+	if err := app.Dao().SaveRecord(initialRecord); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	hookFunc := MakeImmutable("name")
+
+	updateViaView := func(t *testing.T, updates map[string]interface{}) error {
+		viewRecord, err := app.Dao().FindRecordById(view.Name, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to fetch view record: %v", err)
+		}
+		for k, v := range updates {
+			viewRecord.Set(k, v)
+		}
+
+		baseRecord, err := BaseRecordForView(app.Dao(), viewRecord, coll.Name, map[string]string{"name": "name", "status": "status"})
+		if err != nil {
+			t.Fatalf("Failed to map view record: %v", err)
+		}
+		if baseRecord.Collection().Id != coll.Id || baseRecord.GetString("description") != "kept" {
+			t.Fatalf("Expected the base record with its other fields preserved, got %v", baseRecord.PublicExport())
+		}
+
+		return hookFunc(&core.RecordEvent{App: app, Record: baseRecord})
+	}
+
+	t.Run("allowed change via view", func(t *testing.T) {
+		if err := updateViaView(t, map[string]interface{}{"status": "inactive"}); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("immutable change via view", func(t *testing.T) {
+		err := updateViaView(t, map[string]interface{}{"name": "changed"})
+		if err == nil || !strings.Contains(err.Error(), "Attempt to modify immutable field 'name'") {
+			t.Errorf("Expected immutable field error, got: %v", err)
+		}
+	})
+
+	t.Run("non view record", func(t *testing.T) {
+		_, err := BaseRecordForView(app.Dao(), initialRecord, coll.Name, map[string]string{"name": "name"})
+		if err == nil || !strings.Contains(err.Error(), "is not a view collection") {
+			t.Errorf("Expected error for a base record, got: %v", err)
+		}
+	})
+
+	t.Run("invalid mappings", func(t *testing.T) {
+		viewRecord, err := app.Dao().FindRecordById(view.Name, initialRecord.Id)
+		if err != nil {
+			t.Fatalf("Failed to fetch view record: %v", err)
+		}
+
+		tests := []struct {
+			name     string
+			mapping  map[string]string
+			expected string
+		}{
+			{"no mapping", nil, "a mapping of the view columns to base fields must be provided"},
+			{"unknown column", map[string]string{"title": "name"}, `unknown column "title"`},
+			{"unknown base field", map[string]string{"name": "title"}, `column "name" is mapped to "title"`},
+			{"system field", map[string]string{"name": "created"}, `column "name" is mapped to "created"`},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := BaseRecordForView(app.Dao(), viewRecord, coll.Name, tc.mapping)
+				if err == nil || !strings.Contains(err.Error(), tc.expected) {
+					t.Errorf("Expected error containing %q, got: %v", tc.expected, err)
+				}
+			})
+		}
+	})
+}
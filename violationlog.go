@@ -0,0 +1,78 @@
+package pbimmutable
+
+import (
+	"log/slog"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// FieldOptionSecret is the bool field of the FieldOptionsCollection records that marks
+// their field as secret. The values of secret fields are never written to the violation
+// log (see WithViolationLog).
+const FieldOptionSecret = "secret"
+
+// redactedValue replaces the values of secret fields in the violation log.
+const redactedValue = "[redacted]"
+
+// WithViolationLog writes a structured warning via e.App.Logger() every time the hook
+// rejects an update. The entry contains the collection name, the record id, the violated
+// field names with their old and new values and the id of the requesting auth record
+// or admin, if any.
+//
+// The values of the listed secretFields, and of fields whose record in the
+// FieldOptionsCollection sets FieldOptionSecret to true, are replaced with "[redacted]".
+// If the field options can't be loaded, all values are redacted.
+func WithViolationLog(secretFields ...string) Option {
+	return func(cfg *hookConfig) {
+		cfg.logViolations = true
+		cfg.secretFields = append(cfg.secretFields, secretFields...)
+	}
+}
+
+// writeViolationLog writes the violation log entry of a rejected update.
+func (cfg *hookConfig) writeViolationLog(e *core.RecordEvent, violations ChangeSet) {
+	e.App.Logger().Warn("pbimmutable: blocked change of immutable fields", cfg.violationLogAttrs(e, violations)...)
+}
+
+// violationLogAttrs returns the structured attributes of the violation log entry.
+func (cfg *hookConfig) violationLogAttrs(e *core.RecordEvent, violations ChangeSet) []any {
+	// a failed lookup redacts everything rather than risking to log a secret
+	secretFields, err := optionFields(e.App.Dao(), e.Record.Collection(), FieldOptionSecret)
+	redactAll := err != nil
+
+	fields := make([]string, len(violations))
+	changes := make([]any, len(violations))
+	for i, change := range violations {
+		fields[i] = change.Field
+
+		oldValue, newValue := change.Old, change.New
+		if redactAll || cfg.isSecretField(e, change.Field, secretFields) {
+			oldValue, newValue = redactedValue, redactedValue
+		}
+
+		changes[i] = slog.Group(change.Field,
+			slog.String("reason", string(change.RuleKind)),
+			slog.Any("old", oldValue),
+			slog.Any("new", newValue),
+		)
+	}
+
+	return []any{
+		slog.String("collection", e.Record.Collection().Name),
+		slog.String("recordId", e.Record.Id),
+		slog.Any("fields", fields),
+		slog.Group("changes", changes...),
+		slog.String("actor", requestActorId(requestInfo(e))),
+	}
+}
+
+// isSecretField reports whether the values of the field must not be logged, because
+// it is listed by the hook or in the marked fields. JSON paths inherit the secrecy of
+// their field.
+func (cfg *hookConfig) isSecretField(e *core.RecordEvent, name string, marked []string) bool {
+	if field, _, ok := splitJSONPath(e.Record, name); ok {
+		name = field
+	}
+
+	return containsString(cfg.secretFields, name) || containsString(marked, name)
+}
@@ -0,0 +1,122 @@
+package pbimmutable
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestWithViolationLog(t *testing.T) {
+	app, _, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	coll := &models.Collection{
+		Name: "test_accounts",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "owner", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "iban", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "pin", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(coll); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	if err := app.Dao().SaveCollection(NewFieldOptionsCollection()); err != nil {
+		t.Fatalf("Failed to save field options collection: %v", err)
+	}
+	if err := SetFieldOption(app.Dao(), coll.Name, "iban", FieldOptionSecret, true); err != nil {
+		t.Fatalf("Failed to set field option: %v", err)
+	}
+
+	record := models.NewRecord(coll)
+	record.Set("owner", "alice")
+	record.Set("iban", "DE001")
+	record.Set("pin", "1234")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatalf("Failed to save initial record: %v", err)
+	}
+
+	// the record is loaded with its collection from the database, like for an update request
+	account, err := app.Dao().FindRecordById(coll.Name, record.Id)
+	if err != nil {
+		t.Fatalf("Failed to reload record: %v", err)
+	}
+
+	user := models.NewRecord(coll)
+	user.Id = "user_id"
+
+	cfg := parseArgs("MakeImmutable", []interface{}{WithViolationLog("pin")})
+
+	event := newRequestUpdateEvent(app, account, map[string]interface{}{"owner": "bob", "iban": "DE002", "pin": "0000"}, &models.RequestInfo{AuthRecord: user})
+	violations := Evaluate(account, event.Record, []string{"owner", "iban", "pin"}).Violations()
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Warn("test", cfg.violationLogAttrs(event, violations)...)
+
+	var entry struct {
+		Collection string                       `json:"collection"`
+		RecordId   string                       `json:"recordId"`
+		Fields     []string                     `json:"fields"`
+		Actor      string                       `json:"actor"`
+		Changes    map[string]map[string]string `json:"changes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode log entry %s: %v", buf.String(), err)
+	}
+
+	if entry.Collection != coll.Name || entry.RecordId != account.Id || entry.Actor != "user_id" || len(entry.Fields) != 3 {
+		t.Errorf("Unexpected log entry: %s", buf.String())
+	}
+	if owner := entry.Changes["owner"]; owner["old"] != "alice" || owner["new"] != "bob" || owner["reason"] != string(RuleImmutable) {
+		t.Errorf("Expected the owner values to be logged, got: %v", owner)
+	}
+	for _, field := range []string{"iban", "pin"} {
+		if change := entry.Changes[field]; change["old"] != redactedValue || change["new"] != redactedValue {
+			t.Errorf("Expected the %s values to be redacted, got: %v", field, change)
+		}
+	}
+
+	t.Run("unavailable field options redact all values", func(t *testing.T) {
+		// the collection is still registered, but its records can't be loaded anymore
+		if _, err := app.Dao().DB().NewQuery("DROP TABLE {{" + FieldOptionsCollection + "}}").Execute(); err != nil {
+			t.Fatalf("Failed to drop the field options table: %v", err)
+		}
+		resetFieldOptions()
+
+		var buf bytes.Buffer
+		slog.New(slog.NewJSONHandler(&buf, nil)).Warn("test", cfg.violationLogAttrs(event, violations)...)
+
+		var entry struct {
+			Changes map[string]map[string]string `json:"changes"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to decode log entry %s: %v", buf.String(), err)
+		}
+		if owner := entry.Changes["owner"]; owner["old"] != redactedValue || owner["new"] != redactedValue {
+			t.Errorf("Expected the owner values to be redacted, got: %v", owner)
+		}
+	})
+
+	t.Run("rejection still returns the error", func(t *testing.T) {
+		hookFunc := MakeImmutable("owner", WithViolationLog())
+
+		if err := hookFunc(newUpdateEvent(app, account, map[string]interface{}{"owner": "bob"})); err == nil {
+			t.Errorf("Expected immutable field error, got nil")
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		if cfg := parseArgs("MakeImmutable", []interface{}{"owner"}); cfg.logViolations {
+			t.Errorf("Expected the violation log to be disabled")
+		}
+		if cfg := (ImmutableConfig{Fields: []string{"owner"}, LogViolations: true}).build("MakeImmutableWith"); !cfg.logViolations {
+			t.Errorf("Expected LogViolations to enable the violation log")
+		}
+	})
+}
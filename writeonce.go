@@ -0,0 +1,44 @@
+package pbimmutable
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RuleWriteOnce is the kind of the rule created by MakeWriteOnce.
+const RuleWriteOnce RuleKind = "write_once"
+
+// MakeWriteOnce returns a hook that allows the provided fields (or all user-defined fields
+// when none are given) to be set exactly once: an update from an empty value to a
+// non-empty value passes, any later change (including clearing the value) is rejected.
+//
+// "Empty" follows the stored value of the field type, e.g. an empty text, a zero number,
+// an unset date or an empty relation.
+//
+// It accepts the same arguments as MakeImmutable. The update that fills the fields runs
+// the callback and e.Next() like any other accepted update.
+func MakeWriteOnce(args ...interface{}) func(e *core.RecordEvent) error {
+	cfg := parseArgs("MakeWriteOnce", args)
+
+	cfg.evaluate = func(e *core.RecordEvent, original *models.Record) (ChangeSet, error) {
+		changes := cfg.cmp.evaluate(original, e.Record, cfg.fields)
+		for i, change := range changes {
+			changes[i].RuleKind = RuleWriteOnce
+			if !change.Violated {
+				continue
+			}
+
+			if isEmptyValue(change.Old) {
+				changes[i].Violated = false // first write
+				continue
+			}
+
+			changes[i].Message = fmt.Sprintf("Field '%s' was already set and can't be changed.", change.Field)
+		}
+		return changes, nil
+	}
+
+	return newHook(cfg)
+}
@@ -0,0 +1,61 @@
+package pbimmutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestMakeWriteOnce(t *testing.T) {
+	app, coll, cleanup := setupTestAppWithCollection(t)
+	defer cleanup()
+
+	emptyRecord := models.NewRecord(coll)
+	emptyRecord.Set("name", "write_once_empty")
+	if err := app.Dao().SaveRecord(emptyRecord); err != nil {
+		t.Fatalf("Failed to save empty record: %v", err)
+	}
+
+	filledRecord := models.NewRecord(coll)
+	filledRecord.Set("name", "write_once_filled")
+	filledRecord.Set("status", "SN-001")
+	filledRecord.Set("value", 5)
+	if err := app.Dao().SaveRecord(filledRecord); err != nil {
+		t.Fatalf("Failed to save filled record: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		original            *models.Record
+		updates             map[string]interface{}
+		expectErrorContains string
+	}{
+		{"first text write", emptyRecord, map[string]interface{}{"status": "SN-001"}, ""},
+		{"first number write", emptyRecord, map[string]interface{}{"value": 5}, ""},
+		{"empty stays empty", emptyRecord, map[string]interface{}{"description": "not checked"}, ""},
+		{"unchanged value", filledRecord, map[string]interface{}{"status": "SN-001"}, ""},
+		{"second text write", filledRecord, map[string]interface{}{"status": "SN-002"}, "Field 'status' was already set and can't be changed."},
+		{"second number write", filledRecord, map[string]interface{}{"value": 6}, "Field 'value' was already set and can't be changed."},
+		{"clearing a value", filledRecord, map[string]interface{}{"status": ""}, "Field 'status' was already set"},
+		{"clearing a number", filledRecord, map[string]interface{}{"value": 0}, "Field 'value' was already set"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hookFunc := MakeWriteOnce("status", "value")
+
+			err := hookFunc(newUpdateEvent(app, tc.original, tc.updates))
+
+			if tc.expectErrorContains != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tc.expectErrorContains)
+				} else if !strings.Contains(err.Error(), tc.expectErrorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.expectErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}